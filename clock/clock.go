@@ -0,0 +1,132 @@
+// Package clock decides which day a moment belongs to. Timestamps are stored
+// in UTC; daily totals, streaks and the heatmap group them into days in the
+// configured timezone, with each day starting at the configured time.
+package clock
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Flack74/pom/store"
+)
+
+// Rules are the timezone and start-of-day time used to group moments into days
+type Rules struct {
+	Location *time.Location // Nil means the system timezone
+	DayStart int            // Minutes after midnight when a day starts
+}
+
+// Settings are the day fields of the config document
+type Settings struct {
+	Timezone string `json:"timezone,omitempty"`  // IANA name, empty for the system timezone
+	DayStart string `json:"day_start,omitempty"` // HH:MM, empty for midnight
+}
+
+// Parse builds the rules for a timezone name and an HH:MM start of day.
+// Empty values mean the system timezone and midnight.
+func Parse(settings Settings) (Rules, error) {
+	var rules Rules
+	if settings.Timezone != "" {
+		location, err := time.LoadLocation(settings.Timezone)
+		if err != nil {
+			return Rules{}, fmt.Errorf("unknown timezone '%s'", settings.Timezone)
+		}
+		rules.Location = location
+	}
+	if settings.DayStart != "" {
+		start, err := time.Parse("15:04", settings.DayStart)
+		if err != nil {
+			return Rules{}, fmt.Errorf("invalid day start '%s', use HH:MM", settings.DayStart)
+		}
+		rules.DayStart = start.Hour()*60 + start.Minute()
+	}
+	return rules, nil
+}
+
+// Load returns the configured rules, falling back to the system timezone and
+// midnight if none are set or they cannot be read
+func Load() Rules {
+	rules := Rules{}
+	store.View(func(tx *store.Tx) error {
+		rules = Read(tx)
+		return nil
+	})
+	return rules
+}
+
+// Read returns the configured rules inside a transaction
+func Read(tx *store.Tx) Rules {
+	var settings Settings
+	if _, err := tx.Get(store.KeyConfig, &settings); err != nil {
+		return Rules{}
+	}
+	rules, err := Parse(settings)
+	if err != nil {
+		return Rules{}
+	}
+	return rules
+}
+
+// Local returns the rules' timezone
+func (r Rules) Local() *time.Location {
+	if r.Location == nil {
+		return time.Local
+	}
+	return r.Location
+}
+
+// In returns t in the rules' timezone
+func (r Rules) In(t time.Time) time.Time {
+	return t.In(r.Local())
+}
+
+// Now returns the current time in the rules' timezone
+func (r Rules) Now() time.Time {
+	return r.In(time.Now())
+}
+
+// Day returns midnight of the calendar date that t counts towards. Moments
+// before the start of day count towards the previous date.
+func (r Rules) Day(t time.Time) time.Time {
+	local := r.In(t)
+	day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, local.Location())
+	if local.Before(r.Start(day)) {
+		day = day.AddDate(0, 0, -1)
+	}
+	return day
+}
+
+// Today returns the date the current moment counts towards
+func (r Rules) Today() time.Time {
+	return r.Day(time.Now())
+}
+
+// Start returns the moment the day with day's date begins
+func (r Rules) Start(day time.Time) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), r.DayStart/60, r.DayStart%60, 0, 0, r.Local())
+}
+
+// Bounds returns the start and exclusive end of the day t counts towards
+func (r Rules) Bounds(t time.Time) (time.Time, time.Time) {
+	day := r.Day(t)
+	return r.Start(day), r.Start(day.AddDate(0, 0, 1))
+}
+
+// Key returns the date t counts towards as YYYY-MM-DD
+func (r Rules) Key(t time.Time) string {
+	return r.Day(t).Format("2006-01-02")
+}
+
+// SameDay reports whether a and b count towards the same date
+func (r Rules) SameDay(a, b time.Time) bool {
+	return r.Day(a).Equal(r.Day(b))
+}
+
+// String describes the rules, e.g. "Europe/Berlin, days start at 04:00"
+func (r Rules) String() string {
+	zone := "system timezone"
+	if r.Location != nil {
+		zone = r.Location.String()
+	}
+	return fmt.Sprintf("%s, days start at %02d:%02d", zone, r.DayStart/60, r.DayStart%60)
+}
@@ -0,0 +1,147 @@
+package clock
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func TestRulesDay(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("failed to load timezone: %v", err)
+	}
+	midnight := Rules{Location: newYork}
+	early := Rules{Location: newYork, DayStart: 4 * 60} // Days start at 04:00
+	late := Rules{Location: newYork, DayStart: 2*60 + 30}
+
+	// US daylight saving time starts at 02:00 on 2026-03-08 and ends at
+	// 02:00 on 2026-11-01
+	tests := []struct {
+		name  string
+		rules Rules
+		at    string // RFC 3339
+		want  string
+	}{
+		{"just before midnight", midnight, "2026-03-10T23:59:59-04:00", "2026-03-10"},
+		{"at midnight", midnight, "2026-03-11T00:00:00-04:00", "2026-03-11"},
+		{"UTC date ahead of the timezone", midnight, "2026-03-11T02:00:00Z", "2026-03-10"},
+		{"after midnight before the day start", early, "2026-03-11T00:30:00-04:00", "2026-03-10"},
+		{"just before the day start", early, "2026-03-11T03:59:59-04:00", "2026-03-10"},
+		{"at the day start", early, "2026-03-11T04:00:00-04:00", "2026-03-11"},
+		{"end of the day before DST starts", early, "2026-03-08T03:30:00-04:00", "2026-03-07"},
+		{"day start on the first DST day", early, "2026-03-08T04:00:00-04:00", "2026-03-08"},
+		{"before a day start skipped by DST", late, "2026-03-08T01:29:00-05:00", "2026-03-07"},
+		{"after a day start skipped by DST", late, "2026-03-08T03:30:00-04:00", "2026-03-08"},
+		{"repeated hour before DST ends", early, "2026-11-01T01:30:00-04:00", "2026-10-31"},
+		{"repeated hour after DST ends", early, "2026-11-01T01:30:00-05:00", "2026-10-31"},
+		{"day start after DST ends", early, "2026-11-01T04:00:00-05:00", "2026-11-01"},
+	}
+	for _, tt := range tests {
+		at, err := time.Parse(time.RFC3339, tt.at)
+		if err != nil {
+			t.Fatalf("bad time %q: %v", tt.at, err)
+		}
+		day := tt.rules.Day(at)
+		if got := day.Format("2006-01-02"); got != tt.want {
+			t.Errorf("%s: Day(%s) = %s, want %s", tt.name, tt.at, got, tt.want)
+		}
+		if day.Hour() != 0 || day.Minute() != 0 || day.Location() != newYork {
+			t.Errorf("%s: Day(%s) = %v, want midnight in %v", tt.name, tt.at, day, newYork)
+		}
+		if got := tt.rules.Key(at); got != tt.want {
+			t.Errorf("%s: Key(%s) = %s, want %s", tt.name, tt.at, got, tt.want)
+		}
+	}
+}
+
+func TestRulesIn(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("failed to load timezone: %v", err)
+	}
+	rules := Rules{Location: newYork}
+
+	tests := []struct {
+		at   string
+		want string
+	}{
+		{"2026-03-08T06:59:00Z", "2026-03-08 01:59 EST"},
+		{"2026-03-08T07:00:00Z", "2026-03-08 03:00 EDT"},
+		{"2026-11-01T05:30:00Z", "2026-11-01 01:30 EDT"},
+		{"2026-11-01T06:30:00Z", "2026-11-01 01:30 EST"},
+	}
+	for _, tt := range tests {
+		at, err := time.Parse(time.RFC3339, tt.at)
+		if err != nil {
+			t.Fatalf("bad time %q: %v", tt.at, err)
+		}
+		local := rules.In(at)
+		if got := local.Format("2006-01-02 15:04 MST"); got != tt.want {
+			t.Errorf("In(%s) = %s, want %s", tt.at, got, tt.want)
+		}
+		if !local.Equal(at) {
+			t.Errorf("In(%s) = %v, a different moment", tt.at, local)
+		}
+	}
+
+	if got := (Rules{}).Local(); got != time.Local {
+		t.Errorf("Local() without a timezone = %v, want the system timezone", got)
+	}
+}
+
+func TestRulesBounds(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("failed to load timezone: %v", err)
+	}
+	rules := Rules{Location: newYork, DayStart: 4 * 60}
+
+	tests := []struct {
+		at     string
+		length time.Duration
+	}{
+		{"2026-03-10T12:00:00-04:00", 24 * time.Hour},
+		{"2026-03-07T12:00:00-05:00", 23 * time.Hour}, // Until 04:00 on the first DST day
+		{"2026-10-31T12:00:00-04:00", 25 * time.Hour}, // Until 04:00 on the first standard day
+	}
+	for _, tt := range tests {
+		at, err := time.Parse(time.RFC3339, tt.at)
+		if err != nil {
+			t.Fatalf("bad time %q: %v", tt.at, err)
+		}
+		start, end := rules.Bounds(at)
+		if start.Hour() != 4 || end.Hour() != 4 {
+			t.Errorf("Bounds(%s) = %v to %v, want 04:00 to 04:00", tt.at, start, end)
+		}
+		if got := end.Sub(start); got != tt.length {
+			t.Errorf("Bounds(%s) spans %v, want %v", tt.at, got, tt.length)
+		}
+		if at.Before(start) || !at.Before(end) {
+			t.Errorf("Bounds(%s) = %v to %v, which does not contain it", tt.at, start, end)
+		}
+		if !rules.SameDay(start, end.Add(-time.Second)) || rules.SameDay(start, end) {
+			t.Errorf("Bounds(%s) = %v to %v, which is not one day", tt.at, start, end)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	rules, err := Parse(Settings{Timezone: "Europe/Berlin", DayStart: "04:30"})
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if rules.Location.String() != "Europe/Berlin" || rules.DayStart != 4*60+30 {
+		t.Errorf("Parse = %v, want Europe/Berlin from 04:30", rules)
+	}
+
+	for _, settings := range []Settings{
+		{Timezone: "Mars/Olympus"},
+		{DayStart: "25:00"},
+		{DayStart: "4am"},
+	} {
+		if _, err := Parse(settings); err == nil {
+			t.Errorf("Parse(%+v) succeeded, want an error", settings)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/Flack74/pom/config"
+	"github.com/Flack74/pom/daemon"
+	"github.com/spf13/cobra"
+)
+
+var achievementsCmd = &cobra.Command{
+	Use:   "achievements",
+	Short: "🏆 Achievements and badges",
+	Long: `🏆 Achievements and Badges
+
+Earn badges as you go: your first 100 sessions, a 30-day goal streak, 10 hours
+in one week, finishing a task that took 8 sessions, and more. Unlocks are
+dated, announced with a desktop notification and fire plugins with the
+achievement trigger.
+
+Rules are declarative: a metric, a threshold and an optional tag or profile.
+Teams can add their own, or replace a built-in rule by using its ID.
+
+Metrics:
+  sessions         completed focus sessions
+  hours            focus hours
+  day_sessions     completed focus sessions in one day
+  week_hours       focus hours in one week
+  active_days      days with a completed focus session
+  streak           daily goal streak in days
+  tasks_completed  completed tasks
+  task_sessions    focus sessions spent on one completed task
+  task_estimate    estimated pomodoros of one completed task
+
+Examples:
+  pom achievements                         Unlocked badges and progress
+  pom achievements add backend-50 sessions 50 --name "Backend Regular" --tag backend
+  pom achievements import team-rules.json  Add rules from a JSON array
+  pom achievements remove backend-50`,
+	Run: func(cmd *cobra.Command, args []string) {
+		printUnlocks(daemon.AnnounceAchievements())
+
+		achievements, err := config.Achievements()
+		if err != nil {
+			fmt.Printf("Error loading achievements: %v\n", err)
+			return
+		}
+
+		unlocked := 0
+		for _, a := range achievements {
+			if a.Unlocked {
+				unlocked++
+			}
+		}
+		fmt.Printf("\n🏆 Achievements (%d of %d unlocked)\n\n", unlocked, len(achievements))
+		for _, a := range achievements {
+			if a.Unlocked {
+				fmt.Printf("   %-24s %s  %s\n", a.Label(), a.UnlockedAt.Local().Format("Jan 2, 2006"), a.Description)
+				continue
+			}
+			fmt.Printf("   🔒 %-21s %s %s  %s\n", truncate(a.Name, 21), bar(a.Progress, 1, 10),
+				achievementValue(a), a.Description)
+		}
+	},
+}
+
+var addAchievementCmd = &cobra.Command{
+	Use:   "add [id] [metric] [threshold]",
+	Short: "Add a custom achievement rule",
+	Args:  cobra.ExactArgs(3),
+	Run: func(cmd *cobra.Command, args []string) {
+		threshold, err := strconv.ParseFloat(args[2], 64)
+		if err != nil {
+			fmt.Printf("Invalid threshold: %v\n", err)
+			return
+		}
+
+		rule := config.Achievement{ID: args[0], Metric: args[1], Threshold: threshold}
+		rule.Name, _ = cmd.Flags().GetString("name")
+		rule.Description, _ = cmd.Flags().GetString("description")
+		rule.Icon, _ = cmd.Flags().GetString("icon")
+		rule.Tag, _ = cmd.Flags().GetString("tag")
+		rule.Profile, _ = cmd.Flags().GetString("profile")
+		if rule.Name == "" {
+			rule.Name = rule.ID
+		}
+
+		if err := config.AddAchievement(rule); err != nil {
+			fmt.Printf("Error adding achievement: %v\n", err)
+			return
+		}
+		fmt.Printf("✅ Achievement '%s' added\n", rule.ID)
+		printUnlocks(daemon.AnnounceAchievements())
+	},
+}
+
+var importAchievementsCmd = &cobra.Command{
+	Use:   "import [file]",
+	Short: "Add achievement rules from a JSON array",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		data, err := os.ReadFile(args[0])
+		if err != nil {
+			fmt.Printf("Error reading rules: %v\n", err)
+			return
+		}
+		var rules []config.Achievement
+		if err := json.Unmarshal(data, &rules); err != nil {
+			fmt.Printf("Error reading rules: not a JSON array of rules: %v\n", err)
+			return
+		}
+
+		for _, rule := range rules {
+			if err := config.AddAchievement(rule); err != nil {
+				fmt.Printf("Error adding achievement: %v\n", err)
+				continue
+			}
+			fmt.Printf("✅ Achievement '%s' added\n", rule.ID)
+		}
+		printUnlocks(daemon.AnnounceAchievements())
+	},
+}
+
+var removeAchievementCmd = &cobra.Command{
+	Use:   "remove [id]",
+	Short: "Remove a custom achievement rule",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := config.RemoveAchievement(args[0]); err != nil {
+			fmt.Printf("Error removing achievement: %v\n", err)
+			return
+		}
+		fmt.Printf("🗑️  Achievement '%s' removed\n", args[0])
+	},
+}
+
+// printUnlocks announces new unlocks on the terminal
+func printUnlocks(unlocked []config.Unlock) {
+	for _, unlock := range unlocked {
+		fmt.Printf("🏆 Achievement unlocked: %s\n", unlock.Name)
+	}
+}
+
+// achievementValue shows the progress towards a locked achievement, e.g. "42/100"
+func achievementValue(a config.AchievementStatus) string {
+	format := func(v float64) string {
+		return strings.TrimSuffix(strconv.FormatFloat(v, 'f', 1, 64), ".0")
+	}
+	return fmt.Sprintf("%s/%s", format(a.Value), format(a.Threshold))
+}
+
+// nextAchievement describes the locked achievement closest to unlocking, or
+// returns "" if there is none
+func nextAchievement() string {
+	achievements, err := config.Achievements()
+	if err != nil {
+		return ""
+	}
+	for _, a := range achievements {
+		if !a.Unlocked && a.Value > 0 {
+			return fmt.Sprintf("🏆 Next: %s (%s %s)", a.Label(), achievementValue(a), strings.ReplaceAll(a.Metric, "_", " "))
+		}
+	}
+	return ""
+}
+
+func init() {
+	addAchievementCmd.Flags().String("name", "", "name shown when unlocked (default: the ID)")
+	addAchievementCmd.Flags().String("description", "", "what it takes")
+	addAchievementCmd.Flags().String("icon", "", "emoji shown with the name (default: 🏆)")
+	addAchievementCmd.Flags().String("tag", "", "only count sessions or tasks with this tag")
+	addAchievementCmd.Flags().String("profile", "", "only count sessions run with this profile")
+
+	achievementsCmd.AddCommand(addAchievementCmd)
+	achievementsCmd.AddCommand(importAchievementsCmd)
+	achievementsCmd.AddCommand(removeAchievementCmd)
+	rootCmd.AddCommand(achievementsCmd)
+}
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Flack74/pom/daemon"
+	"github.com/Flack74/pom/timer"
+)
+
+var daemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "🛰️  Run the background timer daemon",
+	Long: `🛰️  Background Timer Daemon
+
+The daemon owns the one authoritative timer. 'pom start', 'pom pause',
+'pom resume', 'pom stop' and 'pom status' talk to it over a local Unix
+socket, so the timer keeps running when a terminal closes and any number
+of terminals can attach to the same session.
+
+You rarely need to run it yourself: the first command that needs the
+timer starts it in the background. Running it in the foreground is useful
+for debugging; a spawned daemon logs to ~/.config/pom/daemon.log.
+
+Examples:
+  pom daemon          Run the daemon in the foreground
+  pom daemon stop     Shut the daemon down (the active run is kept)`,
+	Run: func(cmd *cobra.Command, args []string) {
+		engine, err := timer.NewEngine(daemon.Hooks())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading timer state: %v\n", err)
+			os.Exit(1)
+		}
+
+		server, err := daemon.Listen(engine)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		sigChan := make(chan os.Signal, 1)
+		signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+		go func() {
+			select {
+			case <-sigChan:
+				server.Close()
+			case <-server.Done():
+			}
+		}()
+
+		path, _ := daemon.SocketPath()
+		log.Printf("pom daemon listening on %s", path)
+		if err := server.Serve(); err != nil {
+			log.Printf("pom daemon stopped: %v", err)
+			os.Exit(1)
+		}
+		log.Printf("pom daemon stopped")
+	},
+}
+
+var daemonStopCmd = &cobra.Command{
+	Use:   "stop",
+	Short: "Shut the daemon down",
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := daemon.Dial()
+		if err != nil {
+			fmt.Println("The pom daemon is not running")
+			return
+		}
+		defer client.Close()
+
+		if _, err := client.Call(daemon.MethodShutdown, nil); err != nil {
+			fmt.Printf("Error stopping daemon: %v\n", err)
+			return
+		}
+		fmt.Println("✅ pom daemon stopped")
+	},
+}
+
+func init() {
+	daemonCmd.AddCommand(daemonStopCmd)
+	rootCmd.AddCommand(daemonCmd)
+}
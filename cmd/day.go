@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Flack74/pom/clock"
+	"github.com/Flack74/pom/config"
+	"github.com/spf13/cobra"
+)
+
+var dayCmd = &cobra.Command{
+	Use:   "day",
+	Short: "🕓 Timezone and start of day",
+	Long: `🕓 Timezone and Start of Day
+
+Choose how sessions are grouped into days for daily goals, streaks, the
+calendar heatmap, insights and reports:
+  • Timezone: an IANA name such as Europe/Berlin (default: system timezone)
+  • Start of day: sessions before this time count towards the previous day,
+    for those who work past midnight (default: 00:00)
+
+Session times are stored in UTC, so changing these settings regroups all of
+your history.
+
+Examples:
+  pom day                                  Show the current settings
+  pom day set --timezone America/New_York  Use New York time
+  pom day set --starts-at 04:00            Days start at 4 AM
+  pom day reset                            System timezone, midnight`,
+	Run: func(cmd *cobra.Command, args []string) {
+		rules := clock.Load()
+		start, end := rules.Bounds(time.Now())
+
+		fmt.Printf("🕓 %s\n", rules)
+		fmt.Printf("   Today: %s, %s – %s\n", rules.Today().Format("Mon Jan 2"),
+			start.Format("Jan 2 15:04"), end.Format("Jan 2 15:04"))
+	},
+}
+
+var daySetCmd = &cobra.Command{
+	Use:   "set",
+	Short: "Set the timezone and start of day",
+	Run: func(cmd *cobra.Command, args []string) {
+		if !cmd.Flags().Changed("timezone") && !cmd.Flags().Changed("starts-at") {
+			fmt.Println("Give --timezone and/or --starts-at, e.g. pom day set --starts-at 04:00")
+			return
+		}
+
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			fmt.Printf("Error loading config: %v\n", err)
+			return
+		}
+		if cmd.Flags().Changed("timezone") {
+			cfg.Timezone, _ = cmd.Flags().GetString("timezone")
+		}
+		if cmd.Flags().Changed("starts-at") {
+			cfg.DayStart, _ = cmd.Flags().GetString("starts-at")
+		}
+		saveDaySettings(cfg)
+	},
+}
+
+var dayResetCmd = &cobra.Command{
+	Use:   "reset",
+	Short: "Use the system timezone and midnight",
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			fmt.Printf("Error loading config: %v\n", err)
+			return
+		}
+		cfg.Settings = clock.Settings{}
+		saveDaySettings(cfg)
+	},
+}
+
+// saveDaySettings validates and saves the day settings, then recomputes
+// today's goal progress under them
+func saveDaySettings(cfg config.Config) {
+	if err := config.ValidateDaySettings(cfg.Settings); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	if err := config.SaveConfig(cfg); err != nil {
+		fmt.Printf("Error saving config: %v\n", err)
+		return
+	}
+	if err := config.UpdateProgress(); err != nil {
+		fmt.Printf("Error updating goals progress: %v\n", err)
+	}
+
+	fmt.Printf("✅ %s\n", clock.Load())
+}
+
+func init() {
+	daySetCmd.Flags().String("timezone", "", "IANA timezone, e.g. Europe/Berlin (empty for the system timezone)")
+	daySetCmd.Flags().String("starts-at", "", "time the day starts, HH:MM (empty for midnight)")
+
+	dayCmd.AddCommand(daySetCmd)
+	dayCmd.AddCommand(dayResetCmd)
+	rootCmd.AddCommand(dayCmd)
+}
@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Flack74/pom/config"
+	"github.com/Flack74/pom/store"
+)
+
+var doctorFix bool
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "🩺 Check and repair your data",
+	Long: `🩺 Data Health Check
+
+Check the pom datastore for corruption and repair it from backups:
+  • Verifies the database file can be read
+  • Decodes every section (config, tasks, goals, sessions, ...)
+  • Restores broken sections from the newest backup that has a good copy
+
+Pom keeps a last-good copy of the database in ~/.config/pom/backups,
+refreshed after every change, and up to 10 rolling backups taken
+automatically at most once an hour before the database is changed.
+Repairs try the last-good copy first, then the rolling backups.
+
+Examples:
+  pom doctor                Check for problems
+  pom doctor --fix          Repair problems from backups
+  pom doctor backup         Take a backup now
+  pom doctor backups        List available backups`,
+	Run: func(cmd *cobra.Command, args []string) {
+		backups, err := restoreSources()
+		if err != nil {
+			fmt.Printf("Error listing backups: %v\n", err)
+			return
+		}
+
+		fmt.Println("🩺 Checking pom data...")
+		problems := 0
+
+		// The whole database must parse before individual sections can be checked
+		if err := store.Verify(); err != nil {
+			problems++
+			fmt.Printf("   ❌ Database: %v\n", err)
+			if !doctorFix {
+				fmt.Println("\nRun 'pom doctor --fix' to restore from a backup.")
+				return
+			}
+			if !restoreDatabase(backups) {
+				return
+			}
+		} else {
+			fmt.Println("   ✅ Database")
+		}
+
+		for _, check := range config.DataChecks() {
+			err := store.View(check.Check)
+			if err == nil {
+				fmt.Printf("   ✅ %s\n", check.Key)
+				continue
+			}
+
+			problems++
+			fmt.Printf("   ❌ %s: %v\n", check.Key, err)
+			if doctorFix {
+				restoreKey(check, backups)
+			}
+		}
+
+		if problems == 0 {
+			fmt.Println("\n🎉 No problems found")
+		} else if !doctorFix {
+			fmt.Printf("\n⚠️  %d problem(s) found. Run 'pom doctor --fix' to repair.\n", problems)
+		}
+	},
+}
+
+var doctorBackupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Take a backup of your data now",
+	Run: func(cmd *cobra.Command, args []string) {
+		path, err := store.Backup()
+		if err != nil {
+			fmt.Printf("Error creating backup: %v\n", err)
+			return
+		}
+
+		fmt.Printf("✅ Backup saved to: %s\n", path)
+	},
+}
+
+var doctorBackupsCmd = &cobra.Command{
+	Use:   "backups",
+	Short: "List available backups",
+	Run: func(cmd *cobra.Command, args []string) {
+		backups, err := restoreSources()
+		if err != nil {
+			fmt.Printf("Error listing backups: %v\n", err)
+			return
+		}
+
+		if len(backups) == 0 {
+			fmt.Println("No backups yet. Create one with: pom doctor backup")
+			return
+		}
+
+		fmt.Println("💾 Backups (newest first):")
+		for _, backup := range backups {
+			fmt.Printf("   %s\n", filepath.Base(backup))
+		}
+	},
+}
+
+// restoreSources returns the files repairs are taken from: the last-good
+// copy, if there is one, then the rolling backups newest first
+func restoreSources() ([]string, error) {
+	backups, err := store.Backups()
+	if err != nil {
+		return nil, err
+	}
+
+	lastGood, err := store.LastGood()
+	if err != nil {
+		return nil, err
+	}
+	if lastGood == "" {
+		return backups, nil
+	}
+	return append([]string{lastGood}, backups...), nil
+}
+
+// restoreDatabase replaces the whole database with the newest readable backup
+func restoreDatabase(backups []string) bool {
+	for _, backup := range backups {
+		if err := store.Restore(backup); err != nil {
+			fmt.Printf("   ⚠️  Skipping %s: %v\n", filepath.Base(backup), err)
+			continue
+		}
+		fmt.Printf("   🔧 Database restored from %s\n", filepath.Base(backup))
+		return true
+	}
+
+	fmt.Println("   ❌ No usable backup found. Move ~/.config/pom/pom.db aside to start fresh.")
+	return false
+}
+
+// restoreKey repairs one section from the newest backup holding a valid copy,
+// resetting it to defaults if no backup has one
+func restoreKey(check config.DataCheck, backups []string) {
+	for _, backup := range backups {
+		if err := store.Inspect(backup, check.Check); err != nil {
+			continue
+		}
+		if err := store.RestoreKey(check.Key, backup); err != nil {
+			continue
+		}
+		fmt.Printf("   🔧 %s restored from %s\n", check.Key, filepath.Base(backup))
+		return
+	}
+
+	err := store.Update(func(tx *store.Tx) error {
+		return tx.Delete(check.Key)
+	})
+	if err != nil {
+		fmt.Printf("   ❌ Failed to reset %s: %v\n", check.Key, err)
+		return
+	}
+	fmt.Printf("   🔧 %s reset to defaults (no usable backup found)\n", check.Key)
+}
+
+func init() {
+	doctorCmd.Flags().BoolVar(&doctorFix, "fix", false, "repair problems using backups")
+
+	doctorCmd.AddCommand(doctorBackupCmd)
+	doctorCmd.AddCommand(doctorBackupsCmd)
+	rootCmd.AddCommand(doctorCmd)
+}
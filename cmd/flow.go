@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Flack74/pom/config"
+	"github.com/Flack74/pom/daemon"
+	"github.com/Flack74/pom/timer"
+)
+
+var (
+	flowRatio  float64
+	flowCycles int
+	flowTaskID string
+	flowSave   bool
+)
+
+var flowCmd = &cobra.Command{
+	Use:   "flow",
+	Short: "🌊 Start an open-ended focus session",
+	Long: `🌊 Flowtime: Open-Ended Focus
+
+Focus for as long as the work needs instead of a fixed 25 minutes. The
+focus timer counts up until you end it, and the break that follows is
+worked out from the time you spent: one break minute per --ratio focus
+minutes (5 by default, so 50 minutes of flow earn a 10 minute break).
+
+During the session:
+  • Press 's' to end the focus and start your break
+  • Press 'p' to pause and 'r' to resume
+  • Press 'q' or Ctrl+C to end the flow without a break
+  • Press 'd' to detach and leave the timer running
+
+'pom skip' and 'pom stop' do the same from another terminal. Flow
+intervals are logged like regular sessions, so stats, insights, goals and
+task time tracking include them.
+
+Examples:
+  pom flow                      Flow until you stop, then take a break
+  pom flow --ratio 3            One break minute per 3 focus minutes
+  pom flow --ratio 4 -c         Save the ratio as your default
+  pom flow -s 3                 Three flow and break cycles
+  pom flow -t 3                 Link the time to planned task #3`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, _ := config.LoadConfig()
+		if !cmd.Flags().Changed("ratio") {
+			flowRatio = cfg.BreakRatio()
+		}
+		if flowRatio <= 0 {
+			fmt.Fprintln(os.Stderr, "Error: the ratio must be positive")
+			os.Exit(1)
+		}
+		if flowCycles < 1 {
+			fmt.Fprintln(os.Stderr, "Error: cycles must be at least 1")
+			os.Exit(1)
+		}
+
+		if flowSave {
+			cfg.FlowRatio = flowRatio
+			if err := config.SaveConfig(cfg); err != nil {
+				fmt.Fprintf(os.Stderr, "Error saving config: %v\n", err)
+			} else {
+				fmt.Printf("✅ Saved break ratio 1:%g as default\n", flowRatio)
+			}
+		}
+
+		theme, err := config.LoadTheme()
+		if err != nil {
+			theme = config.DefaultTheme
+		}
+
+		if flowTaskID != "" {
+			task, err := findTask(flowTaskID)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%s⚠️  %v%s\n", theme.WarningColor, err, theme.TextColor)
+				os.Exit(1)
+			}
+			flowTaskID = task.ID
+			fmt.Printf("%s📎 Linked to task: %s%s\n", theme.HighlightColor, task.Title, theme.TextColor)
+		}
+
+		client, err := daemon.Connect()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s⚠️  %v%s\n", theme.WarningColor, err, theme.TextColor)
+			os.Exit(1)
+		}
+		defer client.Close()
+
+		_, err = client.Call(daemon.MethodStart, daemon.StartParams{
+			Intervals: timer.FlowPlan(flowCycles, flowRatio),
+			TaskID:    flowTaskID,
+		})
+		if err != nil {
+			if snap, statusErr := client.Call(daemon.MethodStatus, nil); statusErr == nil && snap.Active {
+				fmt.Println("⏳ A session is already running. Attach to it with: pom status --watch")
+			} else {
+				fmt.Fprintf(os.Stderr, "%s⚠️  Failed to start flow: %v%s\n", theme.WarningColor, err, theme.TextColor)
+			}
+			os.Exit(1)
+		}
+
+		fmt.Printf("\n%s🌊 Starting Flow%s\n", theme.HighlightColor, theme.TextColor)
+		fmt.Printf("%s📚 Focus until you press 's'. Break: 1 min per %g min of focus%s\n\n",
+			theme.TextColor, flowRatio, theme.TextColor)
+
+		switch attach(theme, true) {
+		case attachFinished:
+			fmt.Println("🎉 Flow session completed!")
+			if flowTaskID != "" {
+				fmt.Println("📝 Task progress updated")
+			}
+		case attachStopped:
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	flowCmd.Flags().Float64VarP(&flowRatio, "ratio", "r", config.DefaultFlowRatio, "focus minutes per break minute")
+	flowCmd.Flags().IntVarP(&flowCycles, "sessions", "s", 1, "number of flow and break cycles")
+	flowCmd.Flags().StringVarP(&flowTaskID, "task", "t", "", "link session to a task number or ID")
+	flowCmd.Flags().BoolVarP(&flowSave, "save-config", "c", false, "save the ratio as default")
+
+	rootCmd.AddCommand(flowCmd)
+}
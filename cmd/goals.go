@@ -3,6 +3,7 @@ package cmd
 import (
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/Flack74/pom/config"
@@ -12,20 +13,30 @@ import (
 
 var goalsCmd = &cobra.Command{
 	Use:   "goals",
-	Short: "🎯 Manage daily Pomodoro goals",
-	Long: `🎯 Set and Track Your Daily Goals
+	Short: "🎯 Manage Pomodoro goals",
+	Long: `🎯 Set and Track Your Goals
 
-Stay motivated by setting and tracking daily Pomodoro goals:
-  • Set target number of sessions per day
-  • Set target focus minutes per day
-  • Track your progress
-  • Build and maintain streaks
-  • Get motivational feedback
+Stay motivated by setting and tracking Pomodoro goals:
+  • Set target sessions and focus minutes per day
+  • Give weekdays their own targets, or take them off as rest days
+  • Add weekly and monthly targets
+  • Build streaks, recomputed from your session history
+  • Earn streak freezes that cover a missed day
+  • Add named goals for a tag, task or profile
+
+Met days in a row earn a streak freeze (one per 7 days by default, up to 2
+banked). A missed day uses a freeze instead of breaking the streak. Rest days
+neither extend nor break it.
 
 Examples:
-  pom goals set 8 240     Set goal: 8 sessions, 240 minutes
-  pom goals show          View current goals and progress
-  pom goals reset         Reset today's progress`,
+  pom goals set 8 240                 Set goal: 8 sessions, 240 minutes a day
+  pom goals set --rest sat,sun        Weekends off
+  pom goals set --day fri=4:100       Lighter Fridays: 4 sessions, 100 minutes
+  pom goals set --weekly-minutes 900  Also aim for 15 hours a week
+  pom goals show                      View current goals and progress
+  pom goals history                   Days met and missed
+  pom goals add backend 10h/week tag:backend
+  pom goals add study 2sessions/day profile:study`,
 	Run: func(cmd *cobra.Command, args []string) {
 		if err := config.ShowProgress(); err != nil {
 			fmt.Printf("Error showing progress: %v\n", err)
@@ -35,34 +46,68 @@ Examples:
 
 var setGoalCmd = &cobra.Command{
 	Use:   "set [sessions] [minutes]",
-	Short: "Set daily goals",
-	Long:  "Set daily goals for number of sessions and total focus minutes",
-	Args:  cobra.ExactArgs(2),
+	Short: "Set goals",
+	Long: `Set the daily targets for number of sessions and total focus minutes,
+and optionally per-weekday targets, rest days, weekly and monthly targets and
+streak freezes. Settings not given are kept.
+
+Examples:
+  pom goals set 6 150 --rest sun
+  pom goals set --day sat=2:50 --day sun=2:50
+  pom goals set --day sat=default          Use the daily target on Saturdays
+  pom goals set --rest none                No rest days
+  pom goals set --monthly-sessions 120
+  pom goals set --freeze-every 5 --max-freezes 3
+  pom goals set --no-freezes`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 0 && len(args) != 2 {
+			return fmt.Errorf("give both sessions and minutes, or neither")
+		}
+		return nil
+	},
 	Run: func(cmd *cobra.Command, args []string) {
-		sessions, err := strconv.Atoi(args[0])
-		if err != nil {
-			fmt.Printf("Invalid number of sessions: %v\n", err)
+		if len(args) == 0 && cmd.Flags().NFlag() == 0 {
+			fmt.Println("Give daily targets and/or options, e.g. pom goals set 8 240 --rest sat,sun")
 			return
 		}
 
-		minutes, err := strconv.Atoi(args[1])
+		goal, err := config.LoadGoal()
 		if err != nil {
-			fmt.Printf("Invalid number of minutes: %v\n", err)
+			fmt.Printf("Error loading goal: %v\n", err)
 			return
 		}
 
-		goal := config.Goal{
-			DailySessionTarget: sessions,
-			DailyMinutes:       minutes,
-			StartDate:          time.Now(),
+		if len(args) == 2 {
+			sessions, err := strconv.Atoi(args[0])
+			if err != nil {
+				fmt.Printf("Invalid number of sessions: %v\n", err)
+				return
+			}
+
+			minutes, err := strconv.Atoi(args[1])
+			if err != nil {
+				fmt.Printf("Invalid number of minutes: %v\n", err)
+				return
+			}
+			goal.DailySessionTarget, goal.DailyMinutes = sessions, minutes
+		}
+
+		if err := applyGoalFlags(cmd, &goal); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
 		}
+		goal.StartDate = time.Now().UTC()
 
 		if err := config.SaveGoal(goal); err != nil {
 			fmt.Printf("Error saving goal: %v\n", err)
 			return
 		}
+		if err := config.UpdateProgress(); err != nil {
+			fmt.Printf("Error updating goals progress: %v\n", err)
+		}
 
-		fmt.Printf("Daily goals set: %d sessions, %d minutes\n", sessions, minutes)
+		fmt.Println("✅ Goals set")
+		printGoal(goal)
 	},
 }
 
@@ -70,14 +115,236 @@ var showGoalCmd = &cobra.Command{
 	Use:   "show",
 	Short: "Show current goals and progress",
 	Run: func(cmd *cobra.Command, args []string) {
+		goal, err := config.LoadGoal()
+		if err != nil {
+			fmt.Printf("Error loading goal: %v\n", err)
+			return
+		}
+		printGoal(goal)
+
 		if err := config.ShowProgress(); err != nil {
 			fmt.Printf("Error showing progress: %v\n", err)
 		}
 	},
 }
 
+var goalHistoryCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Show past days met or missed",
+	Run: func(cmd *cobra.Command, args []string) {
+		days, _ := cmd.Flags().GetInt("days")
+		if days <= 0 {
+			fmt.Println("Error: --days must be positive")
+			return
+		}
+
+		history, err := config.GoalHistory()
+		if err != nil {
+			fmt.Printf("Error loading goal history: %v\n", err)
+			return
+		}
+		if len(history) > days {
+			history = history[len(history)-days:]
+		}
+
+		fmt.Printf("\n🎯 Goal history (last %d days)\n\n", len(history))
+		for i := len(history) - 1; i >= 0; i-- {
+			day := history[i]
+			target := day.Target.String()
+			if day.Status == config.DayRest {
+				target = "rest day"
+			}
+			fmt.Printf("%s  %s %-8s %3d sessions %4d min   target %-20s streak %d\n",
+				day.Date.Format("Mon Jan 02"), goalStatusIcon(day.Status), day.Status,
+				day.Sessions, day.Minutes, target, day.Streak)
+		}
+	},
+}
+
+var addGoalCmd = &cobra.Command{
+	Use:   "add [name] [target]... [scope]...",
+	Short: "Add a named goal for a tag, task or profile",
+	Long: `Add a named goal that counts the focus time of matching sessions only.
+
+Targets are per day, week or month, in hours, minutes or sessions:
+  10h/week  90m/day  2sessions/day  40sessions/month
+
+Scopes choose the sessions that count; several scopes must all match:
+  tag:NAME      sessions on tasks with this tag
+  task:REF      sessions on this task, by number or ID
+  profile:NAME  sessions run with this profile
+
+Examples:
+  pom goals add backend 10h/week tag:backend
+  pom goals add study 2sessions/day 50m/day profile:study
+  pom goals add writing 20h/month`,
+	Args: cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		goal := config.NamedGoal{Name: args[0]}
+		for _, arg := range args[1:] {
+			var err error
+			if strings.Contains(arg, "/") {
+				err = config.ParseGoalTarget(&goal, arg)
+			} else {
+				err = config.ParseGoalScope(&goal, arg)
+			}
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				return
+			}
+		}
+		if goal.TaskID != "" {
+			task, err := config.GetTask(goal.TaskID)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				return
+			}
+			goal.TaskID = task.ID
+		}
+
+		goal, err := config.AddNamedGoal(goal)
+		if err != nil {
+			fmt.Printf("Error adding goal: %v\n", err)
+			return
+		}
+		fmt.Printf("✅ Goal '%s' added: %s on %s\n", goal.Name, goal.Target(), goal.Scope())
+	},
+}
+
+var removeGoalCmd = &cobra.Command{
+	Use:   "remove [name]",
+	Short: "Remove a named goal",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := config.RemoveNamedGoal(args[0]); err != nil {
+			fmt.Printf("Error removing goal: %v\n", err)
+			return
+		}
+		fmt.Printf("🗑️  Goal '%s' removed\n", args[0])
+	},
+}
+
+// applyGoalFlags applies the goal options given on the command line
+func applyGoalFlags(cmd *cobra.Command, goal *config.Goal) error {
+	flags := cmd.Flags()
+	for name, target := range map[string]*int{
+		"weekly-sessions":  &goal.WeeklySessions,
+		"weekly-minutes":   &goal.WeeklyMinutes,
+		"monthly-sessions": &goal.MonthlySessions,
+		"monthly-minutes":  &goal.MonthlyMinutes,
+		"freeze-every":     &goal.FreezeEvery,
+		"max-freezes":      &goal.MaxFreezes,
+	} {
+		if flags.Changed(name) {
+			*target, _ = flags.GetInt(name)
+		}
+	}
+	if flags.Changed("no-freezes") {
+		goal.NoFreezes, _ = flags.GetBool("no-freezes")
+	}
+
+	if flags.Changed("rest") {
+		rest, _ := flags.GetStringSlice("rest")
+		goal.RestDays = nil
+		for _, name := range rest {
+			if name == "none" {
+				continue
+			}
+			day, err := config.ParseWeekday(name)
+			if err != nil {
+				return err
+			}
+			goal.RestDays = append(goal.RestDays, day)
+		}
+	}
+
+	if flags.Changed("day") {
+		values, _ := flags.GetStringArray("day")
+		for _, value := range values {
+			name, target, ok := strings.Cut(value, "=")
+			if !ok {
+				return fmt.Errorf("invalid weekday target '%s', use DAY=SESSIONS:MINUTES", value)
+			}
+			day, err := config.ParseWeekday(name)
+			if err != nil {
+				return err
+			}
+			if target == "default" {
+				delete(goal.Weekdays, day)
+				continue
+			}
+
+			sessions, minutes, ok := strings.Cut(target, ":")
+			s, err1 := strconv.Atoi(sessions)
+			m, err2 := strconv.Atoi(minutes)
+			if !ok || err1 != nil || err2 != nil {
+				return fmt.Errorf("invalid weekday target '%s', use DAY=SESSIONS:MINUTES", value)
+			}
+			if goal.Weekdays == nil {
+				goal.Weekdays = map[string]config.DayTarget{}
+			}
+			goal.Weekdays[day] = config.DayTarget{Sessions: s, Minutes: m}
+		}
+	}
+	return nil
+}
+
+// printGoal prints the goal's targets
+func printGoal(goal config.Goal) {
+	fmt.Printf("   Daily:   %d sessions, %d minutes\n", goal.DailySessionTarget, goal.DailyMinutes)
+	for _, day := range config.Weekdays {
+		if target, ok := goal.Weekdays[day]; ok {
+			fmt.Printf("   %-8s %d sessions, %d minutes\n", strings.ToUpper(day[:1])+day[1:]+":", target.Sessions, target.Minutes)
+		}
+	}
+	if len(goal.RestDays) > 0 {
+		fmt.Printf("   Rest:    %s\n", strings.Join(goal.RestDays, ", "))
+	}
+	if goal.WeeklySessions > 0 || goal.WeeklyMinutes > 0 {
+		fmt.Printf("   Weekly:  %d sessions, %d minutes\n", goal.WeeklySessions, goal.WeeklyMinutes)
+	}
+	if goal.MonthlySessions > 0 || goal.MonthlyMinutes > 0 {
+		fmt.Printf("   Monthly: %d sessions, %d minutes\n", goal.MonthlySessions, goal.MonthlyMinutes)
+	}
+	if every, max := goal.Freezes(); every > 0 {
+		fmt.Printf("   Freezes: one per %d met days, up to %d\n", every, max)
+	} else {
+		fmt.Println("   Freezes: off")
+	}
+}
+
+// goalStatusIcon returns the icon for a goal history status
+func goalStatusIcon(status string) string {
+	switch status {
+	case config.DayMet:
+		return "✅"
+	case config.DayMissed:
+		return "❌"
+	case config.DayFrozen:
+		return "🧊"
+	case config.DayRest:
+		return "💤"
+	default:
+		return "⏳"
+	}
+}
+
 func init() {
+	setGoalCmd.Flags().Int("weekly-sessions", 0, "target sessions per week (0 for none)")
+	setGoalCmd.Flags().Int("weekly-minutes", 0, "target focus minutes per week (0 for none)")
+	setGoalCmd.Flags().Int("monthly-sessions", 0, "target sessions per month (0 for none)")
+	setGoalCmd.Flags().Int("monthly-minutes", 0, "target focus minutes per month (0 for none)")
+	setGoalCmd.Flags().StringSlice("rest", nil, "rest days, e.g. sat,sun (none to clear)")
+	setGoalCmd.Flags().StringArray("day", nil, "weekday target DAY=SESSIONS:MINUTES, or DAY=default (repeatable)")
+	setGoalCmd.Flags().Int("freeze-every", 0, "met days in a row that earn a streak freeze (0 for 7)")
+	setGoalCmd.Flags().Int("max-freezes", 0, "streak freezes that can be banked (0 for 2)")
+	setGoalCmd.Flags().Bool("no-freezes", false, "never use streak freezes")
+	goalHistoryCmd.Flags().IntP("days", "d", 14, "number of days to show")
+
 	goalsCmd.AddCommand(setGoalCmd)
 	goalsCmd.AddCommand(showGoalCmd)
+	goalsCmd.AddCommand(goalHistoryCmd)
+	goalsCmd.AddCommand(addGoalCmd)
+	goalsCmd.AddCommand(removeGoalCmd)
 	rootCmd.AddCommand(goalsCmd)
 }
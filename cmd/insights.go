@@ -1,7 +1,10 @@
 package cmd
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/Flack74/pom/config"
 	"github.com/spf13/cobra"
@@ -18,8 +21,23 @@ Get personalized suggestions based on your Pomodoro history:
   • Productivity patterns
   • Performance improvements
 
+'pom insights analyze' breaks your focus down by weekday, hour, week,
+profile, task and tag, looks at interruptions and pauses, and rates it with
+a focus score from 0 to 100:
+
+  100 × (0.4 × completion + 0.2 × consistency + 0.2 × depth + 0.2 × calm)
+
+  completion   share of focus sessions run to the end
+  consistency  share of days with a completed focus session
+  depth        average focus length over 50 minutes, at most 1
+  calm         1 minus the share of focus time spent paused
+
 Examples:
+  pom insights analyze          Analyze the last 28 days
+  pom insights analyze -d 90    Analyze the last 90 days
+  pom insights analyze --json   Print the analysis as JSON
   pom insights suggest          Get AI suggestions
+  pom insights coach            Weekly coaching note
   pom insights calendar         View session calendar
   pom insights today           Today's statistics`,
 }
@@ -39,7 +57,7 @@ var suggestCmd = &cobra.Command{
 			return
 		}
 
-		fmt.Println("🧠 AI Suggestions for Better Productivity:\n")
+		fmt.Print("🧠 AI Suggestions for Better Productivity:\n\n")
 		for i, suggestion := range suggestions {
 			confidence := int(suggestion.Confidence * 100)
 			fmt.Printf("%d. %s (Confidence: %d%%)\n", i+1, suggestion.Message, confidence)
@@ -62,7 +80,251 @@ var suggestCmd = &cobra.Command{
 			fmt.Printf("📊 Your Stats:\n")
 			fmt.Printf("   Completion Rate: %.1f%%\n", stats.CompletionRate*100)
 			fmt.Printf("   Average Work Time: %.1f minutes\n", stats.AverageWorkTime)
-			fmt.Printf("   Productivity Score: %.1f/100\n", stats.ProductivityScore)
+			fmt.Printf("   Focus Score: %.1f/100 (see 'pom insights analyze')\n", stats.ProductivityScore)
+		}
+	},
+}
+
+var analyzeCmd = &cobra.Command{
+	Use:   "analyze",
+	Short: "Analyze completion, trends, interruptions and your focus score",
+	Run: func(cmd *cobra.Command, args []string) {
+		days, _ := cmd.Flags().GetInt("days")
+		asJSON, _ := cmd.Flags().GetBool("json")
+		if days < 1 || days > config.MaxInsightDays {
+			fmt.Printf("Error: days must be between 1 and %d\n", config.MaxInsightDays)
+			return
+		}
+
+		insights, err := config.AnalyzeInsights(days)
+		if err != nil {
+			fmt.Printf("Error analyzing sessions: %v\n", err)
+			return
+		}
+		if asJSON {
+			data, _ := json.MarshalIndent(insights, "", "  ")
+			fmt.Println(string(data))
+			return
+		}
+		printInsights(insights)
+	},
+}
+
+// printInsights prints an insights report
+func printInsights(insights config.Insights) {
+	fmt.Printf("\n🧠 Insights for %s to %s (%d days)\n\n",
+		insights.Since.Format("Jan 2"), insights.Until.Format("Jan 2"), insights.Days)
+
+	focus := insights.Focus
+	if focus.Sessions == 0 {
+		fmt.Println("🤖 No focus sessions in this period yet.")
+		return
+	}
+
+	score := insights.Score
+	fmt.Printf("🎯 Focus Score: %.1f/100\n", score.Score)
+	fmt.Printf("   Completion %3.0f%% · Consistency %3.0f%% · Depth %3.0f%% · Calm %3.0f%%\n",
+		score.Completion*100, score.Consistency*100, score.Depth*100, score.Calm*100)
+	fmt.Printf("   %d focus sessions, %d completed, %.0f minutes\n", focus.Sessions, focus.Completed, focus.Minutes)
+
+	fmt.Println("\n📅 By weekday")
+	printCurve(insights.Weekdays)
+	fmt.Println("\n🕐 By hour")
+	var hours []config.InsightBucket
+	for _, hour := range insights.Hours {
+		if hour.Sessions > 0 {
+			hours = append(hours, hour)
+		}
+	}
+	printCurve(hours)
+	if insights.BestWeekday != "" {
+		fmt.Printf("   Best weekday: %s\n", insights.BestWeekday)
+	}
+	if insights.BestHour >= 0 {
+		fmt.Printf("   Best hour: %02d:00\n", insights.BestHour)
+	}
+
+	fmt.Println("\n📈 Rolling weeks")
+	for _, week := range insights.Weeks {
+		fmt.Printf("   %-16s %4.0f min %s %3.0f%% completed\n",
+			week.Label, week.Minutes, bar(week.Minutes, maxMinutes(insights.Weeks), 20), week.CompletionRate*100)
+	}
+	fmt.Printf("   %s %s\n", trendIcon(insights.Trend.Direction), insights.Trend.Summary)
+
+	printBreakdown("👥 By profile", insights.Profiles)
+	printBreakdown("📝 By task", insights.Tasks)
+	printBreakdown("🏷️  By tag", insights.Tags)
+
+	if estimates := insights.Estimates; estimates.Tasks > 0 {
+		fmt.Println("\n📏 Estimates")
+		fmt.Printf("   %s\n", estimates.Summary)
+		fmt.Printf("   %d sessions for %d estimated · %d over, %d under, %d on target · %.0f%% accurate\n",
+			estimates.Actual, estimates.Estimated, estimates.Over, estimates.Under, estimates.OnTarget, estimates.Accuracy*100)
+	}
+
+	stops := insights.Interruptions
+	fmt.Println("\n⏸️  Interruptions and pauses")
+	fmt.Printf("   Cut short: %d stopped, %d skipped, %d abandoned\n", stops.Interrupted, stops.Skipped, stops.Abandoned)
+	if focus.Interrupted > 0 {
+		fmt.Printf("   On average after %.1f minutes (%.0f%% of the planned time)\n", stops.AvgMinutesIn, stops.AvgShareDone*100)
+		fmt.Printf("   Most often on %s and around %02d:00\n", stops.WorstWeekday, stops.WorstHour)
+	}
+	fmt.Printf("   Paused: %d sessions, %.0f minutes in total, %.1f minutes each on average\n\n",
+		stops.PausedSessions, stops.PauseMinutes, stops.AvgPauseMinutes)
+}
+
+// printCurve prints the completion rate of each bucket as a bar
+func printCurve(buckets []config.InsightBucket) {
+	for _, bucket := range buckets {
+		if bucket.Sessions == 0 {
+			fmt.Printf("   %-10s %s\n", bucket.Label, "-")
+			continue
+		}
+		fmt.Printf("   %-10s %s %3.0f%% of %d\n", bucket.Label, bar(bucket.CompletionRate, 1, 20), bucket.CompletionRate*100, bucket.Sessions)
+	}
+}
+
+// printBreakdown prints focus time per profile, task or tag
+func printBreakdown(title string, buckets []config.InsightBucket) {
+	if len(buckets) == 0 {
+		return
+	}
+	fmt.Printf("\n%s\n", title)
+	for _, bucket := range buckets {
+		fmt.Printf("   %-24s %5.0f min %4d sessions %4.0f%% completed\n",
+			truncate(bucket.Label, 24), bucket.Minutes, bucket.Sessions, bucket.CompletionRate*100)
+	}
+}
+
+// bar draws value out of max as a bar width characters wide
+func bar(value, max float64, width int) string {
+	filled := 0
+	if max > 0 {
+		filled = int(value / max * float64(width))
+	}
+	return strings.Repeat("█", filled) + strings.Repeat("░", width-filled)
+}
+
+// truncate shortens text to at most n characters
+func truncate(text string, n int) string {
+	runes := []rune(text)
+	if len(runes) <= n {
+		return text
+	}
+	return string(runes[:n-1]) + "…"
+}
+
+func maxMinutes(weeks []config.WeekTrend) float64 {
+	max := 0.0
+	for _, week := range weeks {
+		if week.Minutes > max {
+			max = week.Minutes
+		}
+	}
+	return max
+}
+
+func trendIcon(direction string) string {
+	switch direction {
+	case config.TrendUp:
+		return "↗️"
+	case config.TrendDown:
+		return "↘️"
+	}
+	return "➡️"
+}
+
+var coachCmd = &cobra.Command{
+	Use:   "coach",
+	Short: "Get a weekly coaching note",
+	Long: `Get a coaching note about your last 7 days of focus.
+
+By default the note is written by the built-in rules. With an endpoint set
+up, an anonymised summary of your sessions is sent to an OpenAI-compatible
+chat completions server, such as a local llama.cpp or Ollama server, which
+writes the note instead. The summary holds only counts, rates and times of
+day: no task titles, tags or profile names. Use --show-data to see it.
+
+Nothing is sent while privacy mode is on, and the rules take over if the
+endpoint is not reachable or does not answer in time. An API key, if the
+server needs one, is read from the POM_COACH_API_KEY environment variable.
+
+Examples:
+  pom insights coach                                  Weekly coaching note
+  pom insights coach --show-data                      Also print what was sent
+  pom insights coach setup http://localhost:11434/v1 --model llama3.1
+                                                      Use a local Ollama server
+  pom insights coach setup --off                      Back to the built-in rules`,
+	Run: func(cmd *cobra.Command, args []string) {
+		showData, _ := cmd.Flags().GetBool("show-data")
+
+		coaching, err := config.WeeklyCoaching(context.Background())
+		if err != nil {
+			fmt.Printf("Error generating coaching: %v\n", err)
+			return
+		}
+
+		fmt.Println("🧑‍🏫 Weekly Coaching:")
+		fmt.Println()
+		fmt.Println(coaching.Narrative)
+		fmt.Println()
+		if coaching.Fallback {
+			fmt.Printf("⚠️  The coach endpoint failed (%s), so the built-in rules wrote this note.\n", coaching.Error)
+		} else if coaching.Provider == config.ProviderRules {
+			fmt.Println("💡 Written by the built-in rules. See 'pom insights coach --help' to use a local model.")
+		}
+		if showData {
+			data, _ := json.MarshalIndent(coaching.Summary, "", "  ")
+			fmt.Printf("\n📤 Summary given to the coach:\n%s\n", data)
+		}
+	},
+}
+
+var coachSetupCmd = &cobra.Command{
+	Use:   "setup [endpoint]",
+	Short: "Configure the coaching endpoint",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		off, _ := cmd.Flags().GetBool("off")
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			fmt.Printf("Error loading config: %v\n", err)
+			return
+		}
+
+		switch {
+		case off:
+			cfg.Coach = config.CoachConfig{}
+		case len(args) == 1:
+			cfg.Coach.Endpoint = args[0]
+		case cfg.Coach.Endpoint == "":
+			fmt.Println("Give the endpoint's base URL, e.g. pom insights coach setup http://localhost:11434/v1")
+			return
+		}
+		if !off {
+			if cmd.Flags().Changed("model") {
+				cfg.Coach.Model, _ = cmd.Flags().GetString("model")
+			}
+			if cmd.Flags().Changed("timeout") {
+				cfg.Coach.TimeoutSeconds, _ = cmd.Flags().GetInt("timeout")
+			}
+		}
+		if err := config.ValidateCoachConfig(cfg.Coach); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		if err := config.SaveConfig(cfg); err != nil {
+			fmt.Printf("Error saving config: %v\n", err)
+			return
+		}
+		if off {
+			fmt.Println("✅ Coaching uses the built-in rules")
+			return
+		}
+		fmt.Printf("✅ Coaching uses %s (model %q, timeout %s)\n", cfg.Coach.Endpoint, cfg.Coach.Model, cfg.Coach.Timeout())
+		if cfg.PrivacyMode {
+			fmt.Println("🔐 Privacy mode is on, so nothing is sent until it is disabled")
 		}
 	},
 }
@@ -110,6 +372,17 @@ var todayCmd = &cobra.Command{
 func init() {
 	calendarCmd.Flags().Int("months", 3, "Number of months to show")
 	
+	analyzeCmd.Flags().IntP("days", "d", config.DefaultInsightDays, "Number of days to analyze")
+	analyzeCmd.Flags().Bool("json", false, "Print the analysis as JSON")
+
+	coachCmd.Flags().Bool("show-data", false, "Print the summary given to the coach")
+	coachSetupCmd.Flags().String("model", "", "Model name sent to the endpoint")
+	coachSetupCmd.Flags().Int("timeout", 0, "Request timeout in seconds (default 30)")
+	coachSetupCmd.Flags().Bool("off", false, "Stop using the endpoint")
+	coachCmd.AddCommand(coachSetupCmd)
+
+	insightsCmd.AddCommand(analyzeCmd)
+	insightsCmd.AddCommand(coachCmd)
 	insightsCmd.AddCommand(suggestCmd)
 	insightsCmd.AddCommand(calendarCmd)
 	insightsCmd.AddCommand(todayCmd)
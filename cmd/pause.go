@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Flack74/pom/daemon"
+)
+
+var pauseCmd = &cobra.Command{
+	Use:   "pause",
+	Short: "⏸️  Pause the running session",
+	Long: `⏸️  Pause the Running Session
+
+Pause the session running in the pom daemon, from any terminal. Paused
+time is logged separately and does not count as focus time.
+
+Examples:
+  pom pause     Pause the timer
+  pom resume    Carry on`,
+	Run: func(cmd *cobra.Command, args []string) {
+		snap, err := callDaemon(daemon.MethodPause)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println("⏸️  Timer paused. Resume with: pom resume")
+		printStatus(snap)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(pauseCmd)
+}
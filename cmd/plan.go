@@ -2,9 +2,14 @@ package cmd
 
 import (
 	"fmt"
+	"sort"
 	"strings"
+	"time"
 
+	"github.com/Flack74/pom/clock"
 	"github.com/Flack74/pom/config"
+	"github.com/Flack74/pom/daemon"
+	"github.com/Flack74/pom/history"
 
 	"github.com/spf13/cobra"
 )
@@ -20,12 +25,22 @@ Organize your work by planning tasks for your Pomodoro sessions:
   • Link tasks to Pomodoro sessions
   • View task completion statistics
   • Organize work by projects
+  • Estimate tasks in pomodoros and compare with the sessions they took
+  • Find tasks with queries such as 'tag:work due:<friday !done'
+
+Tasks are referred to by their number (3 or #3), their ID or the start of it.
 
 Examples:
   pom plan add "Write documentation"    Add a new task
+  pom plan add "Ship v2" -e 8 -p high --due friday --project launch
   pom plan list                        List all tasks
-  pom plan complete task-id            Mark task as complete
-  pom start -t task-id                 Start session for task`,
+  pom plan list --sort due --overdue   Overdue tasks, earliest first
+  pom plan list 'tag:work due:<friday !done'
+  pom plan show 3                      Details and sessions of task #3
+  pom plan edit 3 --estimate 6 --due tomorrow
+  pom plan complete 3                  Mark task as complete
+  pom plan archive --done              Hide all completed tasks
+  pom start -t 3                       Start session for task`,
 	Run: func(cmd *cobra.Command, args []string) {
 		// ... existing code ...
 	},
@@ -37,52 +52,342 @@ var addTaskCmd = &cobra.Command{
 	Args:  cobra.MinimumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		title := strings.Join(args, " ")
-		description, _ := cmd.Flags().GetString("description")
-		tags, _ := cmd.Flags().GetStringSlice("tags")
+		task := config.Task{Title: title}
+		task.Description, _ = cmd.Flags().GetString("description")
+		task.Tags, _ = cmd.Flags().GetStringSlice("tags")
+		task.Estimate, _ = cmd.Flags().GetInt("estimate")
+		task.Project, _ = cmd.Flags().GetString("project")
+		priority, _ := cmd.Flags().GetString("priority")
+		due, _ := cmd.Flags().GetString("due")
+
+		var err error
+		if task.Priority, err = config.ParsePriority(priority); err != nil {
+			fmt.Printf("Error adding task: %v\n", err)
+			return
+		}
+		if task.Due, err = config.ParseDueDate(due, clock.Load(), time.Now()); err != nil {
+			fmt.Printf("Error adding task: %v\n", err)
+			return
+		}
 
-		if err := config.AddTask(title, description, tags); err != nil {
+		added, err := config.AddTask(task)
+		if err != nil {
 			fmt.Printf("Error adding task: %v\n", err)
 			return
 		}
 
-		fmt.Printf("Task added: %s\n", title)
+		fmt.Printf("Task added: #%d %s\n", added.Number, title)
 	},
 }
 
 var listTasksCmd = &cobra.Command{
-	Use:   "list",
-	Short: "List all tasks",
+	Use:   "list [query...]",
+	Short: "List tasks, optionally matching a query",
+	Long: `List open tasks, or the tasks matching a query. Terms are separated by
+spaces and must all match; '!' in front of a term negates it. Double quotes
+keep spaces in a value, as in title:"write report". Quote the whole query so
+the shell leaves '<', '!' and the double quotes alone.
+
+Query terms:
+  tag:NAME  project:NAME  priority:LEVEL  title:TEXT
+  due:DATE  due:<DATE  due:<=DATE  due:>DATE  due:>=DATE  due:none
+  estimate:N  estimate:<N  estimate:>N  estimate:none  (est: for short)
+  done  open  overdue  archived             or is:done, is:open, ...
+  any other word                            part of the title
+
+Dates take YYYY-MM-DD, today, tomorrow or a weekday, meaning the next one.
+Completed and archived tasks are listed only with --all and --archived, or
+when the query asks about them.
+
+Examples:
+  pom plan list 'tag:work due:<friday !done'
+  pom plan list 'project:launch est:>4' --sort variance
+  pom plan list 'done !archived' --sort title`,
 	Run: func(cmd *cobra.Command, args []string) {
-		showCompleted, _ := cmd.Flags().GetBool("all")
-		if err := config.ListTasks(showCompleted); err != nil {
+		var filter config.TaskFilter
+		filter.Completed, _ = cmd.Flags().GetBool("all")
+		filter.Archived, _ = cmd.Flags().GetBool("archived")
+		filter.Project, _ = cmd.Flags().GetString("project")
+		filter.Tag, _ = cmd.Flags().GetString("tag")
+		filter.Overdue, _ = cmd.Flags().GetBool("overdue")
+		priority, _ := cmd.Flags().GetString("priority")
+		due, _ := cmd.Flags().GetString("due")
+		sortBy, _ := cmd.Flags().GetString("sort")
+
+		var err error
+		if filter.Priority, err = config.ParsePriority(priority); err != nil {
+			fmt.Printf("Error listing tasks: %v\n", err)
+			return
+		}
+		if filter.DueBy, err = config.ParseDueDate(due, clock.Load(), time.Now()); err != nil {
+			fmt.Printf("Error listing tasks: %v\n", err)
+			return
+		}
+		if filter.Query, err = config.ParseTaskQuery(strings.Join(args, " "), clock.Load(), time.Now()); err != nil {
+			fmt.Printf("Error listing tasks: %v\n", err)
+			return
+		}
+		if err := config.ListTasks(filter, sortBy); err != nil {
 			fmt.Printf("Error listing tasks: %v\n", err)
 		}
 	},
 }
 
+var showTaskCmd = &cobra.Command{
+	Use:   "show [task]",
+	Short: "Show a task and the sessions spent on it",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		task, err := config.GetTask(args[0])
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		rules, now := clock.Load(), time.Now()
+
+		status := "open"
+		switch {
+		case task.IsCompleted:
+			status = "completed " + rules.In(task.CompletedAt).Format("Mon Jan 2, 15:04")
+		case task.IsOverdue(rules, now):
+			status = "open, overdue"
+		}
+		if task.Archived {
+			status += ", archived"
+		}
+
+		fmt.Printf("\n📝 #%d %s\n", task.Number, task.Title)
+		if task.Description != "" {
+			fmt.Printf("   %s\n", task.Description)
+		}
+		fmt.Printf("   ID:       %s\n", task.ID)
+		fmt.Printf("   Status:   %s\n", status)
+		fmt.Printf("   Created:  %s\n", rules.In(task.CreatedAt).Format("Mon Jan 2, 15:04"))
+		if task.Project != "" {
+			fmt.Printf("   Project:  %s\n", task.Project)
+		}
+		if task.Priority != "" {
+			fmt.Printf("   Priority: %s\n", task.Priority)
+		}
+		if !task.Due.IsZero() {
+			fmt.Printf("   Due:      %s\n", task.DueDay(rules).Format("Mon Jan 2, 2006"))
+		}
+		if len(task.Tags) > 0 {
+			fmt.Printf("   Tags:     %s\n", strings.Join(task.Tags, ", "))
+		}
+		fmt.Printf("   Sessions: %s\n", task.EstimateSummary())
+		fmt.Printf("   Time:     %d minutes\n", task.Minutes)
+
+		records, err := history.Load()
+		if err != nil {
+			fmt.Printf("Error loading sessions: %v\n", err)
+			return
+		}
+		var linked []history.Record
+		for _, record := range records {
+			if record.IsFocus() && record.TaskID == task.ID {
+				linked = append(linked, record)
+			}
+		}
+		if len(linked) == 0 {
+			fmt.Println()
+			return
+		}
+		fmt.Println("\n   Recent sessions:")
+		sort.Slice(linked, func(i, j int) bool { return linked[i].StartTime.Before(linked[j].StartTime) })
+		if len(linked) > 10 {
+			linked = linked[len(linked)-10:]
+		}
+		for _, record := range linked {
+			fmt.Printf("     %s  %3.0f min  %s\n", rules.In(record.StartTime).Format("Mon Jan 2 15:04"),
+				record.ActualMinutes(), record.Status)
+		}
+		fmt.Println()
+	},
+}
+
+var editTaskCmd = &cobra.Command{
+	Use:   "edit [task]",
+	Short: "Change a task",
+	Long: `Change a task. Only the fields given as flags are changed; an empty value
+clears a field, e.g. --due "" or --priority none.
+
+Examples:
+  pom plan edit 3 --title "Ship v2.1" --estimate 6
+  pom plan edit 3 --due tomorrow --priority high
+  pom plan edit 3 --tags backend,api --project ""`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		flags := cmd.Flags()
+		changed := false
+		for _, flag := range []string{"title", "description", "tags", "estimate", "priority", "due", "project"} {
+			changed = changed || flags.Changed(flag)
+		}
+		if !changed {
+			fmt.Println("Nothing to change. Use --title, --description, --tags, --estimate, --priority, --due or --project.")
+			return
+		}
+
+		// Parse everything first, so a bad value changes nothing
+		priority, _ := flags.GetString("priority")
+		priority, err := config.ParsePriority(priority)
+		if err != nil {
+			fmt.Printf("Error updating task: %v\n", err)
+			return
+		}
+		due, _ := flags.GetString("due")
+		dueDate, err := config.ParseDueDate(due, clock.Load(), time.Now())
+		if err != nil {
+			fmt.Printf("Error updating task: %v\n", err)
+			return
+		}
+
+		task, err := config.UpdateTask(args[0], func(task *config.Task) {
+			if flags.Changed("title") {
+				task.Title, _ = flags.GetString("title")
+			}
+			if flags.Changed("description") {
+				task.Description, _ = flags.GetString("description")
+			}
+			if flags.Changed("tags") {
+				task.Tags, _ = flags.GetStringSlice("tags")
+			}
+			if flags.Changed("estimate") {
+				task.Estimate, _ = flags.GetInt("estimate")
+			}
+			if flags.Changed("priority") {
+				task.Priority = priority
+			}
+			if flags.Changed("due") {
+				task.Due = dueDate
+			}
+			if flags.Changed("project") {
+				task.Project, _ = flags.GetString("project")
+			}
+		})
+		if err != nil {
+			fmt.Printf("Error updating task: %v\n", err)
+			return
+		}
+		fmt.Printf("✅ Updated task #%d %s\n", task.Number, task.Title)
+	},
+}
+
+var deleteTaskCmd = &cobra.Command{
+	Use:   "delete [task]",
+	Short: "Delete a task",
+	Long: `Delete a task. Sessions linked to it stay in the session log; to keep the
+task in reports but out of lists, archive it instead.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		task, err := config.DeleteTask(args[0])
+		if err != nil {
+			fmt.Printf("Error deleting task: %v\n", err)
+			return
+		}
+		fmt.Printf("🗑️  Deleted task #%d %s\n", task.Number, task.Title)
+	},
+}
+
 var completeTaskCmd = &cobra.Command{
-	Use:   "complete [task-id]",
+	Use:   "complete [task]",
 	Short: "Mark a task as completed",
 	Args:  cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		taskID := args[0]
-		if err := config.CompleteTask(taskID); err != nil {
+		task, err := config.CompleteTask(args[0])
+		if err != nil {
 			fmt.Printf("Error completing task: %v\n", err)
 			return
 		}
 
-		fmt.Printf("Task %s marked as completed\n", taskID)
+		fmt.Printf("Task #%d %s marked as completed\n", task.Number, task.Title)
+		printUnlocks(daemon.AnnounceAchievements())
+	},
+}
+
+var reopenTaskCmd = &cobra.Command{
+	Use:   "reopen [task]",
+	Short: "Mark a completed or archived task as open again",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		task, err := config.ReopenTask(args[0])
+		if err != nil {
+			fmt.Printf("Error reopening task: %v\n", err)
+			return
+		}
+		fmt.Printf("Task #%d %s reopened\n", task.Number, task.Title)
+	},
+}
+
+var archiveTaskCmd = &cobra.Command{
+	Use:   "archive [task...]",
+	Short: "Hide tasks from lists, keeping their history",
+	Long: `Archive tasks to hide them from 'pom plan list'. Their sessions still count
+in insights and reports; list them with --archived or the 'archived' query
+term, and bring one back with 'pom plan reopen'.
+
+Examples:
+  pom plan archive 3 7
+  pom plan archive --done     Archive every completed task`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if done, _ := cmd.Flags().GetBool("done"); done {
+			archived, err := config.ArchiveCompletedTasks()
+			if err != nil {
+				fmt.Printf("Error archiving tasks: %v\n", err)
+				return
+			}
+			fmt.Printf("📦 Archived %d completed tasks\n", archived)
+			return
+		}
+		if len(args) == 0 {
+			fmt.Println("Name the tasks to archive, or use --done to archive every completed task.")
+			return
+		}
+		for _, ref := range args {
+			task, err := config.ArchiveTask(ref)
+			if err != nil {
+				fmt.Printf("Error archiving task: %v\n", err)
+				continue
+			}
+			fmt.Printf("📦 Archived task #%d %s\n", task.Number, task.Title)
+		}
 	},
 }
 
 func init() {
 	addTaskCmd.Flags().String("description", "", "Task description")
 	addTaskCmd.Flags().StringSlice("tags", []string{}, "Task tags (comma-separated)")
+	addTaskCmd.Flags().IntP("estimate", "e", 0, "Estimated pomodoros")
+	addTaskCmd.Flags().StringP("priority", "p", "", "Priority: high, medium or low")
+	addTaskCmd.Flags().String("due", "", "Due date: YYYY-MM-DD, today, tomorrow or a weekday")
+	addTaskCmd.Flags().String("project", "", "Project the task belongs to")
 
 	listTasksCmd.Flags().Bool("all", false, "Show completed tasks")
+	listTasksCmd.Flags().Bool("archived", false, "Show archived tasks")
+	listTasksCmd.Flags().String("project", "", "Only tasks in this project")
+	listTasksCmd.Flags().String("tag", "", "Only tasks with this tag")
+	listTasksCmd.Flags().StringP("priority", "p", "", "Only tasks with this priority")
+	listTasksCmd.Flags().String("due", "", "Only tasks due on or before this date")
+	listTasksCmd.Flags().Bool("overdue", false, "Only open tasks past their due date")
+	listTasksCmd.Flags().StringP("sort", "s", "created", "Sort by "+strings.Join(config.TaskSortKeys, ", "))
+
+	editTaskCmd.Flags().String("title", "", "Task title")
+	editTaskCmd.Flags().String("description", "", "Task description")
+	editTaskCmd.Flags().StringSlice("tags", []string{}, "Task tags (comma-separated), replacing the current ones")
+	editTaskCmd.Flags().IntP("estimate", "e", 0, "Estimated pomodoros, 0 to clear")
+	editTaskCmd.Flags().StringP("priority", "p", "", "Priority: high, medium, low or none")
+	editTaskCmd.Flags().String("due", "", "Due date: YYYY-MM-DD, today, tomorrow, a weekday or none")
+	editTaskCmd.Flags().String("project", "", "Project the task belongs to")
+
+	archiveTaskCmd.Flags().Bool("done", false, "Archive every completed task")
 
 	planCmd.AddCommand(addTaskCmd)
 	planCmd.AddCommand(listTasksCmd)
+	planCmd.AddCommand(showTaskCmd)
+	planCmd.AddCommand(editTaskCmd)
+	planCmd.AddCommand(deleteTaskCmd)
 	planCmd.AddCommand(completeTaskCmd)
+	planCmd.AddCommand(reopenTaskCmd)
+	planCmd.AddCommand(archiveTaskCmd)
 	rootCmd.AddCommand(planCmd)
 }
@@ -15,9 +15,13 @@ var pluginsCmd = &cobra.Command{
 Run custom scripts at different points in your Pomodoro sessions:
   • Session start/end hooks
   • Break start/end hooks
+  • Achievement hooks ($ACHIEVEMENT_ID, $ACHIEVEMENT_NAME)
   • Integration with external tools
   • Custom notifications
 
+Session values like $DURATION are inserted quoted, so they are only ever
+text. They are also set as POM_DURATION and so on.
+
 Examples:
   pom plugins list              List all plugins
   pom plugins enable notion-logger  Enable a plugin
@@ -35,7 +39,7 @@ var listPluginsCmd = &cobra.Command{
 			return
 		}
 
-		fmt.Println("🧩 Available Plugins:\n")
+		fmt.Print("🧩 Available Plugins:\n\n")
 		for _, plugin := range plugins.Plugins {
 			status := "❌ Disabled"
 			if plugin.Enabled {
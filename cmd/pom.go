@@ -8,12 +8,13 @@ import (
 	"os/signal"
 	"strings"
 	"syscall"
-	"time"
 
 	"golang.org/x/term"
 
 	"github.com/Flack74/pom/config"
-	"github.com/Flack74/pom/logs"
+	"github.com/Flack74/pom/daemon"
+	"github.com/Flack74/pom/history"
+	"github.com/Flack74/pom/timer"
 )
 
 // ANSI color codes
@@ -26,61 +27,56 @@ const (
 	colorPurple = "\033[35m"
 )
 
-// Timer states
+// How an attached terminal let go of the timer
 const (
-	stateRunning = iota
-	statePaused
-	stateQuitting
+	attachFinished = iota // The run completed all of its sessions
+	attachStopped         // The run was stopped early
+	attachDetached        // The terminal detached; the run goes on in the daemon
 )
 
-// handleUserInput handles keyboard input for pause/resume/quit
-func handleUserInput(timerState *int, pauseChan chan struct{}, resumeChan chan struct{}) {
+// readControls forwards keyboard input to keys until stdin is closed
+func readControls(keys chan<- byte) {
 	reader := bufio.NewReader(os.Stdin)
-	fmt.Printf("\n%s⌨️  Controls: [p]ause | [r]esume | [q]uit%s\n", colorBlue, colorReset)
-
 	for {
 		char, err := reader.ReadByte()
 		if err != nil {
-			continue
-		}
-
-		switch char {
-		case 'p', 'P':
-			if *timerState == stateRunning {
-				*timerState = statePaused
-				pauseChan <- struct{}{}
-				fmt.Printf("\n%s⏸️  Timer paused. Press 'r' to resume.%s\n", colorYellow, colorReset)
-			}
-		case 'r', 'R':
-			if *timerState == statePaused {
-				*timerState = stateRunning
-				resumeChan <- struct{}{}
-				fmt.Printf("\n%s▶️  Timer resumed.%s\n", colorGreen, colorReset)
-			}
-		case 'q', 'Q':
-			if *timerState != stateQuitting {
-				*timerState = stateQuitting
-				fmt.Printf("\n%s⏹️  Quitting...%s\n", colorRed, colorReset)
-				return
-			}
+			return
 		}
+		keys <- char
 	}
 }
 
-// countdown displays a live countdown timer with progress bar
-func countdown(duration time.Duration, label string, color string, timerState *int, pauseChan, resumeChan chan struct{}) bool {
-	startTime := time.Now()
-	endTime := startTime.Add(duration)
-	var pausedDuration time.Duration
+// attach shows the daemon's timer with a live progress bar and forwards
+// pause/resume/skip/quit keys to it. With stopOnInterrupt, Ctrl+C stops the
+// run; otherwise it only detaches this terminal.
+func attach(theme config.Theme, stopOnInterrupt bool) int {
+	control, err := daemon.Connect()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s⚠️  %v%s\n", theme.WarningColor, err, theme.TextColor)
+		return attachDetached
+	}
+	defer control.Close()
+
+	watcher, err := daemon.Dial()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s⚠️  %v%s\n", theme.WarningColor, err, theme.TextColor)
+		return attachDetached
+	}
+	defer watcher.Close()
+
+	updates, err := watcher.Watch()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s⚠️  %v%s\n", theme.WarningColor, err, theme.TextColor)
+		return attachDetached
+	}
 
-	// Set up signal handling
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 	defer signal.Stop(sigChan)
 
-	// Create a ticker for the countdown
-	ticker := time.NewTicker(1 * time.Second)
-	defer ticker.Stop()
+	keys := make(chan byte)
+	go readControls(keys)
+	fmt.Printf("\n%s⌨️  Controls: [p]ause | [r]esume | [s]kip | [q]uit | [d]etach%s\n", colorBlue, colorReset)
 
 	// Get terminal width for progress bar
 	width := 40 // default width
@@ -88,184 +84,221 @@ func countdown(duration time.Duration, label string, color string, timerState *i
 		width = w - 20 // leave room for timer and label
 	}
 
+	var last timer.Snapshot
 	for {
 		select {
-		case <-sigChan:
-			fmt.Printf("\n%s⚠️  Session interrupted!%s\n", colorRed, colorReset)
-			return false
-		case <-pauseChan:
-			pauseStart := time.Now()
-			<-resumeChan
-			pausedDuration += time.Since(pauseStart)
-			endTime = endTime.Add(time.Since(pauseStart))
-		case <-ticker.C:
-			if *timerState == stateQuitting {
-				return false
-			}
-			if *timerState == statePaused {
-				continue
-			}
-			if time.Now().After(endTime) {
-				return true
+		case snap, ok := <-updates:
+			if !ok {
+				fmt.Printf("\n%s⚠️  Lost connection to the pom daemon%s\n", theme.WarningColor, theme.TextColor)
+				return attachDetached
 			}
 
-			remaining := time.Until(endTime).Round(time.Second)
-			elapsed := duration - remaining
-			progress := float64(elapsed) / float64(duration)
-			
-			// Ensure progress doesn't exceed 100%
-			if progress > 1.0 {
-				progress = 1.0
+			switch snap.Event {
+			case "":
+				if !snap.Active {
+					fmt.Println("No session is running. Start one with: pom start")
+					return attachFinished
+				}
+				if snap.Recovered {
+					fmt.Printf("%s⏸️  Unfinished session recovered. Press 'r' to resume or 'q' to log it as abandoned.%s\n", colorYellow, colorReset)
+				}
+				printPhaseHeader(snap, theme)
+			case timer.EventStarted:
+				printPhaseHeader(snap, theme)
+			case timer.EventPhase, timer.EventSkipped:
+				if snap.Phase == history.KindBreak && (snap.Event == timer.EventPhase || last.Open && last.Elapsed >= int(timer.MinOpenFocus.Seconds())) {
+					fmt.Printf("\n%s%s%s\n", theme.SuccessColor, getRandomMotivationalMessage(), theme.TextColor)
+					if next := nextAchievement(); next != "" {
+						fmt.Println(next)
+					}
+				}
+				fmt.Println()
+				printPhaseHeader(snap, theme)
+			case timer.EventPaused:
+				fmt.Printf("\n%s⏸️  Timer paused. Press 'r' to resume.%s\n", colorYellow, colorReset)
+			case timer.EventResumed:
+				fmt.Printf("\n%s▶️  Timer resumed.%s\n", colorGreen, colorReset)
+			case timer.EventFinished:
+				fmt.Println()
+				printSummary(snap, theme)
+				return attachFinished
+			case timer.EventStopped:
+				// Open focus is logged as completed unless it was too short to count
+				if snap.Open && snap.Elapsed >= int(timer.MinOpenFocus.Seconds()) {
+					fmt.Printf("\n%s✅ Focus logged: %02d:%02d%s\n", theme.SuccessColor, snap.Elapsed/60, snap.Elapsed%60, theme.TextColor)
+					return attachFinished
+				}
+				fmt.Printf("\n%s⚠️  Session interrupted!%s\n", colorRed, colorReset)
+				return attachStopped
 			}
 
-			// Calculate progress bar with proper rounding
-			barWidth := int(float64(width)*progress + 0.5)
-			bar := strings.Repeat("█", barWidth) + strings.Repeat("░", width-barWidth)
-
-			// Format time remaining
-			minutes := int(remaining.Minutes())
-			seconds := int(remaining.Seconds()) % 60
-
-			// Clear line and print progress
-			fmt.Printf("\r%s%s %02d:%02d [%s] %.0f%%%s",
-				color, label, minutes, seconds, bar, progress*100, colorReset)
+			last = snap
+			if snap.Active && !snap.Paused {
+				renderProgress(snap, theme, width)
+			}
+		case key := <-keys:
+			method := ""
+			switch key {
+			case 'p', 'P':
+				method = daemon.MethodPause
+			case 'r', 'R':
+				method = daemon.MethodResume
+			case 's', 'S':
+				method = daemon.MethodSkip
+			case 'q', 'Q':
+				fmt.Printf("\n%s⏹️  Quitting...%s\n", colorRed, colorReset)
+				method = daemon.MethodStop
+			case 'd', 'D':
+				printDetached(last)
+				return attachDetached
+			}
+			if method != "" {
+				if _, err := control.Call(method, nil); err != nil {
+					fmt.Fprintf(os.Stderr, "\n%s⚠️  %v%s\n", theme.WarningColor, err, theme.TextColor)
+				}
+			}
+		case <-sigChan:
+			if !stopOnInterrupt {
+				printDetached(last)
+				return attachDetached
+			}
+			if _, err := control.Call(daemon.MethodStop, nil); err != nil {
+				fmt.Fprintf(os.Stderr, "\n%s⚠️  %v%s\n", theme.WarningColor, err, theme.TextColor)
+				return attachDetached
+			}
 		}
 	}
 }
 
-// StartPomodoro starts a pomodoro session with the given parameters
-func StartPomodoro(workMin, breakMin, numberOfSess int, taskID string) bool {
-	// Load theme
-	theme, err := config.LoadTheme()
-	if err != nil {
-		theme = config.DefaultTheme
-	}
-
-	// Print session info
-	fmt.Printf("\n%s🎯 Starting Pomodoro Timer%s\n", theme.HighlightColor, theme.TextColor)
-	fmt.Printf("%s📚 Work: %d min | Break: %d min | Sessions: %d%s\n\n",
-		theme.TextColor, workMin, breakMin, numberOfSess, theme.TextColor)
-
-	// If a task ID is provided, verify it exists
-	if taskID != "" {
-		tasks, err := config.LoadTasks()
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "%s⚠️  Error loading tasks: %v%s\n", theme.WarningColor, err, theme.TextColor)
-			return false
-		}
-
-		taskFound := false
-		for _, task := range tasks.Tasks {
-			if task.ID == taskID {
-				taskFound = true
-				fmt.Printf("%s📎 Linked to task: %s%s\n\n", theme.HighlightColor, task.Title, theme.TextColor)
-				break
-			}
-		}
-
-		if !taskFound {
-			fmt.Fprintf(os.Stderr, "%s⚠️  Task with ID %s not found%s\n", theme.WarningColor, taskID, theme.TextColor)
-			return false
+// printPhaseHeader announces the phase a snapshot is in
+func printPhaseHeader(snap timer.Snapshot, theme config.Theme) {
+	name := snap.Interval
+	if snap.Phase == history.KindBreak {
+		if name == "" || name == timer.NameBreak {
+			name = "Break Time"
 		}
+		fmt.Printf("%s☕ %s (%d min)%s\n", theme.HighlightColor, name, snap.Duration/60, theme.TextColor)
+		return
 	}
+	if name == "" || name == timer.NameFocus {
+		name = "Focus Time"
+	}
+	if snap.Open {
+		name += " (counting up, press 's' for your break)"
+	}
+	fmt.Printf("%s📚 Session %d/%d - %s%s\n", theme.HighlightColor, snap.Session, snap.Sessions, name, theme.TextColor)
+}
 
-	// Set up channels for pause/resume functionality
-	pauseChan := make(chan struct{})
-	resumeChan := make(chan struct{})
-	timerState := stateRunning
-
-	// Start user input handler
-	go handleUserInput(&timerState, pauseChan, resumeChan)
-
-	// Track total work time
-	totalWorkTime := time.Duration(0)
-	startTime := time.Now()
-
-	// Run sessions
-	for sess := 1; sess <= numberOfSess; sess++ {
-		work := time.Duration(workMin) * time.Minute
-		breakTime := time.Duration(breakMin) * time.Minute
-
-		// Work period
-		fmt.Printf("%s📚 Session %d/%d - Focus Time%s\n", theme.HighlightColor, sess, numberOfSess, theme.TextColor)
-		if !countdown(work, "Focus", theme.TimerColor, &timerState, pauseChan, resumeChan) {
-			return false
-		}
-		totalWorkTime += work
-
-		// Show motivational message
-		message := getRandomMotivationalMessage()
-		fmt.Printf("\n%s%s%s\n", theme.SuccessColor, message, theme.TextColor)
+// renderProgress redraws the countdown line for a snapshot
+func renderProgress(snap timer.Snapshot, theme config.Theme, width int) {
+	label, color := "Focus", theme.TimerColor
+	if snap.Phase == history.KindBreak {
+		label, color = "Break", theme.ProgressColor
+	}
+	if snap.Interval != "" {
+		label = snap.Interval
+	}
+	if snap.Open {
+		fmt.Printf("\r%s%s %02d:%02d ⏱️  counting up%s", color, label, snap.Elapsed/60, snap.Elapsed%60, colorReset)
+		return
+	}
 
-		// Play sound and show notification
-		if err := logs.PlaySound("work_end"); err != nil {
-			fmt.Fprintf(os.Stderr, "%s⚠️  Error playing sound: %v%s\n", theme.WarningColor, err, theme.TextColor)
-		}
-		if err := logs.ShowNotification("Work session complete!", "Time for a break!"); err != nil {
-			fmt.Fprintf(os.Stderr, "%s⚠️  Error showing notification: %v%s\n", theme.WarningColor, err, theme.TextColor)
-		}
+	progress := 1.0
+	if snap.Duration > 0 {
+		progress = float64(snap.Duration-snap.Remaining) / float64(snap.Duration)
+	}
 
-		// Break period (skip after last session)
-		if sess < numberOfSess {
-			// Execute break start plugins
-			breakData := map[string]string{
-				"DURATION": fmt.Sprintf("%d", breakMin),
-				"SESSION": fmt.Sprintf("%d", sess),
-				"DATE": time.Now().Format("2006-01-02T15:04:05Z"),
-			}
-			config.ExecutePlugins("break_start", breakData)
+	// Ensure progress stays within 0-100%
+	if progress > 1.0 {
+		progress = 1.0
+	}
+	if progress < 0 {
+		progress = 0
+	}
 
-			fmt.Printf("\n%s☕ Break Time%s\n", theme.HighlightColor, theme.TextColor)
-			if !countdown(breakTime, "Break", theme.ProgressColor, &timerState, pauseChan, resumeChan) {
-				return false
-			}
+	// Calculate progress bar with proper rounding
+	barWidth := int(float64(width)*progress + 0.5)
+	bar := strings.Repeat("█", barWidth) + strings.Repeat("░", width-barWidth)
 
-			// Execute break end plugins
-			config.ExecutePlugins("break_end", breakData)
+	// Clear line and print progress
+	fmt.Printf("\r%s%s %02d:%02d [%s] %.0f%%%s",
+		color, label, snap.Remaining/60, snap.Remaining%60, bar, progress*100, colorReset)
+}
 
-			// Play sound and show notification
-			if err := logs.PlaySound("break_end"); err != nil {
-				fmt.Fprintf(os.Stderr, "%s⚠️  Error playing sound: %v%s\n", theme.WarningColor, err, theme.TextColor)
+// printSummary shows the completion message for a finished run
+func printSummary(snap timer.Snapshot, theme config.Theme) {
+	focusSeconds := 0
+	if records, err := history.Load(); err == nil {
+		for _, record := range records {
+			if record.RunID == snap.RunID && record.IsFocus() {
+				focusSeconds += record.ActualSeconds
 			}
-			if err := logs.ShowNotification("Break complete!", "Time to focus!"); err != nil {
-				fmt.Fprintf(os.Stderr, "%s⚠️  Error showing notification: %v%s\n", theme.WarningColor, err, theme.TextColor)
-			}
-			fmt.Println()
 		}
 	}
 
-	// Log the session
-	endTime := time.Now()
-	if err := logs.LogSession(workMin, breakMin, numberOfSess, startTime, endTime, true); err != nil {
-		fmt.Fprintf(os.Stderr, "%s⚠️  Failed to log session: %v%s\n", theme.WarningColor, err, theme.TextColor)
-	}
+	fmt.Printf("\n%s🎉 Pomodoro complete! Great job!%s\n", theme.SuccessColor, theme.TextColor)
+	fmt.Printf("%s📊 Sessions completed: %d%s\n", theme.HighlightColor, snap.Sessions, theme.TextColor)
+	fmt.Printf("%s⏰ Total focus time: %.0f minutes%s\n", theme.HighlightColor, float64(focusSeconds)/60, theme.TextColor)
+}
 
-	// Update task progress if a task is linked
-	if taskID != "" {
-		if err := config.UpdateTaskProgress(taskID, numberOfSess, workMin*numberOfSess); err != nil {
-			fmt.Fprintf(os.Stderr, "%s⚠️  Failed to update task progress: %v%s\n", theme.WarningColor, err, theme.TextColor)
-		}
+// printDetached explains that the timer keeps running without this terminal
+func printDetached(last timer.Snapshot) {
+	fmt.Println()
+	if last.Active {
+		fmt.Println("👋 Detached. The timer keeps running in the background.")
+		fmt.Println("   Reattach with 'pom status --watch', or use 'pom pause' / 'pom stop'")
 	}
+}
 
-	// Update goals progress
-	if err := config.UpdateProgress(numberOfSess, workMin*numberOfSess); err != nil {
-		fmt.Fprintf(os.Stderr, "%s⚠️  Failed to update goals progress: %v%s\n", theme.WarningColor, err, theme.TextColor)
+// printStatus prints a one-off summary of a snapshot
+func printStatus(snap timer.Snapshot) {
+	if !snap.Active {
+		fmt.Println("💤 No session is running. Start one with: pom start")
+		return
 	}
 
-	// Show completion message and summary
-	fmt.Printf("\n%s🎉 Pomodoro complete! Great job!%s\n", theme.SuccessColor, theme.TextColor)
-	fmt.Printf("%s📊 Sessions completed: %d%s\n", theme.HighlightColor, numberOfSess, theme.TextColor)
-	fmt.Printf("%s⏰ Total focus time: %.0f minutes%s\n", theme.HighlightColor, totalWorkTime.Minutes(), theme.TextColor)
-
-	// Final notification
-	if err := logs.ShowNotification("Pomodoro Complete!", "Great job on completing all your sessions!"); err != nil {
-		fmt.Fprintf(os.Stderr, "%s⚠️  Error showing notification: %v%s\n", theme.WarningColor, err, theme.TextColor)
+	phase := "Focus"
+	if snap.Phase == history.KindBreak {
+		phase = "Break"
+	}
+	if snap.Interval != "" {
+		phase = snap.Interval
 	}
-	if err := logs.PlaySound("work_end"); err != nil {
-		fmt.Fprintf(os.Stderr, "%s⚠️  Error playing sound: %v%s\n", theme.WarningColor, err, theme.TextColor)
+	state := "running"
+	switch {
+	case snap.Recovered:
+		state = "recovered, waiting for 'pom resume' or 'pom stop'"
+	case snap.Paused:
+		state = "paused"
 	}
 
-	return true
+	if snap.Open {
+		fmt.Printf("⏱️  %s %d/%d: %02d:%02d so far (%s)\n",
+			phase, snap.Session, snap.Sessions, snap.Elapsed/60, snap.Elapsed%60, state)
+	} else {
+		fmt.Printf("⏱️  %s %d/%d: %02d:%02d left (%s)\n",
+			phase, snap.Session, snap.Sessions, snap.Remaining/60, snap.Remaining%60, state)
+	}
+	if snap.WorkMinutes > 0 {
+		fmt.Printf("   Work: %d min | Break: %d min", snap.WorkMinutes, snap.BreakMinutes)
+	} else {
+		fmt.Printf("   Flow mode")
+	}
+	if snap.Profile != "" {
+		fmt.Printf(" | Profile: %s", snap.Profile)
+	}
+	if snap.TaskID != "" {
+		if task, err := config.GetTask(snap.TaskID); err == nil {
+			fmt.Printf(" | Task: #%d %s", task.Number, task.Title)
+		} else {
+			fmt.Printf(" | Task: %s", snap.TaskID)
+		}
+	}
+	fmt.Println()
+	if snap.Step+1 < len(snap.Intervals) {
+		next := snap.Intervals[snap.Step+1]
+		fmt.Printf("   Next: %s (%s), interval %d of %d\n", next.Label(), next.Length(), snap.Step+2, len(snap.Intervals))
+	}
 }
 
 // getRandomMotivationalMessage returns a random motivational message
@@ -285,13 +318,16 @@ func getRandomMotivationalMessage() string {
 	return messages[rand.Intn(len(messages))]
 }
 
-// SaveConfig saves the current configuration
+// SaveConfig saves the work, break and session counts as the defaults,
+// keeping the rest of the configuration
 func SaveConfig(workMin, breakMin, numberOfSess int) error {
-	cfg := config.Config{
-		WorkMinutes:  workMin,
-		BreakMinutes: breakMin,
-		NumSessions:  numberOfSess,
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %v", err)
 	}
+	cfg.WorkMinutes = workMin
+	cfg.BreakMinutes = breakMin
+	cfg.NumSessions = numberOfSess
 	if err := config.SaveConfig(cfg); err != nil {
 		return fmt.Errorf("failed to save config: %v", err)
 	}
@@ -47,6 +47,7 @@ var enablePrivacyCmd = &cobra.Command{
 		fmt.Println("   • Session data will not be logged")
 		fmt.Println("   • Statistics will be disabled")
 		fmt.Println("   • Cloud sync has been disabled")
+		fmt.Println("   • Coaching uses the built-in rules, nothing is sent")
 		fmt.Println("   • Only current session data is kept in memory")
 	},
 }
@@ -125,11 +126,15 @@ var privacyStatusCmd = &cobra.Command{
 			fmt.Println("   • No session data is being logged")
 			fmt.Println("   • Statistics and insights are disabled")
 			fmt.Println("   • Cloud sync is disabled")
+			fmt.Println("   • Coaching uses the built-in rules only")
 		} else {
 			fmt.Println("   Status: 📊 Normal mode (data logging enabled)")
 			fmt.Println("   • Session data is being logged for statistics")
 			fmt.Println("   • Insights and calendar view available")
 			fmt.Printf("   • Cloud sync: %t\n", cfg.CloudSync)
+			if cfg.Coach.Endpoint != "" {
+				fmt.Printf("   • Coaching summaries are sent to %s\n", cfg.Coach.Endpoint)
+			}
 		}
 
 		// Show data usage estimate
@@ -1,10 +1,16 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"os"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/Flack74/pom/clock"
 	"github.com/Flack74/pom/config"
+	"github.com/Flack74/pom/timer"
 	"github.com/spf13/cobra"
 )
 
@@ -19,11 +25,36 @@ Create and manage different profiles for various work contexts:
   • Quick profile: Short bursts for small tasks
   • Custom profiles: Tailored to your needs
 
+Profile names may contain letters, digits, '.', '_' and '-'. Work time is
+1-180 minutes, break time 1-60 minutes and a profile runs 1-12 sessions.
+
+A profile can take a long break after every few sessions, or run an
+explicit sequence of up to 24 named intervals written as name:kind:minutes,
+where kind is focus or break. Interval names may contain letters, digits,
+spaces, '_' and '-'.
+
+An adaptive profile picks its work and break lengths before each run from
+your recent completion rate, pauses and time of day, within bounds you
+set. 'pom profile explain' shows why each length was chosen.
+
 Examples:
-  pom profile list                    List all profiles
-  pom profile use work               Switch to work profile
-  pom profile create "coding" 45 10 3  Create custom profile
-  pom profile delete "old-profile"   Remove a profile`,
+  pom profile list                     List all profiles
+  pom profile show work                Show one profile
+  pom profile use work                 Switch to work profile
+  pom profile create coding 45 10 3    Create custom profile
+  pom profile create classic 25 5 8 --long-break 15 --long-every 4
+                                       Long break after every 4th session
+  pom profile create rhythm --intervals "Deep work:focus:90,Walk:break:20,Deep work:focus:90"
+                                       Explicit interval sequence
+  pom profile create tuned 25 5 4 --adaptive --max-work 50
+                                       Adaptive lengths, at most 50 min
+  pom profile explain adaptive         Why the adaptive lengths were chosen
+  pom profile edit coding --work 50    Change a profile
+  pom profile clone work deep-work     Copy a profile
+  pom profile delete old-profile       Remove a profile
+  pom profile export deep-work -o deep-work.json
+                                       Save a profile to share it
+  pom profile import deep-work.json    Add profiles from a file`,
 }
 
 var listProfilesCmd = &cobra.Command{
@@ -46,8 +77,7 @@ var listProfilesCmd = &cobra.Command{
 			}
 			fmt.Printf("  %s%s\n", profile.Name, current)
 			fmt.Printf("    %s\n", profile.Description)
-			fmt.Printf("    Work: %dm, Break: %dm, Sessions: %d\n\n", 
-				profile.WorkMinutes, profile.BreakMinutes, profile.NumSessions)
+			fmt.Printf("    %s\n\n", profileSummary(profile))
 		}
 	},
 }
@@ -62,7 +92,7 @@ var useProfileCmd = &cobra.Command{
 		// Check if profile exists
 		_, err := config.GetProfile(profileName)
 		if err != nil {
-			fmt.Printf("Profile '%s' not found\n", profileName)
+			fmt.Printf("Error: %v\n", err)
 			return
 		}
 
@@ -86,9 +116,41 @@ var useProfileCmd = &cobra.Command{
 var createProfileCmd = &cobra.Command{
 	Use:   "create [name] [work-minutes] [break-minutes] [sessions]",
 	Short: "Create a new profile",
-	Args:  cobra.ExactArgs(4),
+	Args:  cobra.RangeArgs(1, 4),
 	Run: func(cmd *cobra.Command, args []string) {
 		name := args[0]
+		description, _ := cmd.Flags().GetString("description")
+
+		if spec, _ := cmd.Flags().GetString("intervals"); spec != "" {
+			if len(args) != 1 {
+				fmt.Println("Give either --intervals or work, break and session counts, not both")
+				return
+			}
+			if adaptive, _ := cmd.Flags().GetBool("adaptive"); adaptive || adaptiveBoundsChanged(cmd) {
+				fmt.Println("Adaptive profiles use work and break lengths, not --intervals")
+				return
+			}
+			intervals, err := timer.ParsePlan(spec)
+			if err != nil {
+				fmt.Printf("Invalid intervals: %v\n", err)
+				return
+			}
+			if description == "" {
+				description = "Custom profile: " + timer.SummarizePlan(intervals)
+			}
+			profile := config.Profile{Name: name, Description: description, Intervals: intervals}
+			if err := config.AddProfile(profile); err != nil {
+				fmt.Printf("Error creating profile: %v\n", err)
+				return
+			}
+			fmt.Printf("✅ Created profile: %s\n", name)
+			return
+		}
+		if len(args) != 4 {
+			fmt.Println("Usage: pom profile create [name] [work-minutes] [break-minutes] [sessions]")
+			return
+		}
+
 		workMin, err := strconv.Atoi(args[1])
 		if err != nil {
 			fmt.Printf("Invalid work minutes: %s\n", args[1])
@@ -105,7 +167,6 @@ var createProfileCmd = &cobra.Command{
 			return
 		}
 
-		description, _ := cmd.Flags().GetString("description")
 		if description == "" {
 			description = fmt.Sprintf("Custom profile: %dm work, %dm break", workMin, breakMin)
 		}
@@ -117,6 +178,13 @@ var createProfileCmd = &cobra.Command{
 			NumSessions:  sessions,
 			Description:  description,
 		}
+		profile.LongBreakMinutes, _ = cmd.Flags().GetInt("long-break")
+		if profile.LongBreakMinutes > 0 {
+			profile.LongBreakEvery, _ = cmd.Flags().GetInt("long-every")
+		}
+		if adaptive, _ := cmd.Flags().GetBool("adaptive"); adaptive || adaptiveBoundsChanged(cmd) {
+			profile.Adaptive = applyAdaptiveFlags(cmd, config.Adaptive{})
+		}
 
 		if err := config.AddProfile(profile); err != nil {
 			fmt.Printf("Error creating profile: %v\n", err)
@@ -127,11 +195,402 @@ var createProfileCmd = &cobra.Command{
 	},
 }
 
+var showProfileCmd = &cobra.Command{
+	Use:   "show [profile-name]",
+	Short: "Show a profile (the current one by default)",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, _ := config.LoadConfig()
+		name := cfg.CurrentProfile
+		if len(args) == 1 {
+			name = args[0]
+		}
+
+		profile, err := config.GetProfile(name)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		current := ""
+		if profile.Name == cfg.CurrentProfile {
+			current = " (current)"
+		}
+		fmt.Printf("\n👤 %s%s\n", profile.Name, current)
+		if profile.Description != "" {
+			fmt.Printf("   %s\n", profile.Description)
+		}
+		plan := profile.Plan()
+		if len(profile.Intervals) == 0 {
+			fmt.Printf("   Work:     %d min\n", profile.WorkMinutes)
+			fmt.Printf("   Break:    %d min\n", profile.BreakMinutes)
+			if profile.LongBreakMinutes > 0 {
+				fmt.Printf("   Long:     %d min after every %d sessions\n", profile.LongBreakMinutes, profile.LongBreakEvery)
+			}
+			fmt.Printf("   Sessions: %d\n", profile.NumSessions)
+		}
+		if profile.Adaptive != nil {
+			bounds := profile.Adaptive.WithDefaults()
+			fmt.Printf("   Adaptive: work %d-%d min, break %d-%d min, last %d days\n",
+				bounds.MinWork, bounds.MaxWork, bounds.MinBreak, bounds.MaxBreak, bounds.Window)
+		}
+		fmt.Println("   Sequence:")
+		for i, interval := range plan {
+			icon := "📚"
+			if !interval.IsFocus() {
+				icon = "☕"
+			}
+			fmt.Printf("     %2d. %s %-20s %3d min\n", i+1, icon, interval.Label(), interval.Minutes)
+		}
+		fmt.Printf("   Total:    %d min of focus\n\n", timer.FocusMinutes(plan))
+	},
+}
+
+var editProfileCmd = &cobra.Command{
+	Use:   "edit [profile-name]",
+	Short: "Change a profile's settings or name",
+	Long: `Change a profile. Only the settings given as flags are changed.
+
+Examples:
+  pom profile edit coding --work 50 --break 10
+  pom profile edit coding --name deep-work --description "Long focus blocks"
+  pom profile edit coding --adaptive --min-work 30
+  pom profile edit coding --adaptive=false`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+		profile, err := config.GetProfile(name)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		flags := cmd.Flags()
+		changed := false
+		for _, flag := range []string{"name", "work", "break", "sessions", "description", "long-break", "long-every", "intervals", "adaptive", "min-work", "max-work", "min-break", "max-break", "window"} {
+			changed = changed || flags.Changed(flag)
+		}
+		if !changed {
+			fmt.Println("Nothing to change. Use --name, --work, --break, --sessions, --long-break, --long-every, --intervals, --adaptive or --description.")
+			return
+		}
+		if flags.Changed("name") {
+			profile.Name, _ = flags.GetString("name")
+		}
+		if flags.Changed("work") {
+			profile.WorkMinutes, _ = flags.GetInt("work")
+		}
+		if flags.Changed("break") {
+			profile.BreakMinutes, _ = flags.GetInt("break")
+		}
+		if flags.Changed("sessions") {
+			profile.NumSessions, _ = flags.GetInt("sessions")
+		}
+		if flags.Changed("work") || flags.Changed("break") || flags.Changed("sessions") {
+			profile.Intervals = nil
+		}
+		if flags.Changed("intervals") {
+			spec, _ := flags.GetString("intervals")
+			profile.Intervals = nil
+			if spec != "" {
+				if profile.Intervals, err = timer.ParsePlan(spec); err != nil {
+					fmt.Printf("Invalid intervals: %v\n", err)
+					return
+				}
+			}
+		}
+		if flags.Changed("long-break") {
+			profile.LongBreakMinutes, _ = flags.GetInt("long-break")
+			if profile.LongBreakMinutes == 0 {
+				profile.LongBreakEvery = 0
+			} else if profile.LongBreakEvery == 0 {
+				profile.LongBreakEvery = 4
+			}
+		}
+		if flags.Changed("long-every") {
+			profile.LongBreakEvery, _ = flags.GetInt("long-every")
+		}
+		if (flags.Changed("long-break") || flags.Changed("long-every")) && len(profile.Intervals) > 0 {
+			fmt.Println("Long breaks apply to work/break profiles. Add --intervals \"\" to drop the interval sequence.")
+			return
+		}
+		if adaptive, _ := flags.GetBool("adaptive"); flags.Changed("adaptive") && !adaptive {
+			profile.Adaptive = nil
+		} else if adaptive || adaptiveBoundsChanged(cmd) {
+			bounds := config.Adaptive{}
+			if profile.Adaptive != nil {
+				bounds = *profile.Adaptive
+			}
+			profile.Adaptive = applyAdaptiveFlags(cmd, bounds)
+		}
+		if flags.Changed("description") {
+			profile.Description, _ = flags.GetString("description")
+		}
+
+		if err := config.UpdateProfile(name, profile); err != nil {
+			fmt.Printf("Error updating profile: %v\n", err)
+			return
+		}
+
+		if profile.Name != name {
+			fmt.Printf("✅ Renamed profile %s to %s\n", name, profile.Name)
+		}
+		fmt.Printf("✅ Updated profile: %s (%s)\n", profile.Name, profileSummary(profile))
+	},
+}
+
+var deleteProfileCmd = &cobra.Command{
+	Use:   "delete [profile-name]",
+	Short: "Delete a profile",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := config.DeleteProfile(args[0]); err != nil {
+			fmt.Printf("Error deleting profile: %v\n", err)
+			return
+		}
+		fmt.Printf("🗑️  Deleted profile: %s\n", args[0])
+	},
+}
+
+var cloneProfileCmd = &cobra.Command{
+	Use:   "clone [profile-name] [new-name]",
+	Short: "Copy a profile under a new name",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		profile, err := config.CloneProfile(args[0], args[1])
+		if err != nil {
+			fmt.Printf("Error cloning profile: %v\n", err)
+			return
+		}
+		fmt.Printf("✅ Created profile %s from %s\n", profile.Name, args[0])
+		fmt.Printf("💡 Adjust it with: pom profile edit %s --work 50\n", profile.Name)
+	},
+}
+
+var exportProfileCmd = &cobra.Command{
+	Use:   "export [profile-name...]",
+	Short: "Write profiles to a shareable JSON file",
+	Long: `Write profiles to a JSON file that others can add with 'pom profile import'.
+Without names every profile is exported. Without -o the file is printed.
+
+Examples:
+  pom profile export deep-work -o deep-work.json
+  pom profile export > all-profiles.json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		data, err := config.ExportProfiles(args...)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error exporting profiles: %v\n", err)
+			return
+		}
+
+		output, _ := cmd.Flags().GetString("output")
+		if output == "" {
+			fmt.Println(string(data))
+			return
+		}
+		if err := os.WriteFile(output, append(data, '\n'), 0644); err != nil {
+			fmt.Printf("Error writing %s: %v\n", output, err)
+			return
+		}
+		fmt.Printf("✅ Exported profiles to %s\n", output)
+	},
+}
+
+var importProfileCmd = &cobra.Command{
+	Use:   "import [file]",
+	Short: "Add profiles from a file made by 'pom profile export'",
+	Long: `Add the profiles in a file made by 'pom profile export'. A file holding
+a single profile object is accepted too. Profiles that already exist are only
+replaced with --force.
+
+Examples:
+  pom profile import deep-work.json
+  pom profile import team-profiles.json --force`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		data, err := os.ReadFile(args[0])
+		if err != nil {
+			fmt.Printf("Error reading %s: %v\n", args[0], err)
+			return
+		}
+
+		profiles, err := config.ParseProfileFile(data)
+		if err != nil {
+			fmt.Printf("Error importing profiles: %v\n", err)
+			return
+		}
+
+		force, _ := cmd.Flags().GetBool("force")
+		replaced, err := config.ImportProfiles(profiles, force)
+		if err != nil {
+			fmt.Printf("Error importing profiles: %v\n", err)
+			if errors.Is(err, config.ErrExists) {
+				fmt.Println("💡 Use --force to replace existing profiles")
+			}
+			return
+		}
+
+		for _, profile := range profiles {
+			fmt.Printf("✅ Imported profile: %s (%s)\n", profile.Name, profileSummary(profile))
+		}
+		if len(replaced) > 0 {
+			fmt.Printf("♻️  Replaced: %s\n", strings.Join(replaced, ", "))
+		}
+	},
+}
+
+var explainProfileCmd = &cobra.Command{
+	Use:   "explain [profile-name]",
+	Short: "Explain an adaptive profile's session lengths",
+	Long: `Show the lengths an adaptive profile would choose if a run started now,
+the signals behind them, and the log of lengths chosen for earlier runs.
+
+Examples:
+  pom profile explain              Explain the current profile
+  pom profile explain adaptive     Explain the adaptive profile`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, _ := config.LoadConfig()
+		name := cfg.CurrentProfile
+		if len(args) == 1 {
+			name = args[0]
+		}
+
+		profile, err := config.GetProfile(name)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		if profile.Adaptive == nil {
+			fmt.Printf("Profile '%s' is not adaptive. Turn it on with: pom profile edit %s --adaptive\n", profile.Name, profile.Name)
+			return
+		}
+
+		decision, err := config.DecideAdaptive(profile, time.Now())
+		if err != nil {
+			fmt.Printf("Error analyzing sessions: %v\n", err)
+			return
+		}
+		bounds := profile.Adaptive.WithDefaults()
+		fmt.Printf("\n🧠 %s: next run would use work %d min, break %d min\n", profile.Name, decision.WorkMinutes, decision.BreakMinutes)
+		fmt.Printf("   Base: work %d min, break %d min, kept for later runs\n", decision.BaseWork, decision.BaseBreak)
+		fmt.Printf("   Bounds: work %d-%d min, break %d-%d min, last %d days\n",
+			bounds.MinWork, bounds.MaxWork, bounds.MinBreak, bounds.MaxBreak, bounds.Window)
+		for _, reason := range decision.Reasons {
+			fmt.Printf("   • %s\n", reason)
+		}
+
+		log, err := config.LoadAdaptiveLog(profile.Name)
+		if err != nil {
+			fmt.Printf("Error loading adaptive log: %v\n", err)
+			return
+		}
+		if len(log) == 0 {
+			fmt.Println("\n   No runs yet.")
+			fmt.Println()
+			return
+		}
+		if len(log) > 5 {
+			log = log[len(log)-5:]
+		}
+		fmt.Println("\n📜 Recent runs:")
+		rules := clock.Load()
+		for i := len(log) - 1; i >= 0; i-- {
+			entry := log[i]
+			fmt.Printf("   %s  work %d min, break %d min", rules.In(entry.Time).Format("2006-01-02 15:04"), entry.WorkMinutes, entry.BreakMinutes)
+			if entry.Changed() {
+				fmt.Printf(" (was %d/%d)", entry.PrevWork, entry.PrevBreak)
+			}
+			fmt.Println()
+			for _, reason := range entry.Reasons {
+				fmt.Printf("      • %s\n", reason)
+			}
+		}
+		fmt.Println()
+	},
+}
+
+// adaptiveBoundsChanged reports whether any adaptive bound flag was given
+func adaptiveBoundsChanged(cmd *cobra.Command) bool {
+	for _, flag := range []string{"min-work", "max-work", "min-break", "max-break", "window"} {
+		if cmd.Flags().Changed(flag) {
+			return true
+		}
+	}
+	return false
+}
+
+// applyAdaptiveFlags returns bounds with the adaptive bound flags applied
+func applyAdaptiveFlags(cmd *cobra.Command, bounds config.Adaptive) *config.Adaptive {
+	flags := cmd.Flags()
+	for flag, value := range map[string]*int{
+		"min-work":  &bounds.MinWork,
+		"max-work":  &bounds.MaxWork,
+		"min-break": &bounds.MinBreak,
+		"max-break": &bounds.MaxBreak,
+		"window":    &bounds.Window,
+	} {
+		if flags.Changed(flag) {
+			*value, _ = flags.GetInt(flag)
+		}
+	}
+	return &bounds
+}
+
+// addAdaptiveFlags adds the flags that make a profile adaptive
+func addAdaptiveFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("adaptive", false, "Choose work and break lengths from recent sessions")
+	cmd.Flags().Int("min-work", 0, "Shortest adaptive work length (default 15)")
+	cmd.Flags().Int("max-work", 0, "Longest adaptive work length (default 60)")
+	cmd.Flags().Int("min-break", 0, "Shortest adaptive break (default 3)")
+	cmd.Flags().Int("max-break", 0, "Longest adaptive break (default 20)")
+	cmd.Flags().Int("window", 0, "Days of history adaptive lengths learn from (default 14)")
+}
+
+// profileSummary describes a profile's timing on one line
+func profileSummary(profile config.Profile) string {
+	if len(profile.Intervals) > 0 {
+		return timer.SummarizePlan(profile.Intervals)
+	}
+	summary := fmt.Sprintf("Work: %dm, Break: %dm, Sessions: %d", profile.WorkMinutes, profile.BreakMinutes, profile.NumSessions)
+	if profile.LongBreakMinutes > 0 {
+		summary += fmt.Sprintf(", Long break: %dm every %d", profile.LongBreakMinutes, profile.LongBreakEvery)
+	}
+	if profile.Adaptive != nil {
+		summary += ", Adaptive"
+	}
+	return summary
+}
+
 func init() {
 	createProfileCmd.Flags().String("description", "", "Profile description")
-	
+	createProfileCmd.Flags().Int("long-break", 0, "Long break minutes")
+	createProfileCmd.Flags().Int("long-every", 4, "Take the long break after every this many sessions")
+	createProfileCmd.Flags().String("intervals", "", "Explicit interval sequence, e.g. \"Focus:focus:52,Break:break:17\"")
+
+	editProfileCmd.Flags().String("name", "", "Rename the profile")
+	editProfileCmd.Flags().IntP("work", "w", 0, "Work minutes")
+	editProfileCmd.Flags().IntP("break", "b", 0, "Break minutes")
+	editProfileCmd.Flags().IntP("sessions", "s", 0, "Number of sessions")
+	editProfileCmd.Flags().String("description", "", "Profile description")
+	editProfileCmd.Flags().Int("long-break", 0, "Long break minutes, 0 to turn it off")
+	editProfileCmd.Flags().Int("long-every", 0, "Take the long break after every this many sessions")
+	editProfileCmd.Flags().String("intervals", "", "Explicit interval sequence, empty to use work/break settings")
+	addAdaptiveFlags(createProfileCmd)
+	addAdaptiveFlags(editProfileCmd)
+
+	exportProfileCmd.Flags().StringP("output", "o", "", "File to write (default: print)")
+	importProfileCmd.Flags().Bool("force", false, "Replace profiles that already exist")
+
 	profileCmd.AddCommand(listProfilesCmd)
+	profileCmd.AddCommand(showProfileCmd)
 	profileCmd.AddCommand(useProfileCmd)
 	profileCmd.AddCommand(createProfileCmd)
+	profileCmd.AddCommand(editProfileCmd)
+	profileCmd.AddCommand(deleteProfileCmd)
+	profileCmd.AddCommand(cloneProfileCmd)
+	profileCmd.AddCommand(exportProfileCmd)
+	profileCmd.AddCommand(importProfileCmd)
+	profileCmd.AddCommand(explainProfileCmd)
 	rootCmd.AddCommand(profileCmd)
 }
\ No newline at end of file
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Flack74/pom/report"
+)
+
+var (
+	reportDate   string
+	reportFormat string
+	reportOutput string
+)
+
+var reportCmd = &cobra.Command{
+	Use:       "report <week|month>",
+	Short:     "📅 Review a week or month",
+	ValidArgs: []string{report.Week, report.Month},
+	Args:      cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	Long: `📅 Weekly and Monthly Reviews
+
+Review a week (Monday to Sunday) or calendar month:
+  • Focus hours against your daily goal
+  • Best and worst days
+  • Tasks you worked on or completed
+  • How your streak changed
+  • Comparison with the previous week or month
+  • Suggestions for the next one
+
+The current week or month is compared with the same number of days of the
+previous one. Reports render as terminal text, Markdown or a self-contained
+HTML page.
+
+Examples:
+  pom report week                          This week so far
+  pom report month --date 2024-05-01       May 2024
+  pom report week -f markdown -o week.md   Save as Markdown
+  pom report month -f html -o month.html   Save as an HTML page`,
+	Run: func(cmd *cobra.Command, args []string) {
+		date, err := report.ParseDate(reportDate)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		review, err := report.Build(args[0], date)
+		if err != nil {
+			fmt.Printf("Error building report: %v\n", err)
+			return
+		}
+
+		var buf bytes.Buffer
+		if err := report.Render(&buf, review, reportFormat); err != nil {
+			fmt.Printf("Error rendering report: %v\n", err)
+			return
+		}
+
+		if reportOutput == "" {
+			buf.WriteTo(os.Stdout)
+			return
+		}
+		if err := os.WriteFile(reportOutput, buf.Bytes(), 0644); err != nil {
+			fmt.Printf("Error saving report: %v\n", err)
+			return
+		}
+		fmt.Printf("✅ Report saved to: %s\n", reportOutput)
+	},
+}
+
+func init() {
+	reportCmd.Flags().StringVar(&reportDate, "date", "", "any day in the period to review (YYYY-MM-DD, default today)")
+	reportCmd.Flags().StringVarP(&reportFormat, "format", "f", report.FormatText, "output format: text, markdown or html")
+	reportCmd.Flags().StringVarP(&reportOutput, "output", "o", "", "write the report to a file")
+
+	rootCmd.AddCommand(reportCmd)
+}
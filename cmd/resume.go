@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Flack74/pom/daemon"
+)
+
+var abandonRun bool
+
+var resumeCmd = &cobra.Command{
+	Use:   "resume",
+	Short: "⏯️  Resume a paused or unfinished session",
+	Long: `⏯️  Resume a Paused or Unfinished Session
+
+Resume the session paused with 'pom pause'. The timer state is also saved
+to disk, so if the pom daemon itself exited mid-session, the run is
+recovered the next time it starts: resume picks it up at the phase it was
+in, with the time that was left, and carries on with the remaining
+sessions.
+
+Use --abandon to give up on a recovered run instead. The unfinished
+interval is logged as abandoned with the time actually spent, and it still
+counts towards task time and daily minutes.
+
+Examples:
+  pom resume              Continue where you left off
+  pom resume --abandon    Log the unfinished run and discard it`,
+	Run: func(cmd *cobra.Command, args []string) {
+		method := daemon.MethodResume
+		if abandonRun {
+			method = daemon.MethodStop
+		}
+
+		snap, err := callDaemon(method)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		if abandonRun {
+			fmt.Println("🗑️  Session abandoned and logged")
+			return
+		}
+		fmt.Println("▶️  Timer resumed. Watch it with: pom status --watch")
+		printStatus(snap)
+	},
+}
+
+func init() {
+	resumeCmd.Flags().BoolVar(&abandonRun, "abandon", false, "log the unfinished session as abandoned instead of resuming it")
+	rootCmd.AddCommand(resumeCmd)
+}
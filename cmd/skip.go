@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Flack74/pom/daemon"
+)
+
+var skipCmd = &cobra.Command{
+	Use:   "skip",
+	Short: "⏭️  Skip to the next focus or break",
+	Long: `⏭️  Skip to the Next Phase
+
+End the current focus or break of the running session early and move on
+to the next one. The cut-short interval is logged as skipped with the time
+actually spent. Skipping the last focus interval finishes the session.
+
+Examples:
+  pom skip      Skip the rest of this break`,
+	Run: func(cmd *cobra.Command, args []string) {
+		snap, err := callDaemon(daemon.MethodSkip)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println("⏭️  Skipped")
+		printStatus(snap)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(skipCmd)
+}
@@ -3,14 +3,13 @@ package cmd
 import (
 	"fmt"
 	"os"
-	"os/signal"
-	"syscall"
-	"time"
+	"reflect"
 
 	"github.com/spf13/cobra"
 
 	"github.com/Flack74/pom/config"
-	"github.com/Flack74/pom/logs"
+	"github.com/Flack74/pom/daemon"
+	"github.com/Flack74/pom/timer"
 )
 
 var (
@@ -20,6 +19,9 @@ var (
 	saveConfig   bool
 	taskID       string
 	profileName  string
+	longBreak    int
+	longEvery    int
+	intervalSpec string
 )
 
 var startCmd = &cobra.Command{
@@ -31,30 +33,54 @@ Start a focused work session using the Pomodoro Technique. You can customize:
   • Work duration (default: 25 minutes)
   • Break duration (default: 5 minutes)
   • Number of sessions
+  • A long break every few sessions
+  • An explicit sequence of named intervals
   • Link to a planned task
 
-During the session:
+Profiles can define a long break or their own interval sequence (see
+'pom profile'). Passing -w, -b or -s runs work/break intervals instead of a
+profile's own sequence, keeping its long break. Adaptive profiles choose
+their work and break lengths from your recent sessions before each run;
+'pom profile explain' shows why.
+
+The timer runs in the background 'pom daemon', which is started
+automatically. During the session:
   • Press 'p' to pause
   • Press 'r' to resume
-  • Press 'q' to quit (progress is saved)
+  • Press 's' to skip to the next focus or break
+  • Press 'q' or Ctrl+C to quit (progress is saved)
+  • Press 'd' to detach and leave the timer running
+
+Reattach from any terminal with 'pom status --watch'.
 
 Examples:
   pom start                     Start with default settings
   pom start -w 30 -b 10        30min work + 10min break
   pom start -s 4               Do 4 sessions
-  pom start -t task-id         Link to a planned task
+  pom start -s 8 -L 15 --long-every 4
+                               15min long break after every 4th session
+  pom start -i "Deep work:focus:90,Walk:break:20,Deep work:focus:90"
+                               Run a custom interval sequence
+  pom start -t 3               Link to planned task #3
   pom start -c                 Save settings as default`,
 	Run: func(cmd *cobra.Command, args []string) {
+		activeProfile := ""
+		var adaptive *config.AdaptiveDecision
+		var profilePlan []timer.Interval
+
 		// Load profile settings if specified
 		if profileName != "" {
 			profile, err := config.GetProfile(profileName)
 			if err != nil {
-				fmt.Printf("Profile '%s' not found, using default settings\n", profileName)
+				fmt.Printf("⚠️  %v, using default settings\n", err)
 			} else {
+				profile, adaptive = adaptProfile(cmd, profile)
 				// Only use profile values if user didn't specify flags
 				if !cmd.Flags().Changed("work") { workMin = profile.WorkMinutes }
 				if !cmd.Flags().Changed("break") { breakMin = profile.BreakMinutes }
 				if !cmd.Flags().Changed("sessions") { numberOfSess = profile.NumSessions }
+				profilePlan = applyProfilePlan(cmd, profile)
+				activeProfile = profile.Name
 				fmt.Printf("Using profile: %s\n", profile.Name)
 			}
 		} else {
@@ -63,89 +89,161 @@ Examples:
 			if cfg.CurrentProfile != "" {
 				profile, err := config.GetProfile(cfg.CurrentProfile)
 				if err == nil {
+					profile, adaptive = adaptProfile(cmd, profile)
 					if !cmd.Flags().Changed("work") { workMin = profile.WorkMinutes }
 					if !cmd.Flags().Changed("break") { breakMin = profile.BreakMinutes }
 					if !cmd.Flags().Changed("sessions") { numberOfSess = profile.NumSessions }
+					profilePlan = applyProfilePlan(cmd, profile)
+					activeProfile = profile.Name
 				}
 			}
 		}
 
+		plan := profilePlan
+		if intervalSpec != "" {
+			var err error
+			if plan, err = timer.ParsePlan(intervalSpec); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: invalid intervals: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		if plan == nil {
+			plan = timer.ClassicPlan(workMin, breakMin, numberOfSess, longBreak, longEvery)
+		}
+
 		if saveConfig {
 			if err := SaveConfig(workMin, breakMin, numberOfSess); err != nil {
 				fmt.Fprintf(os.Stderr, "Error saving config: %v\n", err)
 			}
 		}
 
-		// Create a channel to handle interrupts
-		sigChan := make(chan os.Signal, 1)
-		signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
-
-		// Execute session start plugins
-		sessionData := map[string]string{
-			"DURATION": fmt.Sprintf("%d", workMin),
-			"BREAK_DURATION": fmt.Sprintf("%d", breakMin),
-			"SESSIONS": fmt.Sprintf("%d", numberOfSess),
-			"DATE": time.Now().Format("2006-01-02T15:04:05Z"),
-			"TASK_ID": taskID,
+		theme, err := config.LoadTheme()
+		if err != nil {
+			theme = config.DefaultTheme
 		}
-		config.ExecutePlugins("session_start", sessionData)
-
-		// Start the timer in a goroutine
-		doneChan := make(chan bool)
-		go func() {
-			startTime := time.Now()
-			isCompleted := StartPomodoro(workMin, breakMin, numberOfSess, taskID)
-			doneChan <- isCompleted
-
-			// Log the session
-			endTime := time.Now()
-			if err := logs.LogSession(workMin, breakMin, numberOfSess, startTime, endTime, isCompleted); err != nil {
-				fmt.Fprintf(os.Stderr, "⚠️  Failed to log session: %v\n", err)
-			}
 
-			// Update task progress if a task is linked
-			if taskID != "" && isCompleted {
-				if err := config.UpdateTaskProgress(taskID, 1, workMin); err != nil {
-					fmt.Fprintf(os.Stderr, "⚠️  Failed to update task progress: %v\n", err)
-				}
+		// If a task ID is provided, verify it exists
+		if taskID != "" {
+			task, err := findTask(taskID)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%s⚠️  %v%s\n", theme.WarningColor, err, theme.TextColor)
+				os.Exit(1)
 			}
+			taskID = task.ID
+			fmt.Printf("%s📎 Linked to task: %s%s\n", theme.HighlightColor, task.Title, theme.TextColor)
+		}
 
-			// Update goals progress
-			if isCompleted {
-				if err := config.UpdateProgress(1, workMin); err != nil {
-					fmt.Fprintf(os.Stderr, "⚠️  Failed to update goals progress: %v\n", err)
-				}
+		client, err := daemon.Connect()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s⚠️  %v%s\n", theme.WarningColor, err, theme.TextColor)
+			os.Exit(1)
+		}
+		defer client.Close()
+
+		_, err = client.Call(daemon.MethodStart, daemon.StartParams{
+			WorkMinutes:  workMin,
+			BreakMinutes: breakMin,
+			Sessions:     numberOfSess,
+			Intervals:    plan,
+			Profile:      activeProfile,
+			TaskID:       taskID,
+		})
+		if err != nil {
+			if snap, statusErr := client.Call(daemon.MethodStatus, nil); statusErr == nil && snap.Recovered {
+				fmt.Printf("⚠️  Found an unfinished session %d/%d\n", snap.Session, snap.Sessions)
+				fmt.Println("   Run 'pom resume' to continue it or 'pom stop' to log it as abandoned")
+			} else if statusErr == nil && snap.Active {
+				fmt.Println("⏳ A session is already running. Attach to it with: pom status --watch")
+			} else {
+				fmt.Fprintf(os.Stderr, "%s⚠️  Failed to start session: %v%s\n", theme.WarningColor, err, theme.TextColor)
 			}
+			os.Exit(1)
+		}
+		if adaptive != nil {
+			if err := config.SaveAdaptive(*adaptive); err != nil {
+				fmt.Fprintf(os.Stderr, "Error saving adaptive lengths: %v\n", err)
+			}
+		}
 
-			// Execute session end plugins
-			sessionData["COMPLETED"] = fmt.Sprintf("%t", isCompleted)
-			sessionData["TOTAL_MINUTES"] = fmt.Sprintf("%d", workMin*numberOfSess)
-			config.ExecutePlugins("session_end", sessionData)
-		}()
+		// Print session info
+		fmt.Printf("\n%s🎯 Starting Pomodoro Timer%s\n", theme.HighlightColor, theme.TextColor)
+		fmt.Printf("%s📚 %s%s\n\n", theme.TextColor, describePlan(plan), theme.TextColor)
 
-		// Wait for either completion or interrupt
-		select {
-		case <-sigChan:
+		switch attach(theme, true) {
+		case attachFinished:
+			fmt.Println("🎉 Pomodoro session completed!")
+			if taskID != "" {
+				fmt.Println("📝 Task progress updated")
+			}
+		case attachStopped:
 			fmt.Println("\n⚠️  Pomodoro session interrupted")
 			os.Exit(1)
-		case isCompleted := <-doneChan:
-			if isCompleted {
-				fmt.Println("🎉 Pomodoro session completed!")
-				if taskID != "" {
-					fmt.Println("📝 Task progress updated")
-				}
-			}
 		}
 	},
 }
 
+// applyProfilePlan returns a profile's explicit interval sequence unless the
+// work, break or session flags override it, and takes the profile's long
+// break when none was given on the command line
+func applyProfilePlan(cmd *cobra.Command, profile config.Profile) []timer.Interval {
+	flags := cmd.Flags()
+	if !flags.Changed("long-break") && !flags.Changed("long-every") {
+		longBreak, longEvery = profile.LongBreakMinutes, profile.LongBreakEvery
+	}
+	if len(profile.Intervals) == 0 || flags.Changed("work") || flags.Changed("break") || flags.Changed("sessions") {
+		return nil
+	}
+	return profile.Intervals
+}
+
+// adaptProfile works out an adaptive profile's lengths for this run and
+// explains the choice, unless the work and break lengths were given. The
+// decision is returned to be saved once the run starts.
+func adaptProfile(cmd *cobra.Command, profile config.Profile) (config.Profile, *config.AdaptiveDecision) {
+	if profile.Adaptive == nil || cmd.Flags().Changed("work") || cmd.Flags().Changed("break") {
+		return profile, nil
+	}
+
+	adapted, decision, err := config.Adapt(profile)
+	if err != nil {
+		fmt.Printf("⚠️  Could not adapt profile: %v\n", err)
+		return profile, nil
+	}
+	fmt.Printf("🧠 Adaptive lengths: work %d min, break %d min", decision.WorkMinutes, decision.BreakMinutes)
+	if decision.Changed() {
+		fmt.Printf(" (was %d/%d)", decision.PrevWork, decision.PrevBreak)
+	}
+	fmt.Println()
+	for _, reason := range decision.Reasons {
+		fmt.Printf("   • %s\n", reason)
+	}
+	return adapted, &decision
+}
+
+// describePlan summarizes a run for the start banner
+func describePlan(plan []timer.Interval) string {
+	classic := timer.ClassicPlan(workMin, breakMin, numberOfSess, 0, 0)
+	if reflect.DeepEqual(plan, classic) {
+		return fmt.Sprintf("Work: %d min | Break: %d min | Sessions: %d", workMin, breakMin, numberOfSess)
+	}
+	return fmt.Sprintf("%s (%d min of focus)", timer.SummarizePlan(plan), timer.FocusMinutes(plan))
+}
+
+// findTask returns the task ref refers to: its number, ID or a prefix of the ID
+func findTask(ref string) (config.Task, error) {
+	return config.GetTask(ref)
+}
+
 func init() {
 	startCmd.Flags().IntVarP(&workMin, "work", "w", 25, "work minutes")
 	startCmd.Flags().IntVarP(&breakMin, "break", "b", 5, "break minutes")
 	startCmd.Flags().IntVarP(&numberOfSess, "sessions", "s", 4, "number of sessions")
 	startCmd.Flags().BoolVarP(&saveConfig, "save-config", "c", false, "save as default configuration")
-	startCmd.Flags().StringVarP(&taskID, "task", "t", "", "link session to a task ID")
+	startCmd.Flags().StringVarP(&taskID, "task", "t", "", "link session to a task number or ID")
 	startCmd.Flags().StringVarP(&profileName, "profile", "p", "", "use specific profile")
+	startCmd.Flags().IntVarP(&longBreak, "long-break", "L", 0, "long break minutes")
+	startCmd.Flags().IntVar(&longEvery, "long-every", 4, "take the long break after every this many sessions")
+	startCmd.Flags().StringVarP(&intervalSpec, "intervals", "i", "", "interval sequence, e.g. \"Focus:focus:52,Break:break:17\"")
 
 	rootCmd.AddCommand(startCmd)
 }
@@ -8,6 +8,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/Flack74/pom/config"
+	"github.com/Flack74/pom/history"
 	"github.com/Flack74/pom/logs"
 )
 
@@ -22,6 +23,7 @@ Track your productivity with detailed statistics about your Pomodoro sessions:
   • Focus time tracking
   • Daily averages
   • Goal completion streaks
+  • Time spent in each kind of interval, such as long breaks
   • Task-specific statistics
 
 Examples:
@@ -49,27 +51,41 @@ Examples:
 			os.Exit(1)
 		}
 
-		// Get current goals
+		// Get current goals and today's target
 		goal, err := config.LoadGoal()
 		if err != nil {
 			goal = config.Goal{} // Empty goal if none set
 		}
+		progress, err := config.LoadProgress()
+		if err != nil {
+			progress = config.GoalProgress{TargetToday: goal.Daily()}
+		}
+		target := progress.TargetToday
 
 		// Print statistics header
 		fmt.Printf("\n%s📊 Pomodoro Statistics%s\n\n", theme.HighlightColor, theme.TextColor)
 
 		// Today's Progress
 		fmt.Printf("%s🌅 Today's Progress%s\n", theme.SuccessColor, theme.TextColor)
+		if progress.RestToday {
+			fmt.Println("   💤 Rest day")
+		}
 		fmt.Printf("   Sessions completed: %d", todaySessions)
-		if goal.DailySessionTarget > 0 {
-			fmt.Printf(" / %d (%.1f%%)", goal.DailySessionTarget, float64(todaySessions)/float64(goal.DailySessionTarget)*100)
+		if target.Sessions > 0 {
+			fmt.Printf(" / %d (%.1f%%)", target.Sessions, float64(todaySessions)/float64(target.Sessions)*100)
 		}
 		fmt.Println()
 		fmt.Printf("   Focus time: %d minutes", todayMinutes)
-		if goal.DailyMinutes > 0 {
-			fmt.Printf(" / %d (%.1f%%)", goal.DailyMinutes, float64(todayMinutes)/float64(goal.DailyMinutes)*100)
+		if target.Minutes > 0 {
+			fmt.Printf(" / %d (%.1f%%)", target.Minutes, float64(todayMinutes)/float64(target.Minutes)*100)
 		}
 		fmt.Println()
+		if progress.Week.HasTarget() {
+			fmt.Printf("   This week: %s\n", config.PeriodSummary(progress.Week))
+		}
+		if progress.Month.HasTarget() {
+			fmt.Printf("   This month: %s\n", config.PeriodSummary(progress.Month))
+		}
 
 		// All-time Stats
 		fmt.Printf("\n%s🏆 All-time Statistics%s\n", theme.SuccessColor, theme.TextColor)
@@ -77,13 +93,38 @@ Examples:
 		fmt.Printf("   Total focus time: %.0f minutes (%.1f hours)\n", totalFocusMinutes, totalFocusMinutes/60)
 		fmt.Printf("   Average sessions per day: %.1f\n", avgSessionsPerDay)
 
+		// Time by interval, so long breaks and custom sequences show up
+		if intervals, err := logs.GetIntervalStats(); err == nil && len(intervals) > 0 {
+			fmt.Printf("\n%s⏱️  Intervals%s\n", theme.SuccessColor, theme.TextColor)
+			for _, interval := range intervals {
+				icon := "📚"
+				if interval.Kind == history.KindBreak {
+					icon = "☕"
+				}
+				fmt.Printf("   %s %-20s %4d completed / %-4d %6.0f minutes\n",
+					icon, interval.Name, interval.Completed, interval.Count, interval.Minutes)
+			}
+		}
+
 		// Get current streak if goals are set
-		if goal.DailySessionTarget > 0 || goal.DailyMinutes > 0 {
-			progress, err := config.LoadProgress()
-			if err == nil {
-				fmt.Printf("\n%s🔥 Goal Streaks%s\n", theme.SuccessColor, theme.TextColor)
-				fmt.Printf("   Current streak: %d days\n", progress.CurrentStreak)
-				fmt.Printf("   Longest streak: %d days\n", progress.LongestStreak)
+		if goal.IsSet() {
+			fmt.Printf("\n%s🔥 Goal Streaks%s\n", theme.SuccessColor, theme.TextColor)
+			fmt.Printf("   Current streak: %d days\n", progress.CurrentStreak)
+			fmt.Printf("   Longest streak: %d days\n", progress.LongestStreak)
+			if _, max := goal.Freezes(); max > 0 {
+				fmt.Printf("   Streak freezes: %d/%d\n", progress.Freezes, max)
+			}
+		}
+
+		// Named goals for tags, tasks and profiles
+		if named, err := config.NamedGoalsProgress(); err == nil && len(named) > 0 {
+			fmt.Printf("\n%s🎯 Goals%s\n", theme.SuccessColor, theme.TextColor)
+			for _, p := range named {
+				mark := ""
+				if p.Met {
+					mark = " ✅"
+				}
+				fmt.Printf("   %-16s %s %3.0f%%  %s%s\n", truncate(p.Goal.Name, 16), bar(p.Percent, 100, 20), p.Percent, p.Summary(), mark)
 			}
 		}
 
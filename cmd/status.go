@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Flack74/pom/config"
+	"github.com/Flack74/pom/daemon"
+	"github.com/Flack74/pom/timer"
+)
+
+var watchStatus bool
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "⏱️  Show the running session",
+	Long: `⏱️  Show the Running Session
+
+Show the phase, session number and time left of the session running in
+the pom daemon. With --watch, attach this terminal to the live countdown;
+any number of terminals can watch the same session.
+
+While watching:
+  • Press 'p' to pause, 'r' to resume, 's' to skip or 'q' to quit the session
+  • Press 'd' or Ctrl+C to detach and leave the timer running
+
+Examples:
+  pom status            Show the current session
+  pom status --watch    Attach to the live countdown`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if watchStatus {
+			theme, err := config.LoadTheme()
+			if err != nil {
+				theme = config.DefaultTheme
+			}
+			attach(theme, false)
+			return
+		}
+
+		snap, err := callDaemon(daemon.MethodStatus)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		printStatus(snap)
+	},
+}
+
+// callDaemon invokes a parameterless method on the daemon, starting it if needed
+func callDaemon(method string) (timer.Snapshot, error) {
+	client, err := daemon.Connect()
+	if err != nil {
+		return timer.Snapshot{}, err
+	}
+	defer client.Close()
+
+	return client.Call(method, nil)
+}
+
+func init() {
+	statusCmd.Flags().BoolVarP(&watchStatus, "watch", "w", false, "attach to the live countdown")
+	rootCmd.AddCommand(statusCmd)
+}
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Flack74/pom/daemon"
+)
+
+var stopCmd = &cobra.Command{
+	Use:   "stop",
+	Short: "⏹️  Stop the running session",
+	Long: `⏹️  Stop the Running Session
+
+End the session running in the pom daemon early. The current interval is
+logged as interrupted with the time actually spent, which still counts
+towards task time and daily minutes.
+
+A session recovered after the daemon exited is logged as abandoned instead.
+
+Examples:
+  pom stop      Stop the timer`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if _, err := callDaemon(daemon.MethodStop); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println("⏹️  Session stopped and logged")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(stopCmd)
+}
@@ -1,10 +1,18 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"log"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/Flack74/pom/daemon"
 	"github.com/Flack74/pom/web"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 var webCmd = &cobra.Command{
@@ -17,36 +25,209 @@ Launch a web dashboard to access all Pomodoro features from any device:
   • Real-time timer with progress visualization
   • All CLI features accessible via web
   • Cross-platform compatibility (Windows, Mac, Linux)
-  • Background daemon mode available
+  • Background mode with pom web stop / pom web status
+
+The server only listens on 127.0.0.1 unless --bind says otherwise. Browsers
+sign in with an access token kept in ~/.config/pom/web.token; API clients send
+it in the X-Pom-Token header (or Authorization: Bearer). Plugins can only be
+added, enabled or disabled over 127.0.0.1, whatever --bind is.
+
+Ctrl+C or SIGTERM shut the server down gracefully: requests in flight finish
+and open tabs receive the final timer state. A running session keeps going in
+the pom daemon.
+
+With --tls the server uses HTTPS. Pass your own --cert and --key, or let pom
+create a self-signed certificate in ~/.config/pom on first run and reuse it.
 
 Examples:
   pom web                Start on default port 8080
   pom web -p 3000        Start on custom port
-  pom web -d             Run in background (daemon mode)`,
+  pom web --bind 0.0.0.0 Listen on every interface
+  pom web --bind 0.0.0.0 --tls --redirect-http 8081
+                         HTTPS for other devices, redirecting plain HTTP
+  pom web --cert cert.pem --key key.pem
+                         HTTPS with your own certificate
+  pom web -d             Run in the background
+  pom web status         Show the background server
+  pom web stop           Stop it gracefully
+  pom web token          Show the access token`,
 	Run: func(cmd *cobra.Command, args []string) {
 		port, _ := cmd.Flags().GetInt("port")
-		daemon, _ := cmd.Flags().GetBool("daemon")
-		
-		if daemon {
-			fmt.Printf("🌐 Web UI daemon mode on port %d\n", port)
-			fmt.Printf("🔗 Access at: http://localhost:%d\n", port)
-			fmt.Println("ℹ️  Run with: nohup pom web & (for true background)")
-			fmt.Println("ℹ️  Or use: pom web (and run in tmux/screen)")
-		}
-		
-		// Always start server the same way
-		server := web.NewServer()
-		if err := server.Start(port); err != nil {
+		bind, _ := cmd.Flags().GetString("bind")
+		useTLS, _ := cmd.Flags().GetBool("tls")
+		certFile, _ := cmd.Flags().GetString("cert")
+		keyFile, _ := cmd.Flags().GetString("key")
+		redirectPort, _ := cmd.Flags().GetInt("redirect-http")
+		background, _ := cmd.Flags().GetBool("daemon")
+
+		if inst, ok := web.Running(); ok {
+			fmt.Printf("❌ pom web is already running (pid %d) at %s\n", inst.PID, inst.URL)
+			fmt.Println("💡 Stop it first with: pom web stop")
+			os.Exit(1)
+		}
+
+		if background {
+			startWebDaemon(cmd)
+			return
+		}
+
+		server, err := web.NewServer()
+		if err != nil {
 			fmt.Fprintf(os.Stderr, "Failed to start web server: %v\n", err)
 			os.Exit(1)
 		}
-		
 
+		sigChan := make(chan os.Signal, 1)
+		signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+		go func() {
+			<-sigChan
+			fmt.Println("\n🛑 Shutting down web server...")
+			ctx, cancel := context.WithTimeout(context.Background(), webShutdownTimeout)
+			defer cancel()
+			if err := server.Shutdown(ctx); err != nil {
+				log.Printf("web server shutdown: %v", err)
+			}
+		}()
+
+		opts := web.Options{
+			Bind:         bind,
+			Port:         port,
+			TLS:          useTLS,
+			CertFile:     certFile,
+			KeyFile:      keyFile,
+			RedirectPort: redirectPort,
+		}
+		if err := server.Start(opts); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to start web server: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println("👋 Web server stopped")
+	},
+}
+
+// webShutdownTimeout bounds how long shutdown waits for requests in flight
+const webShutdownTimeout = 10 * time.Second
+
+// startWebDaemon runs `pom web` with the same flags as a detached background
+// process and waits for it to record itself in the pidfile
+func startWebDaemon(cmd *cobra.Command) {
+	args := []string{"web"}
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		if f.Name != "daemon" {
+			args = append(args, fmt.Sprintf("--%s=%s", f.Name, f.Value.String()))
+		}
+	})
+
+	logPath, err := web.LogPath()
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+	pid, err := daemon.Spawn(logPath, args...)
+	if err != nil {
+		fmt.Printf("❌ Failed to start web server: %v\n", err)
+		os.Exit(1)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if inst, ok := web.Running(); ok && inst.PID == pid {
+			fmt.Printf("🌐 Web UI running in the background (pid %d)\n", pid)
+			fmt.Printf("🔗 Access at: %s\n", inst.URL)
+			fmt.Println("🔑 Sign in with the token from: pom web token")
+			fmt.Printf("📄 Log: %s\n", logPath)
+			fmt.Println("🛑 Stop with: pom web stop")
+			return
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	fmt.Printf("❌ Web server did not start, see %s\n", logPath)
+	os.Exit(1)
+}
+
+var webStopCmd = &cobra.Command{
+	Use:   "stop",
+	Short: "🛑 Stop the background web server",
+	Run: func(cmd *cobra.Command, args []string) {
+		inst, err := web.Stop(webShutdownTimeout + 5*time.Second)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✅ pom web stopped (pid %d)\n", inst.PID)
+	},
+}
+
+var webStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "📡 Show whether the web server is running",
+	Run: func(cmd *cobra.Command, args []string) {
+		inst, ok := web.Running()
+		if !ok {
+			fmt.Println("⚪ pom web is not running")
+			return
+		}
+
+		fmt.Printf("🟢 pom web is running (pid %d)\n", inst.PID)
+		if inst.URL != "" {
+			fmt.Printf("🔗 URL: %s\n", inst.URL)
+		}
+		fmt.Printf("⏱️  Up since %s (%s)\n", inst.Started.Format("2006-01-02 15:04:05"),
+			time.Since(inst.Started).Round(time.Second))
+		if logPath, err := web.LogPath(); err == nil {
+			if _, err := os.Stat(logPath); err == nil {
+				fmt.Printf("📄 Log: %s\n", logPath)
+			}
+		}
+	},
+}
+
+var webTokenCmd = &cobra.Command{
+	Use:   "token",
+	Short: "🔑 Show or reset the web access token",
+	Long: `🔑 Web Access Token
+
+Print the token used to sign in to the web UI and API. Resetting it signs out
+every browser; restart pom web to use the new token.
+
+Examples:
+  pom web token          Show the token
+  pom web token --reset  Generate a new token`,
+	Run: func(cmd *cobra.Command, args []string) {
+		reset, _ := cmd.Flags().GetBool("reset")
+
+		var token string
+		var err error
+		if reset {
+			token, err = web.ResetToken()
+		} else {
+			token, err = web.LoadToken()
+		}
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(1)
+		}
+
+		if reset {
+			fmt.Println("✅ New token generated. Restart pom web to apply it.")
+		}
+		fmt.Println(token)
 	},
 }
 
 func init() {
+	webTokenCmd.Flags().Bool("reset", false, "Generate a new token")
+	webCmd.AddCommand(webTokenCmd)
+	webCmd.AddCommand(webStopCmd)
+	webCmd.AddCommand(webStatusCmd)
+
 	webCmd.Flags().IntP("port", "p", 8080, "Port to run web server on")
-	webCmd.Flags().BoolP("daemon", "d", false, "Run in background (daemon mode)")
+	webCmd.Flags().String("bind", "127.0.0.1", "Address to listen on (0.0.0.0 for every interface)")
+	webCmd.Flags().Bool("tls", false, "Serve HTTPS (self-signed certificate unless --cert is given)")
+	webCmd.Flags().String("cert", "", "PEM certificate file for HTTPS")
+	webCmd.Flags().String("key", "", "PEM private key file for HTTPS")
+	webCmd.Flags().Int("redirect-http", 0, "Redirect plain HTTP on this port to HTTPS")
+	webCmd.Flags().BoolP("daemon", "d", false, "Run in the background (log in ~/.config/pom/web.log)")
 	rootCmd.AddCommand(webCmd)
 }
\ No newline at end of file
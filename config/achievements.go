@@ -0,0 +1,441 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Flack74/pom/clock"
+	"github.com/Flack74/pom/history"
+	"github.com/Flack74/pom/store"
+)
+
+// Achievement metrics. Session metrics count focus intervals and can be
+// scoped to a tag or profile; task metrics count completed tasks and can be
+// scoped to a tag.
+const (
+	MetricSessions       = "sessions"        // Completed focus sessions
+	MetricHours          = "hours"           // Focus hours
+	MetricDaySessions    = "day_sessions"    // Completed focus sessions in one day
+	MetricWeekHours      = "week_hours"      // Focus hours in one week, Monday to Sunday
+	MetricActiveDays     = "active_days"     // Days with a completed focus session
+	MetricStreak         = "streak"          // Goal streak in days
+	MetricTasksCompleted = "tasks_completed" // Completed tasks
+	MetricTaskSessions   = "task_sessions"   // Sessions spent on one completed task
+	MetricTaskEstimate   = "task_estimate"   // Estimated pomodoros of one completed task
+)
+
+// AchievementMetrics lists the metrics a rule can use
+var AchievementMetrics = []string{MetricSessions, MetricHours, MetricDaySessions, MetricWeekHours,
+	MetricActiveDays, MetricStreak, MetricTasksCompleted, MetricTaskSessions, MetricTaskEstimate}
+
+// Achievement is a declarative rule: it unlocks once Metric reaches Threshold
+type Achievement struct {
+	ID          string  `json:"id"`
+	Name        string  `json:"name"`
+	Description string  `json:"description,omitempty"`
+	Icon        string  `json:"icon,omitempty"`
+	Metric      string  `json:"metric"`
+	Threshold   float64 `json:"threshold"`
+	Tag         string  `json:"tag,omitempty"`     // Only sessions or tasks with this tag
+	Profile     string  `json:"profile,omitempty"` // Only sessions run with this profile
+}
+
+// Unlock records when an achievement was earned
+type Unlock struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	UnlockedAt time.Time `json:"unlocked_at"`
+}
+
+// AchievementState is what is stored under the achievements key
+type AchievementState struct {
+	Rules    []Achievement `json:"rules,omitempty"` // Custom rules, which replace built-in ones with the same ID
+	Unlocked []Unlock      `json:"unlocked"`
+}
+
+// AchievementStatus is an achievement with the progress towards it
+type AchievementStatus struct {
+	Achievement
+	Custom     bool       `json:"custom"`
+	Value      float64    `json:"value"`    // Current value of the metric
+	Progress   float64    `json:"progress"` // Value over threshold, capped at 1
+	Unlocked   bool       `json:"unlocked"`
+	UnlockedAt *time.Time `json:"unlocked_at,omitempty"`
+}
+
+// DefaultAchievements are the built-in rules
+var DefaultAchievements = []Achievement{
+	{ID: "first-session", Name: "First Tomato", Icon: "🍅", Description: "Complete your first focus session", Metric: MetricSessions, Threshold: 1},
+	{ID: "sessions-100", Name: "Century", Icon: "💯", Description: "Complete 100 focus sessions", Metric: MetricSessions, Threshold: 100},
+	{ID: "sessions-1000", Name: "Tomato Farmer", Icon: "🚜", Description: "Complete 1000 focus sessions", Metric: MetricSessions, Threshold: 1000},
+	{ID: "hours-10", Name: "Getting Serious", Icon: "⏱️", Description: "Focus for 10 hours in total", Metric: MetricHours, Threshold: 10},
+	{ID: "hours-100", Name: "Deep Worker", Icon: "🧠", Description: "Focus for 100 hours in total", Metric: MetricHours, Threshold: 100},
+	{ID: "day-8", Name: "Full Day", Icon: "☀️", Description: "Complete 8 focus sessions in one day", Metric: MetricDaySessions, Threshold: 8},
+	{ID: "week-10h", Name: "Ten-Hour Week", Icon: "📅", Description: "Focus for 10 hours in one week", Metric: MetricWeekHours, Threshold: 10},
+	{ID: "streak-7", Name: "Week Streak", Icon: "🔥", Description: "Meet your daily goal 7 days in a row", Metric: MetricStreak, Threshold: 7},
+	{ID: "streak-30", Name: "Month Streak", Icon: "🏅", Description: "Meet your daily goal 30 days in a row", Metric: MetricStreak, Threshold: 30},
+	{ID: "tasks-10", Name: "Finisher", Icon: "✅", Description: "Complete 10 tasks", Metric: MetricTasksCompleted, Threshold: 10},
+	{ID: "task-8", Name: "Marathon Task", Icon: "🏃", Description: "Finish a task estimated at 8 pomodoros", Metric: MetricTaskEstimate, Threshold: 8},
+}
+
+// ValidateAchievement checks a rule's ID, metric, threshold and scope
+func ValidateAchievement(rule Achievement) error {
+	switch {
+	case rule.ID == "":
+		return fmt.Errorf("%w achievement: an ID is required", ErrInvalid)
+	case len(rule.ID) > MaxProfileNameLength || !profileNamePattern.MatchString(rule.ID):
+		return fmt.Errorf("%w achievement: ID '%s' may only contain letters, digits, '.', '_' and '-', up to %d characters", ErrInvalid, rule.ID, MaxProfileNameLength)
+	case rule.Name == "":
+		return fmt.Errorf("%w achievement: a name is required", ErrInvalid)
+	case len(rule.Name) > MaxProfileNameLength || !labelPattern.MatchString(rule.Name):
+		return fmt.Errorf("%w achievement: name '%s' may only contain letters, digits, spaces, '_' and '-', up to %d characters", ErrInvalid, rule.Name, MaxProfileNameLength)
+	case !isAchievementMetric(rule.Metric):
+		return fmt.Errorf("%w achievement: unknown metric '%s', use %s", ErrInvalid, rule.Metric, strings.Join(AchievementMetrics, ", "))
+	case rule.Threshold <= 0:
+		return fmt.Errorf("%w achievement: threshold must be positive", ErrInvalid)
+	case rule.Metric == MetricStreak && (rule.Tag != "" || rule.Profile != ""):
+		return fmt.Errorf("%w achievement: streaks cannot be scoped to a tag or profile", ErrInvalid)
+	case rule.isTaskMetric() && rule.Profile != "":
+		return fmt.Errorf("%w achievement: task metrics cannot be scoped to a profile", ErrInvalid)
+	}
+	return nil
+}
+
+// isAchievementMetric reports whether metric is a known metric
+func isAchievementMetric(metric string) bool {
+	for _, known := range AchievementMetrics {
+		if metric == known {
+			return true
+		}
+	}
+	return false
+}
+
+// isTaskMetric reports whether the rule measures completed tasks
+func (a Achievement) isTaskMetric() bool {
+	return a.Metric == MetricTasksCompleted || a.Metric == MetricTaskSessions || a.Metric == MetricTaskEstimate
+}
+
+// Label returns the icon and name, e.g. "💯 Century"
+func (a Achievement) Label() string {
+	icon := a.Icon
+	if icon == "" {
+		icon = "🏆"
+	}
+	return icon + " " + a.Name
+}
+
+// AllRules returns the built-in rules with custom rules added or replacing
+// them by ID
+func (s AchievementState) AllRules() []Achievement {
+	rules := make([]Achievement, 0, len(DefaultAchievements)+len(s.Rules))
+	custom := map[string]bool{}
+	for _, rule := range s.Rules {
+		custom[rule.ID] = true
+	}
+	for _, rule := range DefaultAchievements {
+		if !custom[rule.ID] {
+			rules = append(rules, rule)
+		}
+	}
+	return append(rules, s.Rules...)
+}
+
+// LoadAchievements loads the custom rules and unlocks from the datastore
+func LoadAchievements() (AchievementState, error) {
+	var state AchievementState
+	err := store.View(func(tx *store.Tx) error {
+		var err error
+		state, err = getAchievements(tx)
+		return err
+	})
+	return state, err
+}
+
+// getAchievements reads the achievement state inside a transaction
+func getAchievements(tx *store.Tx) (AchievementState, error) {
+	state := AchievementState{Unlocked: []Unlock{}}
+	if _, err := tx.Get(store.KeyAchievements, &state); err != nil {
+		return AchievementState{}, err
+	}
+	return state, nil
+}
+
+// AddAchievement validates and adds a custom rule. A rule with the ID of a
+// built-in one replaces it; a rule with the ID of another custom rule is
+// refused.
+func AddAchievement(rule Achievement) error {
+	if err := ValidateAchievement(rule); err != nil {
+		return err
+	}
+	return store.Update(func(tx *store.Tx) error {
+		state, err := getAchievements(tx)
+		if err != nil {
+			return err
+		}
+		for _, existing := range state.Rules {
+			if existing.ID == rule.ID {
+				return fmt.Errorf("achievement '%s' %w", rule.ID, ErrExists)
+			}
+		}
+		state.Rules = append(state.Rules, rule)
+		return tx.Put(store.KeyAchievements, state)
+	})
+}
+
+// RemoveAchievement removes a custom rule. Unlocks are kept.
+func RemoveAchievement(id string) error {
+	return store.Update(func(tx *store.Tx) error {
+		state, err := getAchievements(tx)
+		if err != nil {
+			return err
+		}
+		for i, rule := range state.Rules {
+			if rule.ID == id {
+				state.Rules = append(state.Rules[:i], state.Rules[i+1:]...)
+				return tx.Put(store.KeyAchievements, state)
+			}
+		}
+		return fmt.Errorf("custom achievement '%s' %w", id, ErrNotFound)
+	})
+}
+
+// Achievements returns every rule with its progress, unlocked ones first in
+// the order they were earned
+func Achievements() ([]AchievementStatus, error) {
+	var result []AchievementStatus
+	err := store.View(func(tx *store.Tx) error {
+		state, err := getAchievements(tx)
+		if err != nil {
+			return err
+		}
+		data, err := readAchievementData(tx, time.Now())
+		if err != nil {
+			return err
+		}
+
+		unlocked := map[string]time.Time{}
+		for _, unlock := range state.Unlocked {
+			unlocked[unlock.ID] = unlock.UnlockedAt
+		}
+		custom := map[string]bool{}
+		for _, rule := range state.Rules {
+			custom[rule.ID] = true
+		}
+		for _, rule := range state.AllRules() {
+			value, _ := rule.measure(data)
+			status := AchievementStatus{Achievement: rule, Custom: custom[rule.ID], Value: value}
+			status.Progress = value / rule.Threshold
+			if status.Progress > 1 {
+				status.Progress = 1
+			}
+			if at, ok := unlocked[rule.ID]; ok {
+				status.Unlocked, status.UnlockedAt, status.Progress = true, &at, 1
+			}
+			result = append(result, status)
+		}
+		return nil
+	})
+
+	sort.SliceStable(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.Unlocked != b.Unlocked {
+			return a.Unlocked
+		}
+		if a.Unlocked {
+			return a.UnlockedAt.Before(*b.UnlockedAt)
+		}
+		return a.Progress > b.Progress
+	})
+	return result, err
+}
+
+// CheckAchievements unlocks the rules whose metric has reached its threshold
+// and returns the new unlocks. Each unlock is dated to when the threshold was
+// crossed, so achievements earned before this version are backdated.
+func CheckAchievements() ([]Unlock, error) {
+	var unlocked []Unlock
+	err := store.Update(func(tx *store.Tx) error {
+		state, err := getAchievements(tx)
+		if err != nil {
+			return err
+		}
+		data, err := readAchievementData(tx, time.Now())
+		if err != nil {
+			return err
+		}
+
+		have := map[string]bool{}
+		for _, unlock := range state.Unlocked {
+			have[unlock.ID] = true
+		}
+		for _, rule := range state.AllRules() {
+			if have[rule.ID] {
+				continue
+			}
+			if _, at := rule.measure(data); !at.IsZero() {
+				unlocked = append(unlocked, Unlock{ID: rule.ID, Name: rule.Label(), UnlockedAt: at.UTC()})
+			}
+		}
+		if len(unlocked) == 0 {
+			return nil
+		}
+		state.Unlocked = append(state.Unlocked, unlocked...)
+		return tx.Put(store.KeyAchievements, state)
+	})
+	return unlocked, err
+}
+
+// PluginData is the plugin environment for the achievement trigger
+func (u Unlock) PluginData() map[string]string {
+	return map[string]string{
+		"ACHIEVEMENT_ID":   u.ID,
+		"ACHIEVEMENT_NAME": u.Name,
+		"DATE":             u.UnlockedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+// achievementData is what achievement rules are measured against
+type achievementData struct {
+	records []history.Record // Focus intervals, oldest first
+	tasks   []Task           // Completed tasks, oldest first
+	tags    map[string][]string
+	days    []GoalDay
+	rules   clock.Rules
+	now     time.Time
+}
+
+// readAchievementData collects the data for measuring rules inside a transaction
+func readAchievementData(tx *store.Tx, now time.Time) (achievementData, error) {
+	records, err := history.Read(tx)
+	if err != nil {
+		return achievementData{}, err
+	}
+	tasks, err := getTasks(tx)
+	if err != nil {
+		return achievementData{}, err
+	}
+	var goal Goal
+	if _, err := tx.Get(store.KeyGoal, &goal); err != nil {
+		return achievementData{}, err
+	}
+
+	data := achievementData{tags: map[string][]string{}, rules: clock.Read(tx), now: now}
+	for _, record := range records {
+		if record.IsFocus() {
+			data.records = append(data.records, record)
+		}
+	}
+	sort.SliceStable(data.records, func(i, j int) bool {
+		return data.records[i].EndTime.Before(data.records[j].EndTime)
+	})
+	for _, task := range tasks.Tasks {
+		data.tags[task.ID] = task.Tags
+		if task.IsCompleted {
+			data.tasks = append(data.tasks, task)
+		}
+	}
+	sort.SliceStable(data.tasks, func(i, j int) bool {
+		return data.tasks[i].CompletedAt.Before(data.tasks[j].CompletedAt)
+	})
+	data.days = GoalDays(records, goal, data.rules, now)
+	return data, nil
+}
+
+// measure returns the best value of the rule's metric so far, and when it
+// first reached the threshold, or the zero time if it has not
+func (a Achievement) measure(data achievementData) (float64, time.Time) {
+	var value float64
+	var reached time.Time
+	bump := func(v float64, at time.Time) {
+		if v > value {
+			value = v
+		}
+		if reached.IsZero() && v >= a.Threshold {
+			reached = at
+		}
+	}
+
+	switch a.Metric {
+	case MetricStreak:
+		for _, day := range data.days {
+			at := data.rules.Start(day.Date.AddDate(0, 0, 1))
+			if at.After(data.now) {
+				at = data.now
+			}
+			bump(float64(day.Streak), at)
+		}
+		return value, reached
+	case MetricTasksCompleted, MetricTaskSessions, MetricTaskEstimate:
+		count := 0
+		for _, task := range data.tasks {
+			if a.Tag != "" && !hasTag(task.Tags, a.Tag) {
+				continue
+			}
+			count++
+			switch a.Metric {
+			case MetricTasksCompleted:
+				bump(float64(count), task.CompletedAt)
+			case MetricTaskSessions:
+				bump(float64(task.Sessions), task.CompletedAt)
+			default:
+				bump(float64(task.Estimate), task.CompletedAt)
+			}
+		}
+		return value, reached
+	}
+
+	var total float64
+	buckets := map[string]float64{}
+	for _, record := range data.records {
+		if a.Profile != "" && !strings.EqualFold(record.Profile, a.Profile) {
+			continue
+		}
+		if a.Tag != "" && !hasTag(data.tags[record.TaskID], a.Tag) {
+			continue
+		}
+		hours := float64(record.ActualSeconds) / 3600
+		day := data.rules.Day(record.EndTime)
+
+		switch a.Metric {
+		case MetricSessions:
+			if record.IsCompleted() {
+				total++
+				bump(total, record.EndTime)
+			}
+		case MetricHours:
+			total += hours
+			bump(total, record.EndTime)
+		case MetricDaySessions:
+			if record.IsCompleted() {
+				key := day.Format("2006-01-02")
+				buckets[key]++
+				bump(buckets[key], record.EndTime)
+			}
+		case MetricWeekHours:
+			key := day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7)).Format("2006-01-02")
+			buckets[key] += hours
+			bump(buckets[key], record.EndTime)
+		case MetricActiveDays:
+			key := day.Format("2006-01-02")
+			if record.IsCompleted() && buckets[key] == 0 {
+				buckets[key] = 1
+				total++
+				bump(total, record.EndTime)
+			}
+		}
+	}
+	return value, reached
+}
+
+// hasTag reports whether tags contains tag, ignoring case
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
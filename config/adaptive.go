@@ -0,0 +1,341 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/Flack74/pom/clock"
+	"github.com/Flack74/pom/history"
+	"github.com/Flack74/pom/store"
+	"github.com/Flack74/pom/timer"
+)
+
+// Defaults for adaptive profiles
+const (
+	DefaultAdaptiveWindow = 14 // Days of history considered
+	MaxAdaptiveLog        = 100
+
+	// adaptiveMinSamples is how many focus intervals are needed before
+	// lengths are changed
+	adaptiveMinSamples = 3
+
+	// adaptiveMinConfidence is the least confidence a suggestion needs to be applied
+	adaptiveMinConfidence = 0.6
+)
+
+// Adaptive holds the bounds an adaptive profile's lengths are kept within.
+// Zero values fall back to defaults.
+type Adaptive struct {
+	MinWork  int `json:"min_work,omitempty"`
+	MaxWork  int `json:"max_work,omitempty"`
+	MinBreak int `json:"min_break,omitempty"`
+	MaxBreak int `json:"max_break,omitempty"`
+	Window   int `json:"window_days,omitempty"` // Days of history considered
+}
+
+// WithDefaults returns the settings with unset bounds filled in
+func (a Adaptive) WithDefaults() Adaptive {
+	if a.MinWork == 0 {
+		a.MinWork = 15
+	}
+	if a.MaxWork == 0 {
+		a.MaxWork = 60
+	}
+	if a.MinBreak == 0 {
+		a.MinBreak = 3
+	}
+	if a.MaxBreak == 0 {
+		a.MaxBreak = 20
+	}
+	if a.Window == 0 {
+		a.Window = DefaultAdaptiveWindow
+	}
+	return a
+}
+
+// validateAdaptive checks an adaptive profile's bounds and that its current
+// lengths lie within them
+func validateAdaptive(profile Profile) error {
+	a := profile.Adaptive.WithDefaults()
+	switch {
+	case a.MinWork < 1 || a.MinWork > a.MaxWork || a.MaxWork > MaxWorkMinutes:
+		return fmt.Errorf("%w profile: adaptive work bounds must satisfy 1 <= min <= max <= %d, got %d-%d", ErrInvalid, MaxWorkMinutes, a.MinWork, a.MaxWork)
+	case a.MinBreak < 1 || a.MinBreak > a.MaxBreak || a.MaxBreak > MaxBreakMinutes:
+		return fmt.Errorf("%w profile: adaptive break bounds must satisfy 1 <= min <= max <= %d, got %d-%d", ErrInvalid, MaxBreakMinutes, a.MinBreak, a.MaxBreak)
+	case a.Window < 1 || a.Window > 365:
+		return fmt.Errorf("%w profile: adaptive window must be between 1 and 365 days, got %d", ErrInvalid, a.Window)
+	case profile.WorkMinutes < a.MinWork || profile.WorkMinutes > a.MaxWork:
+		return fmt.Errorf("%w profile: work time %d is outside the adaptive bounds %d-%d", ErrInvalid, profile.WorkMinutes, a.MinWork, a.MaxWork)
+	case profile.BreakMinutes < a.MinBreak || profile.BreakMinutes > a.MaxBreak:
+		return fmt.Errorf("%w profile: break time %d is outside the adaptive bounds %d-%d", ErrInvalid, profile.BreakMinutes, a.MinBreak, a.MaxBreak)
+	}
+	return nil
+}
+
+// AdaptiveSignals are the measurements an adaptive decision was based on
+type AdaptiveSignals struct {
+	Samples        int     `json:"samples"`         // Focus intervals considered
+	CompletionRate float64 `json:"completion_rate"` // Share of those that ran to the end
+	AvgPauseMin    float64 `json:"avg_pause_minutes"`
+	HourSamples    int     `json:"hour_samples"`    // Focus intervals started near this time of day
+	HourCompletion float64 `json:"hour_completion"` // Completion rate near this time of day
+}
+
+// AdaptiveDecision records the lengths chosen for one run and why. The base
+// lengths are the profile's, or the insights engine's suggestions, and are
+// what is saved in the profile; the run's lengths add the pause and time of
+// day adjustments for this run only.
+type AdaptiveDecision struct {
+	Time         time.Time       `json:"time"`
+	Profile      string          `json:"profile"`
+	PrevWork     int             `json:"prev_work"`
+	PrevBreak    int             `json:"prev_break"`
+	BaseWork     int             `json:"base_work"`
+	BaseBreak    int             `json:"base_break"`
+	WorkMinutes  int             `json:"work_minutes"`
+	BreakMinutes int             `json:"break_minutes"`
+	Confidence   float64         `json:"confidence"`
+	Signals      AdaptiveSignals `json:"signals"`
+	Reasons      []string        `json:"reasons"`
+}
+
+// Changed reports whether the decision changed the profile's lengths
+func (d AdaptiveDecision) Changed() bool {
+	return d.WorkMinutes != d.PrevWork || d.BreakMinutes != d.PrevBreak
+}
+
+// DecideAdaptive works out the lengths for the next run of an adaptive
+// profile at now from its recent history. The base lengths are the
+// profile's current ones, replaced by the confident work and break
+// suggestions of the insights engine. The run then lengthens the base break
+// if sessions are paused a lot and shortens the base focus at times of day
+// when fewer sessions are completed, within the profile's bounds.
+func DecideAdaptive(profile Profile, now time.Time) (AdaptiveDecision, error) {
+	records, err := history.Load()
+	if err != nil {
+		return AdaptiveDecision{}, err
+	}
+	return decideAdaptive(profile, records, clock.Load(), now), nil
+}
+
+// decideAdaptive is DecideAdaptive over the given session log, with times
+// of day in the rules' timezone
+func decideAdaptive(profile Profile, records []history.Record, rules clock.Rules, now time.Time) AdaptiveDecision {
+	now = rules.In(now)
+	bounds := profile.Adaptive.WithDefaults()
+	decision := AdaptiveDecision{
+		Time:         now.UTC(),
+		Profile:      profile.Name,
+		PrevWork:     profile.WorkMinutes,
+		PrevBreak:    profile.BreakMinutes,
+		BaseWork:     profile.WorkMinutes,
+		BaseBreak:    profile.BreakMinutes,
+		WorkMinutes:  profile.WorkMinutes,
+		BreakMinutes: profile.BreakMinutes,
+	}
+
+	recent, scope := recentRecords(profile.Name, records, now.AddDate(0, 0, -bounds.Window))
+	signals := measure(recent, now)
+	decision.Signals = signals
+
+	if signals.Samples < adaptiveMinSamples {
+		decision.Reasons = append(decision.Reasons, fmt.Sprintf(
+			"Only %d focus sessions in the last %d days (need %d), keeping %d/%d min",
+			signals.Samples, bounds.Window, adaptiveMinSamples, profile.WorkMinutes, profile.BreakMinutes))
+		return decision
+	}
+	decision.Reasons = append(decision.Reasons, fmt.Sprintf(
+		"Based on %d focus sessions of %s in the last %d days: %.0f%% completed, %.1f min paused on average",
+		signals.Samples, scope, bounds.Window, signals.CompletionRate*100, signals.AvgPauseMin))
+
+	stats := analyzeSessions(recent, rules)
+	breakSuggested := false
+	for _, suggestion := range suggestionsFor(stats, now) {
+		if suggestion.Confidence < adaptiveMinConfidence {
+			continue
+		}
+		switch {
+		case suggestion.Type == "work_time" && suggestion.WorkTime > 0:
+			decision.BaseWork = suggestion.WorkTime
+			decision.Confidence = math.Max(decision.Confidence, suggestion.Confidence)
+			decision.Reasons = append(decision.Reasons, fmt.Sprintf(
+				"%s: work %d min (confidence %.0f%%)", suggestion.Message, suggestion.WorkTime, suggestion.Confidence*100))
+		case suggestion.Type == "break_time" && suggestion.BreakTime > 0:
+			decision.BaseBreak = suggestion.BreakTime
+			breakSuggested = true
+			decision.Confidence = math.Max(decision.Confidence, suggestion.Confidence)
+			decision.Reasons = append(decision.Reasons, fmt.Sprintf(
+				"%s: break %d min (confidence %.0f%%)", suggestion.Message, suggestion.BreakTime, suggestion.Confidence*100))
+		}
+	}
+
+	decision.BaseWork = clamp(decision.BaseWork, bounds.MinWork, bounds.MaxWork)
+	decision.BaseBreak = clamp(decision.BaseBreak, bounds.MinBreak, bounds.MaxBreak)
+	decision.WorkMinutes, decision.BreakMinutes = decision.BaseWork, decision.BaseBreak
+
+	// Interruptions: long pauses suggest the breaks are too short
+	if signals.AvgPauseMin >= 3 && !breakSuggested {
+		decision.BreakMinutes += 2
+		decision.Reasons = append(decision.Reasons, fmt.Sprintf(
+			"Sessions were paused for %.1f min on average, so the break grows by 2 min", signals.AvgPauseMin))
+	}
+
+	// Time of day: fewer completions around this hour call for shorter focus
+	if signals.HourSamples >= adaptiveMinSamples && signals.HourCompletion < signals.CompletionRate-0.15 {
+		decision.WorkMinutes -= 5
+		decision.Reasons = append(decision.Reasons, fmt.Sprintf(
+			"Around %02d:00 only %.0f%% of %d sessions were completed, so focus is 5 min shorter",
+			now.Hour(), signals.HourCompletion*100, signals.HourSamples))
+	}
+
+	decision.WorkMinutes = clampMinutes(decision.WorkMinutes, bounds.MinWork, bounds.MaxWork, "work", &decision.Reasons)
+	decision.BreakMinutes = clampMinutes(decision.BreakMinutes, bounds.MinBreak, bounds.MaxBreak, "break", &decision.Reasons)
+
+	if !decision.Changed() {
+		decision.Reasons = append(decision.Reasons, fmt.Sprintf(
+			"Keeping %d/%d min", decision.WorkMinutes, decision.BreakMinutes))
+	}
+	return decision
+}
+
+// recentRecords returns the intervals since since, limited to the profile's
+// own runs if there are enough of them, and describes the scope
+func recentRecords(profile string, records []history.Record, since time.Time) ([]history.Record, string) {
+	var all, own []history.Record
+	ownFocus := 0
+	for _, record := range records {
+		// Open flow intervals have no planned length to learn from
+		if record.StartTime.Before(since) || record.Name == timer.NameFlow {
+			continue
+		}
+		all = append(all, record)
+		if record.Profile == profile {
+			own = append(own, record)
+			if record.IsFocus() {
+				ownFocus++
+			}
+		}
+	}
+	if ownFocus >= adaptiveMinSamples {
+		return own, "profile '" + profile + "'"
+	}
+	return all, "all profiles"
+}
+
+// measure computes the adaptive signals of the focus intervals in records
+func measure(records []history.Record, now time.Time) AdaptiveSignals {
+	var signals AdaptiveSignals
+	var completed, hourCompleted int
+	var paused float64
+	for _, record := range records {
+		if !record.IsFocus() {
+			continue
+		}
+		signals.Samples++
+		paused += float64(record.PausedSeconds) / 60
+		if record.IsCompleted() {
+			completed++
+		}
+
+		diff := abs(record.StartTime.In(now.Location()).Hour() - now.Hour())
+		if diff <= 1 || diff == 23 {
+			signals.HourSamples++
+			if record.IsCompleted() {
+				hourCompleted++
+			}
+		}
+	}
+	if signals.Samples > 0 {
+		signals.CompletionRate = float64(completed) / float64(signals.Samples)
+		signals.AvgPauseMin = paused / float64(signals.Samples)
+	}
+	if signals.HourSamples > 0 {
+		signals.HourCompletion = float64(hourCompleted) / float64(signals.HourSamples)
+	}
+	return signals
+}
+
+// clamp keeps value within min and max
+func clamp(value, min, max int) int {
+	return int(math.Min(math.Max(float64(value), float64(min)), float64(max)))
+}
+
+// clampMinutes keeps value within min and max, noting when it had to
+func clampMinutes(value, min, max int, what string, reasons *[]string) int {
+	switch {
+	case value < min:
+		*reasons = append(*reasons, fmt.Sprintf("Raised %s to the %d min minimum", what, min))
+		return min
+	case value > max:
+		*reasons = append(*reasons, fmt.Sprintf("Capped %s at the %d min maximum", what, max))
+		return max
+	}
+	return value
+}
+
+// Adapt returns the adaptive profile with the lengths for a run starting
+// now and the decision behind them. Nothing is saved until SaveAdaptive.
+func Adapt(profile Profile) (Profile, AdaptiveDecision, error) {
+	if profile.Adaptive == nil {
+		return profile, AdaptiveDecision{}, fmt.Errorf("%w profile: '%s' is not adaptive", ErrInvalid, profile.Name)
+	}
+	decision, err := DecideAdaptive(profile, time.Now())
+	if err != nil {
+		return profile, AdaptiveDecision{}, err
+	}
+	profile.WorkMinutes, profile.BreakMinutes = decision.WorkMinutes, decision.BreakMinutes
+	return profile, decision, nil
+}
+
+// SaveAdaptive stores the base lengths of a decision in its profile, so they
+// are the starting point of the next one, and adds it to the explanation
+// log. The adjustments for pauses and time of day are not saved, so they
+// do not add up over runs. Call it once the run using the decision has
+// started.
+func SaveAdaptive(decision AdaptiveDecision) error {
+	return store.Update(func(tx *store.Tx) error {
+		profiles, err := getProfiles(tx)
+		if err != nil {
+			return err
+		}
+		if i := findProfile(profiles, decision.Profile); i >= 0 {
+			profiles.Profiles[i].WorkMinutes = decision.BaseWork
+			profiles.Profiles[i].BreakMinutes = decision.BaseBreak
+			if err := tx.Put(store.KeyProfiles, profiles); err != nil {
+				return err
+			}
+		}
+
+		var log []AdaptiveDecision
+		if _, err := tx.Get(store.KeyAdaptive, &log); err != nil {
+			return err
+		}
+		log = append(log, decision)
+		if len(log) > MaxAdaptiveLog {
+			log = log[len(log)-MaxAdaptiveLog:]
+		}
+		return tx.Put(store.KeyAdaptive, log)
+	})
+}
+
+// LoadAdaptiveLog returns the explanation log, oldest first, limited to the
+// profile called name unless it is empty
+func LoadAdaptiveLog(name string) ([]AdaptiveDecision, error) {
+	var log []AdaptiveDecision
+	err := store.View(func(tx *store.Tx) error {
+		_, err := tx.Get(store.KeyAdaptive, &log)
+		return err
+	})
+	if err != nil || name == "" {
+		return log, err
+	}
+
+	var filtered []AdaptiveDecision
+	for _, decision := range log {
+		if decision.Profile == name {
+			filtered = append(filtered, decision)
+		}
+	}
+	return filtered, nil
+}
@@ -0,0 +1,102 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Flack74/pom/clock"
+	"github.com/Flack74/pom/history"
+)
+
+// adaptiveHistory returns ten 25 minute focus sessions of profile, paused
+// 4 minutes each: six in the afternoon, all completed, and four in the
+// morning, of which only one was completed
+func adaptiveHistory(profile string, now time.Time) []history.Record {
+	var records []history.Record
+	for i := 0; i < 10; i++ {
+		day := now.AddDate(0, 0, -(i + 1))
+		start := time.Date(day.Year(), day.Month(), day.Day(), 15, 0, 0, 0, time.UTC)
+		status := history.StatusCompleted
+		if i < 4 {
+			start = time.Date(day.Year(), day.Month(), day.Day(), 9, 0, 0, 0, time.UTC)
+			if i > 0 {
+				status = history.StatusInterrupted
+			}
+		}
+		records = append(records, history.Record{
+			Kind:           history.KindFocus,
+			Index:          1,
+			StartTime:      start,
+			EndTime:        start.Add(29 * time.Minute),
+			PlannedSeconds: 25 * 60,
+			ActualSeconds:  25 * 60,
+			PausedSeconds:  4 * 60,
+			Status:         status,
+			Profile:        profile,
+		})
+	}
+	return records
+}
+
+func TestAdaptiveDecisionIsStable(t *testing.T) {
+	isolateStore(t)
+
+	rules := clock.Rules{Location: time.UTC}
+	now := time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+	profile := Profile{Name: "steady", WorkMinutes: 25, BreakMinutes: 5, NumSessions: 4, Adaptive: &Adaptive{}}
+	if err := AddProfile(profile); err != nil {
+		t.Fatalf("AddProfile failed: %v", err)
+	}
+	records := adaptiveHistory(profile.Name, now)
+
+	var decisions []AdaptiveDecision
+	for run := 0; run < 3; run++ {
+		current, err := GetProfile(profile.Name)
+		if err != nil {
+			t.Fatalf("GetProfile failed: %v", err)
+		}
+		decision := decideAdaptive(current, records, rules, now)
+		if err := SaveAdaptive(decision); err != nil {
+			t.Fatalf("SaveAdaptive failed: %v", err)
+		}
+		decisions = append(decisions, decision)
+	}
+
+	// Long pauses add 2 minutes to the break and the weak morning takes 5
+	// off the focus, for this run only
+	first := decisions[0]
+	if first.WorkMinutes != 20 || first.BreakMinutes != 7 {
+		t.Errorf("first run uses %d/%d min, want 20/7", first.WorkMinutes, first.BreakMinutes)
+	}
+	if first.BaseWork != 25 || first.BaseBreak != 5 {
+		t.Errorf("first run has base %d/%d min, want 25/5", first.BaseWork, first.BaseBreak)
+	}
+	for i, decision := range decisions[1:] {
+		if decision.WorkMinutes != first.WorkMinutes || decision.BreakMinutes != first.BreakMinutes ||
+			decision.BaseWork != first.BaseWork || decision.BaseBreak != first.BaseBreak {
+			t.Errorf("run %d uses %d/%d min from base %d/%d, want the same as the first run, %d/%d from %d/%d",
+				i+2, decision.WorkMinutes, decision.BreakMinutes, decision.BaseWork, decision.BaseBreak,
+				first.WorkMinutes, first.BreakMinutes, first.BaseWork, first.BaseBreak)
+		}
+	}
+
+	saved, err := GetProfile(profile.Name)
+	if err != nil {
+		t.Fatalf("GetProfile failed: %v", err)
+	}
+	if saved.WorkMinutes != 25 || saved.BreakMinutes != 5 {
+		t.Errorf("profile saved as %d/%d min, want the base 25/5", saved.WorkMinutes, saved.BreakMinutes)
+	}
+}
+
+func TestAdaptiveAfternoonKeepsFocus(t *testing.T) {
+	rules := clock.Rules{Location: time.UTC}
+	now := time.Date(2026, 3, 10, 15, 0, 0, 0, time.UTC)
+	profile := Profile{Name: "steady", WorkMinutes: 25, BreakMinutes: 5, NumSessions: 4, Adaptive: &Adaptive{}}
+
+	// The morning adjustment must not follow the profile into the afternoon
+	decision := decideAdaptive(profile, adaptiveHistory(profile.Name, now), rules, now)
+	if decision.WorkMinutes != 25 || decision.BreakMinutes != 7 {
+		t.Errorf("afternoon run uses %d/%d min, want 25/7", decision.WorkMinutes, decision.BreakMinutes)
+	}
+}
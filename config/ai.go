@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"math"
 	"time"
+
+	"github.com/Flack74/pom/clock"
 )
 
 type SessionStats struct {
@@ -28,33 +30,55 @@ func AnalyzePerformance() (SessionStats, error) {
 	if err != nil {
 		return SessionStats{}, err
 	}
+	return analyzeSessions(sessions, clock.Load()), nil
+}
 
+// analyzeSessions computes performance statistics over the given intervals,
+// with times of day in the rules' timezone
+func analyzeSessions(sessions []SessionData, rules clock.Rules) SessionStats {
 	if len(sessions) == 0 {
 		return SessionStats{
 			AverageWorkTime:   25,
 			AverageBreakTime:  5,
 			CompletionRate:    0,
 			ProductivityScore: 0,
-		}, nil
+		}
 	}
 
 	var totalWork, totalBreak float64
-	var completed int
+	var focusCount, breakCount, completed int
 	hourMap := make(map[int]int)
 
 	for _, session := range sessions {
-		totalWork += float64(session.WorkMinutes)
-		totalBreak += float64(session.BreakMinutes)
-		if session.Completed {
+		if !session.IsFocus() {
+			totalBreak += session.PlannedMinutes()
+			breakCount++
+			continue
+		}
+		totalWork += session.PlannedMinutes()
+		focusCount++
+		if session.IsCompleted() {
 			completed++
 		}
-		hourMap[session.Date.Hour()]++
+		hourMap[rules.In(session.StartTime).Hour()]++
+	}
+
+	if focusCount == 0 {
+		return SessionStats{
+			AverageWorkTime:   25,
+			AverageBreakTime:  5,
+			CompletionRate:    0,
+			ProductivityScore: 0,
+		}
 	}
 
 	stats := SessionStats{
-		AverageWorkTime:  totalWork / float64(len(sessions)),
-		AverageBreakTime: totalBreak / float64(len(sessions)),
-		CompletionRate:   float64(completed) / float64(len(sessions)),
+		AverageWorkTime:  totalWork / float64(focusCount),
+		AverageBreakTime: 5,
+		CompletionRate:   float64(completed) / float64(focusCount),
+	}
+	if breakCount > 0 {
+		stats.AverageBreakTime = totalBreak / float64(breakCount)
 	}
 
 	// Find preferred time slots
@@ -68,10 +92,10 @@ func AnalyzePerformance() (SessionStats, error) {
 		}
 	}
 
-	// Calculate productivity score
-	stats.ProductivityScore = stats.CompletionRate * 100
+	// The productivity score is the focus score, see FocusScore
+	stats.ProductivityScore = scoreRecords(sessions, rules).Score
 
-	return stats, nil
+	return stats
 }
 
 func GenerateSuggestions() ([]Suggestion, error) {
@@ -79,7 +103,17 @@ func GenerateSuggestions() ([]Suggestion, error) {
 	if err != nil {
 		return nil, err
 	}
+	return suggestionsFor(stats, clock.Load().Now()), nil
+}
+
+// SuggestFor returns the suggestions for the given intervals at time now
+func SuggestFor(sessions []SessionData, now time.Time) []Suggestion {
+	rules := clock.Load()
+	return suggestionsFor(analyzeSessions(sessions, rules), rules.In(now))
+}
 
+// suggestionsFor applies the suggestion rules to stats at time now
+func suggestionsFor(stats SessionStats, now time.Time) []Suggestion {
 	var suggestions []Suggestion
 
 	// Work time suggestions
@@ -110,7 +144,7 @@ func GenerateSuggestions() ([]Suggestion, error) {
 	}
 
 	// Time-based suggestions
-	currentHour := time.Now().Hour()
+	currentHour := now.Hour()
 	isPreferredTime := false
 	for _, hour := range stats.PreferredTimeSlots {
 		if abs(currentHour-hour) <= 1 {
@@ -136,7 +170,7 @@ func GenerateSuggestions() ([]Suggestion, error) {
 		})
 	}
 
-	return suggestions, nil
+	return suggestions
 }
 
 func abs(x int) int {
@@ -4,55 +4,25 @@ import (
 	"fmt"
 	"strings"
 	"time"
+
+	"github.com/Flack74/pom/clock"
+	"github.com/Flack74/pom/history"
 )
 
 type CalendarDay struct {
-	Date     time.Time
-	Sessions int
-	Minutes  int
-	Level    int // 0-4 intensity level for heatmap
+	Date     time.Time `json:"date"`
+	Sessions int       `json:"sessions"`
+	Minutes  int       `json:"minutes"`
+	Level    int       `json:"level"` // 0-4 intensity level for heatmap
 }
 
 func GenerateCalendarView(months int) (string, error) {
-	sessions, err := loadSessionHistory()
+	rules := clock.Load()
+	activityMap, err := calendarActivity(rules)
 	if err != nil {
 		return "", err
 	}
 
-	// Create map of date -> activity
-	activityMap := make(map[string]CalendarDay)
-	
-	// Process sessions
-	for _, session := range sessions {
-		dateKey := session.Date.Format("2006-01-02")
-		if day, exists := activityMap[dateKey]; exists {
-			day.Sessions += session.Sessions
-			day.Minutes += session.WorkMinutes * session.Sessions
-			activityMap[dateKey] = day
-		} else {
-			activityMap[dateKey] = CalendarDay{
-				Date:     session.Date,
-				Sessions: session.Sessions,
-				Minutes:  session.WorkMinutes * session.Sessions,
-			}
-		}
-	}
-
-	// Calculate intensity levels
-	maxMinutes := 0
-	for _, day := range activityMap {
-		if day.Minutes > maxMinutes {
-			maxMinutes = day.Minutes
-		}
-	}
-
-	for dateKey, day := range activityMap {
-		if maxMinutes > 0 {
-			day.Level = int((float64(day.Minutes) / float64(maxMinutes)) * 4)
-		}
-		activityMap[dateKey] = day
-	}
-
 	// Generate calendar
 	var result strings.Builder
 	result.WriteString("📅 Focus Session Calendar (Last " + fmt.Sprintf("%d", months) + " months)\n\n")
@@ -65,7 +35,7 @@ func GenerateCalendarView(months int) (string, error) {
 	result.WriteString(" More\n\n")
 
 	// Generate monthly view
-	now := time.Now()
+	now := rules.Today()
 	for m := months - 1; m >= 0; m-- {
 		monthStart := now.AddDate(0, -m, 0)
 		monthStart = time.Date(monthStart.Year(), monthStart.Month(), 1, 0, 0, 0, 0, monthStart.Location())
@@ -118,6 +88,84 @@ func GenerateCalendarView(months int) (string, error) {
 	return result.String(), nil
 }
 
+// GetCalendarDays returns the focus activity of every day in the last months
+// calendar months, oldest first
+func GetCalendarDays(months int) ([]CalendarDay, error) {
+	rules := clock.Load()
+	activityMap, err := calendarActivity(rules)
+	if err != nil {
+		return nil, err
+	}
+
+	now := rules.Today()
+	start := now.AddDate(0, -(months - 1), 0)
+	start = time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, now.Location())
+
+	days := []CalendarDay{}
+	for date := start; !date.After(now); date = date.AddDate(0, 0, 1) {
+		day, exists := activityMap[date.Format("2006-01-02")]
+		if !exists {
+			day = CalendarDay{}
+		}
+		day.Date = date
+		days = append(days, day)
+	}
+
+	return days, nil
+}
+
+// calendarActivity maps each date under the day rules to its focus activity,
+// with intensity levels relative to the busiest day
+func calendarActivity(rules clock.Rules) (map[string]CalendarDay, error) {
+	sessions, err := loadSessionHistory()
+	if err != nil {
+		return nil, err
+	}
+
+	// Create map of date -> activity
+	activityMap := make(map[string]CalendarDay)
+	
+	// Process focus intervals
+	for _, session := range sessions {
+		if !session.IsFocus() {
+			continue
+		}
+		dateKey := rules.Key(session.EndTime)
+		day, exists := activityMap[dateKey]
+		if !exists {
+			day = CalendarDay{Date: rules.Day(session.EndTime)}
+		}
+		if session.IsCompleted() {
+			day.Sessions++
+		}
+		day.Minutes += session.ActualSeconds
+		activityMap[dateKey] = day
+	}
+
+	// Convert accumulated seconds to minutes
+	for dateKey, day := range activityMap {
+		day.Minutes /= 60
+		activityMap[dateKey] = day
+	}
+
+	// Calculate intensity levels
+	maxMinutes := 0
+	for _, day := range activityMap {
+		if day.Minutes > maxMinutes {
+			maxMinutes = day.Minutes
+		}
+	}
+
+	for dateKey, day := range activityMap {
+		if maxMinutes > 0 {
+			day.Level = int((float64(day.Minutes) / float64(maxMinutes)) * 4)
+		}
+		activityMap[dateKey] = day
+	}
+
+	return activityMap, nil
+}
+
 func getHeatmapChar(level int) string {
 	chars := []string{"⬜", "🟩", "🟨", "🟧", "🟥"}
 	if level < 0 || level >= len(chars) {
@@ -126,22 +174,13 @@ func getHeatmapChar(level int) string {
 	return chars[level]
 }
 
+// GetTodayStats returns today's completed sessions and focus minutes
 func GetTodayStats() (int, int, error) {
-	sessions, err := loadSessionHistory()
+	records, err := history.Load()
 	if err != nil {
 		return 0, 0, err
 	}
 
-	today := time.Now().Format("2006-01-02")
-	totalSessions := 0
-	totalMinutes := 0
-
-	for _, session := range sessions {
-		if session.Date.Format("2006-01-02") == today {
-			totalSessions += session.Sessions
-			totalMinutes += session.WorkMinutes * session.Sessions
-		}
-	}
-
-	return totalSessions, totalMinutes, nil
-}
\ No newline at end of file
+	sessions, minutes := history.DayTotals(records, clock.Load(), time.Now())
+	return sessions, minutes, nil
+}
@@ -0,0 +1,346 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Flack74/pom/clock"
+	"github.com/Flack74/pom/history"
+)
+
+// Defaults for the coaching provider
+const (
+	DefaultCoachTimeout = 30 // Seconds
+	MaxCoachTimeout     = 300
+	CoachDays           = 7 // Days summarized for the weekly narrative
+
+	// CoachAPIKeyEnv names the environment variable holding the endpoint's
+	// API key, so it never lands in the datastore
+	CoachAPIKeyEnv = "POM_COACH_API_KEY"
+)
+
+// Coaching provider names
+const (
+	ProviderRules  = "rules"
+	ProviderOpenAI = "openai"
+)
+
+// CoachConfig selects an OpenAI-compatible chat completions endpoint, such
+// as a local llama.cpp or Ollama server, for coaching narratives
+type CoachConfig struct {
+	Endpoint       string `json:"endpoint,omitempty"` // Base URL, e.g. http://localhost:11434/v1
+	Model          string `json:"model,omitempty"`
+	TimeoutSeconds int    `json:"timeout_seconds,omitempty"`
+}
+
+// Timeout returns the request timeout, falling back to the default
+func (c CoachConfig) Timeout() time.Duration {
+	if c.TimeoutSeconds > 0 {
+		return time.Duration(c.TimeoutSeconds) * time.Second
+	}
+	return DefaultCoachTimeout * time.Second
+}
+
+// CoachSummary is the anonymised view of recent sessions a coaching provider
+// sees. It holds only counts, rates and times of day: no task titles, tags,
+// profile names or timestamps.
+type CoachSummary struct {
+	Days              int                `json:"days"`
+	FocusSessions     int                `json:"focus_sessions"`
+	CompletedSessions int                `json:"completed_sessions"`
+	CompletionRate    float64            `json:"completion_rate"`
+	FocusMinutes      float64            `json:"focus_minutes"`
+	AverageWorkTime   float64            `json:"average_work_minutes"`
+	AverageBreakTime  float64            `json:"average_break_minutes"`
+	PreferredHours    []int              `json:"preferred_hours,omitempty"`
+	BestHour          int                `json:"best_hour"` // -1 if unknown
+	BestWeekday       string             `json:"best_weekday,omitempty"`
+	WeekdayCompletion map[string]float64 `json:"weekday_completion,omitempty"` // Weekdays with sessions only
+	FocusScore        FocusScore         `json:"focus_score"`
+	Trend             string             `json:"trend"`
+	CutShort          int                `json:"cut_short_sessions"`
+	AvgMinutesIn      float64            `json:"avg_minutes_before_cut_short"`
+	PausedSessions    int                `json:"paused_sessions"`
+	AvgPauseMinutes   float64            `json:"avg_pause_minutes"`
+	EstimatedTasks    int                `json:"estimated_tasks"`          // Completed tasks with an estimate
+	EstimateRatio     float64            `json:"estimate_ratio,omitempty"` // Actual over estimated sessions
+}
+
+// CoachProvider turns a summary of recent sessions into a coaching narrative
+type CoachProvider interface {
+	Name() string
+	Coach(ctx context.Context, summary CoachSummary) (string, error)
+}
+
+// Coaching is a coaching narrative and where it came from
+type Coaching struct {
+	Provider  string       `json:"provider"`
+	Narrative string       `json:"narrative"`
+	Fallback  bool         `json:"fallback"`        // The configured endpoint failed and the rules were used
+	Error     string       `json:"error,omitempty"` // Why the endpoint failed
+	Summary   CoachSummary `json:"summary"`         // What the provider was given
+}
+
+// BuildCoachSummary summarizes the last days days of the session log
+func BuildCoachSummary(days int) (CoachSummary, error) {
+	records, err := history.Load()
+	if err != nil {
+		return CoachSummary{}, err
+	}
+	tasks, err := LoadTasks()
+	if err != nil {
+		return CoachSummary{}, err
+	}
+	return buildCoachSummary(records, tasks.Tasks, days, clock.Load(), time.Now()), nil
+}
+
+// buildCoachSummary summarizes records, and the estimates of tasks completed,
+// over the days days up to now
+func buildCoachSummary(records []history.Record, tasks []Task, days int, rules clock.Rules, now time.Time) CoachSummary {
+	insights := analyzeInsights(records, tasks, days, rules, now)
+	var recent []history.Record
+	for _, record := range records {
+		if !record.StartTime.Before(insights.Since) && !record.StartTime.After(now) {
+			recent = append(recent, record)
+		}
+	}
+	stats := analyzeSessions(recent, rules)
+
+	summary := CoachSummary{
+		Days:              insights.Days,
+		FocusSessions:     insights.Focus.Sessions,
+		CompletedSessions: insights.Focus.Completed,
+		CompletionRate:    round2(insights.Focus.CompletionRate),
+		FocusMinutes:      round1(insights.Focus.Minutes),
+		AverageWorkTime:   round1(stats.AverageWorkTime),
+		AverageBreakTime:  round1(stats.AverageBreakTime),
+		PreferredHours:    stats.PreferredTimeSlots,
+		BestHour:          insights.BestHour,
+		BestWeekday:       insights.BestWeekday,
+		WeekdayCompletion: map[string]float64{},
+		FocusScore:        insights.Score,
+		Trend:             insights.Trend.Summary,
+		CutShort:          insights.Focus.Interrupted,
+		AvgMinutesIn:      round1(insights.Interruptions.AvgMinutesIn),
+		PausedSessions:    insights.Interruptions.PausedSessions,
+		AvgPauseMinutes:   round1(insights.Interruptions.AvgPauseMinutes),
+		EstimatedTasks:    insights.Estimates.Tasks,
+		EstimateRatio:     insights.Estimates.Ratio,
+	}
+	sort.Ints(summary.PreferredHours)
+	for _, day := range insights.Weekdays {
+		if day.Sessions > 0 {
+			summary.WeekdayCompletion[day.Label] = round2(day.CompletionRate)
+		}
+	}
+	return summary
+}
+
+// NewCoachProvider returns the provider cfg selects: the configured endpoint,
+// or the rule engine if none is set or privacy mode is on
+func NewCoachProvider(cfg Config) CoachProvider {
+	if cfg.PrivacyMode || cfg.Coach.Endpoint == "" {
+		return RuleCoach{}
+	}
+	return &OpenAICoach{
+		Endpoint: cfg.Coach.Endpoint,
+		Model:    cfg.Coach.Model,
+		APIKey:   os.Getenv(CoachAPIKeyEnv),
+		Client:   &http.Client{Timeout: cfg.Coach.Timeout()},
+	}
+}
+
+// WeeklyCoaching writes a coaching narrative for the last week with the
+// configured provider, falling back to the rule engine if it fails
+func WeeklyCoaching(ctx context.Context) (Coaching, error) {
+	cfg, err := LoadConfig()
+	if err != nil {
+		return Coaching{}, err
+	}
+	summary, err := BuildCoachSummary(CoachDays)
+	if err != nil {
+		return Coaching{}, err
+	}
+	return coach(ctx, NewCoachProvider(cfg), summary, cfg.Coach.Timeout()), nil
+}
+
+// coach runs provider on summary within timeout, falling back to the rule
+// engine if it fails
+func coach(ctx context.Context, provider CoachProvider, summary CoachSummary, timeout time.Duration) Coaching {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	coaching := Coaching{Provider: provider.Name(), Summary: summary}
+	narrative, err := provider.Coach(ctx, summary)
+	if err == nil && strings.TrimSpace(narrative) != "" {
+		coaching.Narrative = strings.TrimSpace(narrative)
+		return coaching
+	}
+	if err == nil {
+		err = fmt.Errorf("empty response")
+	}
+
+	fallback := RuleCoach{}
+	coaching.Provider = fallback.Name()
+	coaching.Fallback = true
+	coaching.Error = err.Error()
+	coaching.Narrative, _ = fallback.Coach(context.Background(), summary)
+	return coaching
+}
+
+// RuleCoach writes the narrative from the built-in suggestion rules. It never
+// sends data anywhere.
+type RuleCoach struct{}
+
+// Name returns ProviderRules
+func (RuleCoach) Name() string {
+	return ProviderRules
+}
+
+// Coach writes a narrative from the summary and the suggestion rules
+func (RuleCoach) Coach(ctx context.Context, summary CoachSummary) (string, error) {
+	if summary.FocusSessions == 0 {
+		return fmt.Sprintf("No focus sessions in the last %d days. Start with one short session today; "+
+			"a 15 minute block is enough to get going.", summary.Days), nil
+	}
+
+	var lines []string
+	lines = append(lines, fmt.Sprintf("In the last %d days you focused for %.0f minutes over %d sessions "+
+		"and finished %.0f%% of them. Your focus score is %.0f/100.",
+		summary.Days, summary.FocusMinutes, summary.FocusSessions, summary.CompletionRate*100, summary.FocusScore.Score))
+	if summary.Trend != "" {
+		lines = append(lines, summary.Trend+".")
+	}
+	if summary.BestWeekday != "" {
+		lines = append(lines, fmt.Sprintf("You finish the most sessions on %s.", summary.BestWeekday))
+	}
+	if summary.CutShort > 0 {
+		lines = append(lines, fmt.Sprintf("%d sessions were cut short, on average after %.0f minutes.",
+			summary.CutShort, summary.AvgMinutesIn))
+	}
+	if summary.EstimatedTasks > 0 && math.Abs(summary.EstimateRatio-1) >= 0.2 {
+		lines = append(lines, fmt.Sprintf("Your tasks took %.0f%% of the sessions you estimated; "+
+			"size new estimates with that in mind.", summary.EstimateRatio*100))
+	}
+
+	stats := SessionStats{
+		AverageWorkTime:    summary.AverageWorkTime,
+		AverageBreakTime:   summary.AverageBreakTime,
+		CompletionRate:     summary.CompletionRate,
+		PreferredTimeSlots: summary.PreferredHours,
+		ProductivityScore:  summary.FocusScore.Score,
+	}
+	for _, suggestion := range suggestionsFor(stats, clock.Load().Now()) {
+		lines = append(lines, suggestion.Message+".")
+	}
+	return strings.Join(lines, " "), nil
+}
+
+// OpenAICoach asks an OpenAI-compatible chat completions endpoint for the
+// narrative. Endpoint is the API base URL; the request goes to
+// Endpoint + "/chat/completions".
+type OpenAICoach struct {
+	Endpoint string
+	Model    string
+	APIKey   string       // Sent as a bearer token if set
+	Client   *http.Client // http.DefaultClient if nil
+}
+
+// coachPrompt asks for a short weekly narrative
+const coachPrompt = "You are a concise, encouraging productivity coach for a Pomodoro timer user. " +
+	"You get a JSON summary of their focus sessions over the last week. Write a short weekly coaching " +
+	"note of at most 150 words in plain text: what went well, one pattern to notice, and one or two " +
+	"concrete things to try next week, such as session lengths or times of day. Do not invent numbers."
+
+// chatMessage is a message of a chat completions request or response
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// Name returns ProviderOpenAI
+func (c *OpenAICoach) Name() string {
+	return ProviderOpenAI
+}
+
+// Coach sends the summary to the endpoint and returns the reply
+func (c *OpenAICoach) Coach(ctx context.Context, summary CoachSummary) (string, error) {
+	data, err := json.Marshal(summary)
+	if err != nil {
+		return "", err
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"model": c.Model,
+		"messages": []chatMessage{
+			{Role: "system", Content: coachPrompt},
+			{Role: "user", Content: string(data)},
+		},
+		"temperature": 0.7,
+		"stream":      false,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	url := strings.TrimSuffix(c.Endpoint, "/") + "/chat/completions"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("invalid coach endpoint: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.APIKey)
+	}
+
+	client := c.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("coach request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return "", fmt.Errorf("coach endpoint returned %s: %s", resp.Status, strings.TrimSpace(string(message)))
+	}
+
+	var reply struct {
+		Choices []struct {
+			Message chatMessage `json:"message"`
+		} `json:"choices"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&reply); err != nil {
+		return "", fmt.Errorf("invalid coach response: %v", err)
+	}
+	if len(reply.Choices) == 0 {
+		return "", fmt.Errorf("coach response has no choices")
+	}
+	return reply.Choices[0].Message.Content, nil
+}
+
+// ValidateCoachConfig checks the coaching settings
+func ValidateCoachConfig(c CoachConfig) error {
+	if c.Endpoint != "" && !strings.HasPrefix(c.Endpoint, "http://") && !strings.HasPrefix(c.Endpoint, "https://") {
+		return fmt.Errorf("%w coach endpoint: '%s' must start with http:// or https://", ErrInvalid, c.Endpoint)
+	}
+	if c.TimeoutSeconds < 0 || c.TimeoutSeconds > MaxCoachTimeout {
+		return fmt.Errorf("%w coach timeout: must be between 1 and %d seconds, got %d", ErrInvalid, MaxCoachTimeout, c.TimeoutSeconds)
+	}
+	return nil
+}
+
+func round2(x float64) float64 {
+	return math.Round(x*100) / 100
+}
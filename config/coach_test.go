@@ -0,0 +1,169 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testSummary is a small week of sessions for the coaching tests
+var testSummary = CoachSummary{
+	Days:              7,
+	FocusSessions:     10,
+	CompletedSessions: 8,
+	CompletionRate:    0.8,
+	FocusMinutes:      250,
+	AverageWorkTime:   25,
+	AverageBreakTime:  5,
+	BestHour:          -1,
+}
+
+// isolateStore points the datastore at an empty home directory, as the rule
+// engine reads the clock settings
+func isolateStore(t *testing.T) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+}
+
+// ruleNarrative returns what the rule engine writes for summary
+func ruleNarrative(t *testing.T, summary CoachSummary) string {
+	t.Helper()
+	narrative, err := RuleCoach{}.Coach(context.Background(), summary)
+	if err != nil {
+		t.Fatalf("rule coach failed: %v", err)
+	}
+	return narrative
+}
+
+func TestOpenAICoachCompletion(t *testing.T) {
+	isolateStore(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v1/chat/completions" {
+			t.Errorf("got %s %s, want POST /v1/chat/completions", r.Method, r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+			t.Errorf("Authorization = %q, want bearer token", got)
+		}
+
+		var request struct {
+			Model    string        `json:"model"`
+			Messages []chatMessage `json:"messages"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("invalid request body: %v", err)
+		}
+		if request.Model != "llama3" {
+			t.Errorf("model = %q, want llama3", request.Model)
+		}
+		if len(request.Messages) != 2 || !strings.Contains(request.Messages[1].Content, `"focus_sessions":10`) {
+			t.Errorf("messages do not carry the summary: %+v", request.Messages)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"  Great week, keep going.  "}}]}`))
+	}))
+	defer server.Close()
+
+	provider := &OpenAICoach{Endpoint: server.URL + "/v1/", Model: "llama3", APIKey: "secret", Client: server.Client()}
+	coaching := coach(context.Background(), provider, testSummary, 5*time.Second)
+
+	if coaching.Fallback || coaching.Error != "" {
+		t.Fatalf("unexpected fallback: %+v", coaching)
+	}
+	if coaching.Provider != ProviderOpenAI {
+		t.Errorf("provider = %q, want %q", coaching.Provider, ProviderOpenAI)
+	}
+	if coaching.Narrative != "Great week, keep going." {
+		t.Errorf("narrative = %q", coaching.Narrative)
+	}
+}
+
+func TestOpenAICoachErrorStatus(t *testing.T) {
+	isolateStore(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "model not loaded", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	provider := &OpenAICoach{Endpoint: server.URL, Client: server.Client()}
+	if _, err := provider.Coach(context.Background(), testSummary); err == nil || !strings.Contains(err.Error(), "503") {
+		t.Errorf("Coach error = %v, want the 503 status", err)
+	}
+
+	coaching := coach(context.Background(), provider, testSummary, 5*time.Second)
+	if !coaching.Fallback || coaching.Provider != ProviderRules {
+		t.Fatalf("want a fallback to the rules, got %+v", coaching)
+	}
+	if !strings.Contains(coaching.Error, "model not loaded") {
+		t.Errorf("error = %q, want the endpoint's message", coaching.Error)
+	}
+	if coaching.Narrative != ruleNarrative(t, testSummary) {
+		t.Errorf("narrative = %q, want the rule engine's", coaching.Narrative)
+	}
+}
+
+func TestOpenAICoachTimeout(t *testing.T) {
+	isolateStore(t)
+
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	provider := &OpenAICoach{Endpoint: server.URL, Client: server.Client()}
+	start := time.Now()
+	coaching := coach(context.Background(), provider, testSummary, 100*time.Millisecond)
+
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("coach took %v, want it to give up after the timeout", elapsed)
+	}
+	if !coaching.Fallback || coaching.Provider != ProviderRules {
+		t.Fatalf("want a fallback to the rules, got %+v", coaching)
+	}
+	if !strings.Contains(coaching.Error, "deadline exceeded") {
+		t.Errorf("error = %q, want a timeout", coaching.Error)
+	}
+	if coaching.Narrative != ruleNarrative(t, testSummary) {
+		t.Errorf("narrative = %q, want the rule engine's", coaching.Narrative)
+	}
+}
+
+func TestPrivacyModeUsesRules(t *testing.T) {
+	isolateStore(t)
+
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"sent anyway"}}]}`))
+	}))
+	defer server.Close()
+
+	cfg := Config{PrivacyMode: true, Coach: CoachConfig{Endpoint: server.URL, Model: "llama3"}}
+	provider := NewCoachProvider(cfg)
+	if _, ok := provider.(RuleCoach); !ok {
+		t.Fatalf("provider = %T, want RuleCoach", provider)
+	}
+
+	coaching := coach(context.Background(), provider, testSummary, cfg.Coach.Timeout())
+	if coaching.Fallback || coaching.Provider != ProviderRules {
+		t.Errorf("want the rules without a fallback, got %+v", coaching)
+	}
+	if coaching.Narrative != ruleNarrative(t, testSummary) {
+		t.Errorf("narrative = %q, want the rule engine's", coaching.Narrative)
+	}
+	if n := atomic.LoadInt32(&requests); n != 0 {
+		t.Errorf("endpoint got %d requests in privacy mode, want none", n)
+	}
+}
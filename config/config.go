@@ -1,9 +1,27 @@
 package config
 
 import (
-	"encoding/json"
-	"os"
-	"path/filepath"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Flack74/pom/clock"
+	"github.com/Flack74/pom/store"
+	"github.com/Flack74/pom/timer"
+)
+
+var (
+	// ErrNotFound is wrapped by errors for profiles, tasks and plugins that do not exist
+	ErrNotFound = errors.New("not found")
+
+	// ErrExists is wrapped by errors for names that are already taken
+	ErrExists = errors.New("already exists")
+
+	// ErrInUse is wrapped by errors for deleting something that is still in use
+	ErrInUse = errors.New("is in use")
+
+	// ErrInvalid is wrapped by validation errors
+	ErrInvalid = errors.New("invalid")
 )
 
 type Config struct {
@@ -14,6 +32,28 @@ type Config struct {
 	PrivacyMode  bool   `json:"privacy_mode"`
 	CloudSync    bool   `json:"cloud_sync"`
 	CloudProvider string `json:"cloud_provider"`
+	FlowRatio    float64 `json:"flow_ratio,omitempty"` // Focus minutes per break minute in flow mode
+	Coach        CoachConfig `json:"coach,omitempty"`  // Endpoint for coaching narratives
+	clock.Settings                                   // Timezone and start of day for daily stats
+}
+
+// DefaultFlowRatio gives one break minute per five minutes of flow
+const DefaultFlowRatio = 5.0
+
+// BreakRatio returns the flow mode break ratio, falling back to the default
+func (c Config) BreakRatio() float64 {
+	if c.FlowRatio > 0 {
+		return c.FlowRatio
+	}
+	return DefaultFlowRatio
+}
+
+// ValidateDaySettings checks the timezone and start of day
+func ValidateDaySettings(settings clock.Settings) error {
+	if _, err := clock.Parse(settings); err != nil {
+		return fmt.Errorf("%w day settings: %v", ErrInvalid, err)
+	}
+	return nil
 }
 
 type Profile struct {
@@ -22,6 +62,17 @@ type Profile struct {
 	BreakMinutes int    `json:"break_minutes"`
 	NumSessions  int    `json:"num_sessions"`
 	Description  string `json:"description"`
+
+	LongBreakMinutes int `json:"long_break_minutes,omitempty"` // Length of the long break, 0 for none
+	LongBreakEvery   int `json:"long_break_every,omitempty"`   // Take the long break after every this many sessions
+
+	// Intervals is an explicit interval sequence. When set it replaces the
+	// work/break/sessions settings above.
+	Intervals []timer.Interval `json:"intervals,omitempty"`
+
+	// Adaptive makes the work and break lengths follow recent history. They
+	// are recalculated within these bounds before each run.
+	Adaptive *Adaptive `json:"adaptive,omitempty"`
 }
 
 type ProfileConfig struct {
@@ -39,140 +90,191 @@ var DefaultConfig = Config{
 }
 
 var DefaultProfiles = []Profile{
-	{Name: "default", WorkMinutes: 25, BreakMinutes: 5, NumSessions: 4, Description: "Standard Pomodoro"},
+	{Name: "default", WorkMinutes: 25, BreakMinutes: 5, NumSessions: 4, Description: "Standard Pomodoro",
+		LongBreakMinutes: 15, LongBreakEvery: 4},
 	{Name: "work", WorkMinutes: 45, BreakMinutes: 10, NumSessions: 3, Description: "Deep work sessions"},
 	{Name: "study", WorkMinutes: 30, BreakMinutes: 5, NumSessions: 4, Description: "Study sessions"},
 	{Name: "quick", WorkMinutes: 15, BreakMinutes: 3, NumSessions: 6, Description: "Quick tasks"},
+	{Name: "52-17", WorkMinutes: 52, BreakMinutes: 17, NumSessions: 3, Description: "52 minutes on, 17 off"},
+	{Name: "ultradian", WorkMinutes: 90, BreakMinutes: 20, NumSessions: 2, Description: "90-minute deep work cycles"},
+	{Name: "adaptive", WorkMinutes: 25, BreakMinutes: 5, NumSessions: 4, Description: "Lengths tuned to your recent sessions",
+		Adaptive: &Adaptive{}},
 }
 
-// getConfigPath returns the path to the config file
-func getConfigPath() (string, error) {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return "", err
-	}
-	return filepath.Join(homeDir, ".pomorc"), nil
-}
-
-// LoadConfig loads the configuration from the config file
+// LoadConfig loads the configuration from the datastore
 func LoadConfig() (Config, error) {
-	configPath, err := getConfigPath()
-	if err != nil {
-		return DefaultConfig, err
-	}
-
-	data, err := os.ReadFile(configPath)
+	config := DefaultConfig
+	err := store.View(func(tx *store.Tx) error {
+		_, err := tx.Get(store.KeyConfig, &config)
+		return err
+	})
 	if err != nil {
-		if os.IsNotExist(err) {
-			return DefaultConfig, nil
-		}
-		return DefaultConfig, err
-	}
-
-	var config Config
-	if err := json.Unmarshal(data, &config); err != nil {
 		return DefaultConfig, err
 	}
 
 	return config, nil
 }
 
-// SaveConfig saves the configuration to the config file
+// SaveConfig saves the configuration to the datastore
 func SaveConfig(config Config) error {
-	configPath, err := getConfigPath()
-	if err != nil {
-		return err
-	}
-
-	data, err := json.MarshalIndent(config, "", "  ")
-	if err != nil {
-		return err
-	}
-
-	return os.WriteFile(configPath, data, 0644)
+	return store.Update(func(tx *store.Tx) error {
+		return tx.Put(store.KeyConfig, config)
+	})
 }
 
 // GetConfigDir returns the path to the configuration directory
 func GetConfigDir() (string, error) {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return "", err
-	}
-
-	configDir := filepath.Join(homeDir, ".config", "pom")
-	if err := os.MkdirAll(configDir, 0755); err != nil {
-		return "", err
-	}
-
-	return configDir, nil
+	return store.Dir()
 }
 
 // Profile management functions
-func GetProfilePath() (string, error) {
-	configDir, err := GetConfigDir()
-	if err != nil {
-		return "", err
-	}
-	return filepath.Join(configDir, "profiles.json"), nil
+func LoadProfiles() (ProfileConfig, error) {
+	var profiles ProfileConfig
+	err := store.View(func(tx *store.Tx) error {
+		var err error
+		profiles, err = getProfiles(tx)
+		return err
+	})
+	return profiles, err
 }
 
-func LoadProfiles() (ProfileConfig, error) {
-	profilePath, err := GetProfilePath()
-	if err != nil {
-		return ProfileConfig{}, err
-	}
+func SaveProfiles(profiles ProfileConfig) error {
+	return store.Update(func(tx *store.Tx) error {
+		return tx.Put(store.KeyProfiles, profiles)
+	})
+}
 
-	data, err := os.ReadFile(profilePath)
+// GetProfile returns the profile called name
+func GetProfile(name string) (Profile, error) {
+	profiles, err := LoadProfiles()
 	if err != nil {
-		if os.IsNotExist(err) {
-			return ProfileConfig{Profiles: DefaultProfiles}, nil
-		}
-		return ProfileConfig{}, err
+		return Profile{}, err
 	}
 
-	var profiles ProfileConfig
-	if err := json.Unmarshal(data, &profiles); err != nil {
-		return ProfileConfig{}, err
+	for _, profile := range profiles.Profiles {
+		if profile.Name == name {
+			return profile, nil
+		}
 	}
 
-	return profiles, nil
+	return Profile{}, fmt.Errorf("profile '%s' %w", name, ErrNotFound)
 }
 
-func SaveProfiles(profiles ProfileConfig) error {
-	profilePath, err := GetProfilePath()
-	if err != nil {
+// AddProfile validates profile and adds it, refusing duplicate names
+func AddProfile(profile Profile) error {
+	syncIntervals(&profile)
+	if err := ValidateProfile(profile); err != nil {
 		return err
 	}
 
-	data, err := json.MarshalIndent(profiles, "", "  ")
-	if err != nil {
+	return store.Update(func(tx *store.Tx) error {
+		profiles, err := getProfiles(tx)
+		if err != nil {
+			return err
+		}
+
+		if findProfile(profiles, profile.Name) >= 0 {
+			return fmt.Errorf("profile '%s' %w", profile.Name, ErrExists)
+		}
+
+		profiles.Profiles = append(profiles.Profiles, profile)
+		return tx.Put(store.KeyProfiles, profiles)
+	})
+}
+
+// UpdateProfile validates profile and replaces the profile called name with
+// it. Renaming the active profile keeps it active.
+func UpdateProfile(name string, profile Profile) error {
+	syncIntervals(&profile)
+	if err := ValidateProfile(profile); err != nil {
 		return err
 	}
 
-	return os.WriteFile(profilePath, data, 0644)
+	return store.Update(func(tx *store.Tx) error {
+		profiles, err := getProfiles(tx)
+		if err != nil {
+			return err
+		}
+
+		i := findProfile(profiles, name)
+		if i < 0 || profiles.Profiles[i].Name != name {
+			return fmt.Errorf("profile '%s' %w", name, ErrNotFound)
+		}
+		if j := findProfile(profiles, profile.Name); j >= 0 && j != i {
+			return fmt.Errorf("profile '%s' %w", profile.Name, ErrExists)
+		}
+		profiles.Profiles[i] = profile
+
+		if profile.Name != name {
+			cfg := DefaultConfig
+			if _, err := tx.Get(store.KeyConfig, &cfg); err != nil {
+				return err
+			}
+			if cfg.CurrentProfile == name {
+				cfg.CurrentProfile = profile.Name
+				if err := tx.Put(store.KeyConfig, cfg); err != nil {
+					return err
+				}
+			}
+		}
+
+		return tx.Put(store.KeyProfiles, profiles)
+	})
 }
 
-func GetProfile(name string) (Profile, error) {
-	profiles, err := LoadProfiles()
-	if err != nil {
-		return Profile{}, err
-	}
+// DeleteProfile removes the profile called name. The active profile cannot
+// be deleted.
+func DeleteProfile(name string) error {
+	return store.Update(func(tx *store.Tx) error {
+		profiles, err := getProfiles(tx)
+		if err != nil {
+			return err
+		}
 
-	for _, profile := range profiles.Profiles {
+		cfg := DefaultConfig
+		if _, err := tx.Get(store.KeyConfig, &cfg); err != nil {
+			return err
+		}
+		if cfg.CurrentProfile == name {
+			return fmt.Errorf("profile '%s' %w: it is the active profile, switch to another one first", name, ErrInUse)
+		}
+
+		for i := range profiles.Profiles {
+			if profiles.Profiles[i].Name == name {
+				profiles.Profiles = append(profiles.Profiles[:i], profiles.Profiles[i+1:]...)
+				return tx.Put(store.KeyProfiles, profiles)
+			}
+		}
+
+		return fmt.Errorf("profile '%s' %w", name, ErrNotFound)
+	})
+}
+
+// findProfile returns the index of the profile whose name matches name
+// ignoring case, or -1. Names differing only in case count as duplicates.
+func findProfile(profiles ProfileConfig, name string) int {
+	for i, profile := range profiles.Profiles {
 		if profile.Name == name {
-			return profile, nil
+			return i
 		}
 	}
-
-	return Profile{}, os.ErrNotExist
+	for i, profile := range profiles.Profiles {
+		if strings.EqualFold(profile.Name, name) {
+			return i
+		}
+	}
+	return -1
 }
 
-func AddProfile(profile Profile) error {
-	profiles, err := LoadProfiles()
+// getProfiles reads the profile list inside a transaction, falling back to the defaults
+func getProfiles(tx *store.Tx) (ProfileConfig, error) {
+	var profiles ProfileConfig
+	found, err := tx.Get(store.KeyProfiles, &profiles)
 	if err != nil {
-		return err
+		return ProfileConfig{}, err
 	}
-
-	profiles.Profiles = append(profiles.Profiles, profile)
-	return SaveProfiles(profiles)
-}
\ No newline at end of file
+	if !found {
+		return ProfileConfig{Profiles: DefaultProfiles}, nil
+	}
+	return profiles, nil
+}
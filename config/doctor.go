@@ -0,0 +1,61 @@
+package config
+
+import (
+	"github.com/Flack74/pom/history"
+	"github.com/Flack74/pom/store"
+)
+
+// DataCheck validates one datastore key by decoding it into its Go type
+type DataCheck struct {
+	Key   string
+	Check func(tx *store.Tx) error
+}
+
+// DataChecks returns a check for every key the config package owns
+func DataChecks() []DataCheck {
+	return []DataCheck{
+		{Key: store.KeyConfig, Check: func(tx *store.Tx) error {
+			var config Config
+			_, err := tx.Get(store.KeyConfig, &config)
+			return err
+		}},
+		{Key: store.KeyProfiles, Check: func(tx *store.Tx) error {
+			_, err := getProfiles(tx)
+			return err
+		}},
+		{Key: store.KeyTasks, Check: func(tx *store.Tx) error {
+			_, err := getTasks(tx)
+			return err
+		}},
+		{Key: store.KeyGoal, Check: func(tx *store.Tx) error {
+			var goal Goal
+			_, err := tx.Get(store.KeyGoal, &goal)
+			return err
+		}},
+		{Key: store.KeyGoals, Check: func(tx *store.Tx) error {
+			_, err := getNamedGoals(tx)
+			return err
+		}},
+		{Key: store.KeyAchievements, Check: func(tx *store.Tx) error {
+			_, err := getAchievements(tx)
+			return err
+		}},
+		{Key: store.KeyProgress, Check: func(tx *store.Tx) error {
+			_, err := getProgress(tx)
+			return err
+		}},
+		{Key: store.KeyPlugins, Check: func(tx *store.Tx) error {
+			_, err := getPlugins(tx)
+			return err
+		}},
+		{Key: store.KeyTheme, Check: func(tx *store.Tx) error {
+			var theme Theme
+			_, err := tx.Get(store.KeyTheme, &theme)
+			return err
+		}},
+		{Key: store.KeySessions, Check: func(tx *store.Tx) error {
+			_, err := history.Read(tx)
+			return err
+		}},
+	}
+}
@@ -1,95 +1,114 @@
 package config
 
 import (
+	"bytes"
 	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"strconv"
-	"time"
+
+	"github.com/Flack74/pom/clock"
+	"github.com/Flack74/pom/history"
+	"github.com/Flack74/pom/store"
 )
 
 type ExportData struct {
-	Sessions []SessionData `json:"sessions"`
-	Tasks    []Task        `json:"tasks"`
-	Goal     Goal          `json:"goal"`
-	Progress GoalProgress  `json:"progress"`
-	Config   Config        `json:"config"`
-	Profiles []Profile     `json:"profiles"`
+	Sessions     []SessionData `json:"sessions"`
+	Tasks        []Task        `json:"tasks"`
+	Goal         Goal          `json:"goal"`
+	Goals        []NamedGoal   `json:"goals,omitempty"`        // Named goals
+	Achievements []Achievement `json:"achievements,omitempty"` // Custom achievement rules
+	Progress     GoalProgress  `json:"progress"`
+	Config       Config        `json:"config"`
+	Profiles     []Profile     `json:"profiles"`
 }
 
-type SessionData struct {
-	Date         time.Time `json:"date"`
-	WorkMinutes  int       `json:"work_minutes"`
-	BreakMinutes int       `json:"break_minutes"`
-	Sessions     int       `json:"sessions"`
-	Completed    bool      `json:"completed"`
-	Profile      string    `json:"profile"`
-}
+// SessionData is a single focus or break interval from the session log
+type SessionData = history.Record
 
 func ExportToJSON(filepath string) error {
+	data, err := json.MarshalIndent(BuildExport(), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return store.WriteFileAtomic(filepath, data, 0644)
+}
+
+// BuildExport collects everything that is exported as a JSON backup
+func BuildExport() ExportData {
 	// Load all data
 	tasks, _ := LoadTasks()
 	goal, _ := LoadGoal()
+	goals, _ := LoadNamedGoals()
+	achievements, _ := LoadAchievements()
 	progress, _ := LoadProgress()
 	config, _ := LoadConfig()
 	profiles, _ := LoadProfiles()
 	sessions, _ := loadSessionHistory()
 
-	exportData := ExportData{
-		Sessions: sessions,
-		Tasks:    tasks.Tasks,
-		Goal:     goal,
-		Progress: progress,
-		Config:   config,
-		Profiles: profiles.Profiles,
+	return ExportData{
+		Sessions:     sessions,
+		Tasks:        tasks.Tasks,
+		Goal:         goal,
+		Goals:        goals,
+		Achievements: achievements.Rules,
+		Progress:     progress,
+		Config:       config,
+		Profiles:     profiles.Profiles,
 	}
+}
 
-	data, err := json.MarshalIndent(exportData, "", "  ")
-	if err != nil {
+func ExportToCSV(filepath string) error {
+	var buf bytes.Buffer
+	if err := WriteSessionsCSV(&buf); err != nil {
 		return err
 	}
 
-	return os.WriteFile(filepath, data, 0644)
+	return store.WriteFileAtomic(filepath, buf.Bytes(), 0644)
 }
 
-func ExportToCSV(filepath string) error {
+// WriteSessionsCSV writes the session log to w as CSV
+func WriteSessionsCSV(w io.Writer) error {
 	sessions, err := loadSessionHistory()
 	if err != nil {
 		return err
 	}
 
-	file, err := os.Create(filepath)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	writer := csv.NewWriter(file)
-	defer writer.Flush()
+	writer := csv.NewWriter(w)
 
 	// Write header
-	header := []string{"Date", "Profile", "Work Minutes", "Break Minutes", "Sessions", "Completed"}
+	header := []string{"Start", "End", "Run ID", "Kind", "Index", "Profile", "Task ID", "Planned Minutes", "Actual Minutes", "Paused Minutes", "Status"}
 	if err := writer.Write(header); err != nil {
 		return err
 	}
 
-	// Write data
+	// Write data, with times in the configured timezone
+	rules := clock.Load()
 	for _, session := range sessions {
 		record := []string{
-			session.Date.Format("2006-01-02 15:04:05"),
+			rules.In(session.StartTime).Format("2006-01-02 15:04:05"),
+			rules.In(session.EndTime).Format("2006-01-02 15:04:05"),
+			session.RunID,
+			session.Kind,
+			strconv.Itoa(session.Index),
 			session.Profile,
-			strconv.Itoa(session.WorkMinutes),
-			strconv.Itoa(session.BreakMinutes),
-			strconv.Itoa(session.Sessions),
-			strconv.FormatBool(session.Completed),
+			session.TaskID,
+			strconv.FormatFloat(session.PlannedMinutes(), 'f', 1, 64),
+			strconv.FormatFloat(session.ActualMinutes(), 'f', 1, 64),
+			strconv.FormatFloat(float64(session.PausedSeconds)/60, 'f', 1, 64),
+			session.Status,
 		}
 		if err := writer.Write(record); err != nil {
 			return err
 		}
 	}
 
-	return nil
+	writer.Flush()
+	return writer.Error()
 }
 
 func ImportFromJSON(filepath string) error {
@@ -103,6 +122,11 @@ func ImportFromJSON(filepath string) error {
 		return err
 	}
 
+	return ImportData(exportData)
+}
+
+// ImportData restores the tasks, profiles and configuration from a backup
+func ImportData(exportData ExportData) error {
 	// Import tasks
 	if len(exportData.Tasks) > 0 {
 		taskList := TaskList{Tasks: exportData.Tasks}
@@ -119,16 +143,31 @@ func ImportFromJSON(filepath string) error {
 		}
 	}
 
-	// Import config
-	if err := SaveConfig(exportData.Config); err != nil {
-		return fmt.Errorf("failed to import config: %v", err)
+	// Import named goals, keeping any already set up
+	for _, goal := range exportData.Goals {
+		if _, err := AddNamedGoal(goal); err != nil && !errors.Is(err, ErrExists) {
+			return fmt.Errorf("failed to import goal '%s': %v", goal.Name, err)
+		}
+	}
+
+	// Import custom achievement rules; unlocks are recomputed from the sessions
+	for _, rule := range exportData.Achievements {
+		if err := AddAchievement(rule); err != nil && !errors.Is(err, ErrExists) {
+			return fmt.Errorf("failed to import achievement '%s': %v", rule.ID, err)
+		}
+	}
+
+	// Import config, unless the backup has none
+	if exportData.Config != (Config{}) {
+		if err := SaveConfig(exportData.Config); err != nil {
+			return fmt.Errorf("failed to import config: %v", err)
+		}
 	}
 
 	return nil
 }
 
+// loadSessionHistory reads the session log written by the logs package
 func loadSessionHistory() ([]SessionData, error) {
-	// This would load from logs/session.go data
-	// For now, return empty slice
-	return []SessionData{}, nil
-}
\ No newline at end of file
+	return history.Load()
+}
@@ -1,160 +1,324 @@
 package config
 
 import (
-	"encoding/json"
 	"fmt"
-	"os"
-	"path/filepath"
+	"strings"
 	"time"
+
+	"github.com/Flack74/pom/clock"
+	"github.com/Flack74/pom/history"
+	"github.com/Flack74/pom/store"
+)
+
+// Streak freeze defaults
+const (
+	DefaultFreezeEvery = 7 // Met days in a row that earn a freeze
+	DefaultMaxFreezes  = 2 // Freezes that can be banked
 )
 
-// Goal represents a daily Pomodoro goal
+// Weekdays are the short weekday names used for per-weekday targets and
+// rest days, Monday first
+var Weekdays = []string{"mon", "tue", "wed", "thu", "fri", "sat", "sun"}
+
+// Goal represents the daily, weekly and monthly Pomodoro goals
 type Goal struct {
-	DailySessionTarget int       `json:"daily_session_target"` // Target number of sessions per day
-	DailyMinutes       int       `json:"daily_minutes"`        // Target minutes of focus time per day
-	StartDate          time.Time `json:"start_date"`           // When this goal was set
-	EndDate            time.Time `json:"end_date,omitempty"`   // Optional end date for the goal
+	DailySessionTarget int                  `json:"daily_session_target"`       // Target number of sessions per day
+	DailyMinutes       int                  `json:"daily_minutes"`              // Target minutes of focus time per day
+	Weekdays           map[string]DayTarget `json:"weekdays,omitempty"`         // Targets for particular weekdays, by short name
+	RestDays           []string             `json:"rest_days,omitempty"`        // Weekdays without a target
+	WeeklySessions     int                  `json:"weekly_sessions,omitempty"`  // Target sessions per week, Monday to Sunday
+	WeeklyMinutes      int                  `json:"weekly_minutes,omitempty"`   // Target minutes per week
+	MonthlySessions    int                  `json:"monthly_sessions,omitempty"` // Target sessions per calendar month
+	MonthlyMinutes     int                  `json:"monthly_minutes,omitempty"`  // Target minutes per calendar month
+	FreezeEvery        int                  `json:"freeze_every,omitempty"`     // Met days in a row that earn a streak freeze, 0 for the default
+	MaxFreezes         int                  `json:"max_freezes,omitempty"`      // Freezes that can be banked, 0 for the default
+	NoFreezes          bool                 `json:"no_freezes,omitempty"`       // Missed days always break the streak
+	StartDate          time.Time            `json:"start_date"`                 // When this goal was set
+	EndDate            time.Time            `json:"end_date,omitempty"`         // Optional end date for the goal
 }
 
-// GoalProgress tracks progress towards goals
-type GoalProgress struct {
-	CurrentDate    time.Time `json:"current_date"`
-	SessionsToday  int       `json:"sessions_today"`
-	MinutesToday   int       `json:"minutes_today"`
-	CurrentStreak  int       `json:"current_streak"`   // Days in a row meeting goals
-	LongestStreak  int       `json:"longest_streak"`   // Longest streak ever
-	LastUpdateDate time.Time `json:"last_update_date"` // Last time progress was updated
+// DayTarget is the focus a day needs to count towards the streak. A day
+// without a target needs one completed session.
+type DayTarget struct {
+	Sessions int `json:"sessions"`
+	Minutes  int `json:"minutes"`
 }
 
-// GetGoalFilePath returns the path to the goals configuration file
-func GetGoalFilePath() (string, error) {
-	configDir, err := GetConfigDir()
-	if err != nil {
-		return "", err
+// Met reports whether a day with these totals reached the target
+func (t DayTarget) Met(sessions, minutes int) bool {
+	if t.Sessions == 0 && t.Minutes == 0 {
+		return sessions > 0
 	}
-	return filepath.Join(configDir, "goals.json"), nil
+	return sessions >= t.Sessions && minutes >= t.Minutes
 }
 
-// SaveGoal saves the current goal to the configuration file
-func SaveGoal(goal Goal) error {
-	goalPath, err := GetGoalFilePath()
-	if err != nil {
-		return err
+// String describes the target, e.g. "4 sessions, 100 min"
+func (t DayTarget) String() string {
+	var parts []string
+	if t.Sessions > 0 {
+		parts = append(parts, fmt.Sprintf("%d sessions", t.Sessions))
+	}
+	if t.Minutes > 0 {
+		parts = append(parts, fmt.Sprintf("%d min", t.Minutes))
 	}
+	if len(parts) == 0 {
+		return "1 session"
+	}
+	return strings.Join(parts, ", ")
+}
 
-	data, err := json.MarshalIndent(goal, "", "  ")
-	if err != nil {
-		return err
+// Daily returns the target for days without a weekday target
+func (g Goal) Daily() DayTarget {
+	return DayTarget{Sessions: g.DailySessionTarget, Minutes: g.DailyMinutes}
+}
+
+// IsSet reports whether any target has been set
+func (g Goal) IsSet() bool {
+	return g.DailySessionTarget > 0 || g.DailyMinutes > 0 || len(g.Weekdays) > 0 || len(g.RestDays) > 0 ||
+		g.WeeklySessions > 0 || g.WeeklyMinutes > 0 || g.MonthlySessions > 0 || g.MonthlyMinutes > 0
+}
+
+// TargetFor returns the target for the date of day, and whether it is a rest day
+func (g Goal) TargetFor(day time.Time) (DayTarget, bool) {
+	name := WeekdayName(day.Weekday())
+	for _, rest := range g.RestDays {
+		if rest == name {
+			return DayTarget{}, true
+		}
+	}
+	if target, ok := g.Weekdays[name]; ok {
+		return target, false
 	}
+	return g.Daily(), false
+}
 
-	return os.WriteFile(goalPath, data, 0644)
+// Freezes returns how many met days in a row earn a streak freeze and how
+// many can be banked, both 0 when freezes are off
+func (g Goal) Freezes() (every, max int) {
+	if g.NoFreezes {
+		return 0, 0
+	}
+	every, max = g.FreezeEvery, g.MaxFreezes
+	if every <= 0 {
+		every = DefaultFreezeEvery
+	}
+	if max <= 0 {
+		max = DefaultMaxFreezes
+	}
+	return every, max
 }
 
-// LoadGoal loads the goal from the configuration file
-func LoadGoal() (Goal, error) {
-	goalPath, err := GetGoalFilePath()
-	if err != nil {
-		return Goal{}, err
+// ValidateGoal checks a goal's targets and weekday names
+func ValidateGoal(goal Goal) error {
+	targets := []int{goal.DailySessionTarget, goal.DailyMinutes, goal.WeeklySessions, goal.WeeklyMinutes,
+		goal.MonthlySessions, goal.MonthlyMinutes, goal.FreezeEvery, goal.MaxFreezes}
+	for _, target := range goal.Weekdays {
+		targets = append(targets, target.Sessions, target.Minutes)
+	}
+	for _, n := range targets {
+		if n < 0 {
+			return fmt.Errorf("%w goal: targets cannot be negative", ErrInvalid)
+		}
 	}
 
-	data, err := os.ReadFile(goalPath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return Goal{}, nil
+	for name := range goal.Weekdays {
+		if !isWeekday(name) {
+			return fmt.Errorf("%w goal: unknown weekday '%s', use %s", ErrInvalid, name, strings.Join(Weekdays, ", "))
 		}
-		return Goal{}, err
 	}
+	rest := map[string]bool{}
+	for _, name := range goal.RestDays {
+		if !isWeekday(name) {
+			return fmt.Errorf("%w goal: unknown weekday '%s', use %s", ErrInvalid, name, strings.Join(Weekdays, ", "))
+		}
+		rest[name] = true
+	}
+	if len(rest) == len(Weekdays) {
+		return fmt.Errorf("%w goal: every day is a rest day", ErrInvalid)
+	}
+	return nil
+}
 
-	var goal Goal
-	if err := json.Unmarshal(data, &goal); err != nil {
-		return Goal{}, err
+// ParseWeekday returns the short name of a weekday given as "mon",
+// "Monday" or anything in between
+func ParseWeekday(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if len(name) >= 3 && isWeekday(name[:3]) && strings.HasPrefix(fullWeekday(name[:3]), name) {
+		return name[:3], nil
 	}
+	return "", fmt.Errorf("%w goal: unknown weekday '%s', use %s", ErrInvalid, name, strings.Join(Weekdays, ", "))
+}
 
-	return goal, nil
+// WeekdayName returns the short name of a weekday
+func WeekdayName(day time.Weekday) string {
+	return Weekdays[(int(day)+6)%7]
 }
 
-// UpdateProgress updates the goal progress for today
-func UpdateProgress(sessions, minutes int) error {
-	progress, err := LoadProgress()
-	if err != nil {
-		progress = GoalProgress{
-			CurrentDate:   time.Now(),
-			SessionsToday: 0,
-			MinutesToday:  0,
-			CurrentStreak: 0,
-			LongestStreak: 0,
+// fullWeekday returns the lowercase full name of a short weekday name
+func fullWeekday(name string) string {
+	for i, short := range Weekdays {
+		if short == name {
+			return strings.ToLower(time.Weekday((i + 1) % 7).String())
 		}
 	}
+	return ""
+}
 
-	// Check if we need to reset daily progress
-	today := time.Now()
-	if !isSameDay(progress.LastUpdateDate, today) {
-		// It's a new day, check if yesterday's goals were met before resetting
-		goal, err := LoadGoal()
-		if err == nil && progress.SessionsToday >= goal.DailySessionTarget &&
-			progress.MinutesToday >= goal.DailyMinutes {
-			progress.CurrentStreak++
-			if progress.CurrentStreak > progress.LongestStreak {
-				progress.LongestStreak = progress.CurrentStreak
-			}
-		} else {
-			progress.CurrentStreak = 0
-		}
+// isWeekday reports whether name is a short weekday name
+func isWeekday(name string) bool {
+	return fullWeekday(name) != ""
+}
 
-		progress.SessionsToday = 0
-		progress.MinutesToday = 0
-		progress.CurrentDate = today
-	}
+// GoalProgress tracks progress towards goals. It is recomputed from the
+// session log on every load.
+type GoalProgress struct {
+	CurrentDate    time.Time      `json:"current_date"`
+	SessionsToday  int            `json:"sessions_today"`
+	MinutesToday   int            `json:"minutes_today"`
+	TargetToday    DayTarget      `json:"target_today"`         // Today's target, by weekday
+	RestToday      bool           `json:"rest_today,omitempty"` // Today is a rest day
+	CurrentStreak  int            `json:"current_streak"`       // Days in a row meeting goals
+	LongestStreak  int            `json:"longest_streak"`       // Longest streak ever
+	Freezes        int            `json:"freezes"`              // Streak freezes banked
+	FreezesUsed    int            `json:"freezes_used"`         // Missed days covered by a freeze
+	Week           PeriodProgress `json:"week"`                 // This week so far
+	Month          PeriodProgress `json:"month"`                // This month so far
+	LastUpdateDate time.Time      `json:"last_update_date"`     // Last time progress was updated
+}
 
-	// Update today's progress
-	progress.SessionsToday += sessions
-	progress.MinutesToday += minutes
-	progress.LastUpdateDate = today
+// PeriodProgress is the progress towards a weekly or monthly goal
+type PeriodProgress struct {
+	Start          time.Time `json:"start"`
+	End            time.Time `json:"end"` // Exclusive
+	Sessions       int       `json:"sessions"`
+	Minutes        int       `json:"minutes"`
+	TargetSessions int       `json:"target_sessions"`
+	TargetMinutes  int       `json:"target_minutes"`
+	Met            bool      `json:"met"` // Always false without a target
+}
 
-	return SaveProgress(progress)
+// HasTarget reports whether the period has a target
+func (p PeriodProgress) HasTarget() bool {
+	return p.TargetSessions > 0 || p.TargetMinutes > 0
 }
 
-// SaveProgress saves the current progress to the configuration file
-func SaveProgress(progress GoalProgress) error {
-	configDir, err := GetConfigDir()
-	if err != nil {
+// SaveGoal validates and saves the current goal to the datastore
+func SaveGoal(goal Goal) error {
+	if err := ValidateGoal(goal); err != nil {
 		return err
 	}
+	return store.Update(func(tx *store.Tx) error {
+		return tx.Put(store.KeyGoal, goal)
+	})
+}
 
-	progressPath := filepath.Join(configDir, "progress.json")
-	data, err := json.MarshalIndent(progress, "", "  ")
-	if err != nil {
+// LoadGoal loads the goal from the datastore
+func LoadGoal() (Goal, error) {
+	var goal Goal
+	err := store.View(func(tx *store.Tx) error {
+		_, err := tx.Get(store.KeyGoal, &goal)
 		return err
+	})
+	if err != nil {
+		return Goal{}, err
 	}
 
-	return os.WriteFile(progressPath, data, 0644)
+	return goal, nil
+}
+
+// UpdateProgress recomputes the goal progress from the session log and
+// saves a copy for exports and plugins
+func UpdateProgress() error {
+	return store.Update(func(tx *store.Tx) error {
+		progress, err := readProgress(tx)
+		if err != nil {
+			return err
+		}
+		return tx.Put(store.KeyProgress, progress)
+	})
 }
 
-// LoadProgress loads the progress from the configuration file
+// SaveProgress saves the current progress to the datastore
+func SaveProgress(progress GoalProgress) error {
+	return store.Update(func(tx *store.Tx) error {
+		return tx.Put(store.KeyProgress, progress)
+	})
+}
+
+// LoadProgress recomputes the goal progress from the session log
 func LoadProgress() (GoalProgress, error) {
-	configDir, err := GetConfigDir()
-	if err != nil {
+	var progress GoalProgress
+	err := store.View(func(tx *store.Tx) error {
+		var err error
+		progress, err = readProgress(tx)
+		return err
+	})
+	return progress, err
+}
+
+// getProgress reads the saved goal progress inside a transaction
+func getProgress(tx *store.Tx) (GoalProgress, error) {
+	var progress GoalProgress
+	if _, err := tx.Get(store.KeyProgress, &progress); err != nil {
 		return GoalProgress{}, err
 	}
+	return progress, nil
+}
 
-	progressPath := filepath.Join(configDir, "progress.json")
-	data, err := os.ReadFile(progressPath)
+// readProgress computes the goal progress inside a transaction
+func readProgress(tx *store.Tx) (GoalProgress, error) {
+	var goal Goal
+	if _, err := tx.Get(store.KeyGoal, &goal); err != nil {
+		return GoalProgress{}, err
+	}
+	records, err := history.Read(tx)
 	if err != nil {
-		if os.IsNotExist(err) {
-			return GoalProgress{
-				CurrentDate:    time.Now(),
-				LastUpdateDate: time.Now(),
-			}, nil
-		}
 		return GoalProgress{}, err
 	}
+	return goalProgress(records, goal, clock.Read(tx), time.Now()), nil
+}
 
-	var progress GoalProgress
-	if err := json.Unmarshal(data, &progress); err != nil {
-		return GoalProgress{}, err
+// goalProgress computes the progress towards goal at now
+func goalProgress(records []history.Record, goal Goal, rules clock.Rules, now time.Time) GoalProgress {
+	days := GoalDays(records, goal, rules, now)
+	today := days[len(days)-1]
+	progress := GoalProgress{
+		CurrentDate:    now.UTC(),
+		SessionsToday:  today.Sessions,
+		MinutesToday:   today.Minutes,
+		TargetToday:    today.Target,
+		RestToday:      today.Status == DayRest,
+		CurrentStreak:  today.Streak,
+		Freezes:        today.Freezes,
+		LastUpdateDate: now.UTC(),
+	}
+	for _, day := range days {
+		if day.Streak > progress.LongestStreak {
+			progress.LongestStreak = day.Streak
+		}
+		if day.Status == DayFrozen {
+			progress.FreezesUsed++
+		}
 	}
 
-	return progress, nil
+	weekStart := today.Date.AddDate(0, 0, -((int(today.Date.Weekday()) + 6) % 7))
+	monthStart := time.Date(today.Date.Year(), today.Date.Month(), 1, 0, 0, 0, 0, today.Date.Location())
+	progress.Week = periodProgress(days, weekStart, weekStart.AddDate(0, 0, 7), goal.WeeklySessions, goal.WeeklyMinutes)
+	progress.Month = periodProgress(days, monthStart, monthStart.AddDate(0, 1, 0), goal.MonthlySessions, goal.MonthlyMinutes)
+	return progress
+}
+
+// periodProgress sums the days from start up to end against a target
+func periodProgress(days []GoalDay, start, end time.Time, sessions, minutes int) PeriodProgress {
+	period := PeriodProgress{Start: start, End: end, TargetSessions: sessions, TargetMinutes: minutes}
+	for _, day := range days {
+		if !day.Date.Before(start) && day.Date.Before(end) {
+			period.Sessions += day.Sessions
+			period.Minutes += day.Minutes
+		}
+	}
+	period.Met = period.HasTarget() && period.Sessions >= sessions && period.Minutes >= minutes
+	return period
 }
 
 // ShowProgress displays the current progress towards goals
@@ -170,17 +334,55 @@ func ShowProgress() error {
 	}
 
 	fmt.Printf("\nDaily Goals Progress:\n")
-	fmt.Printf("Sessions: %d/%d\n", progress.SessionsToday, goal.DailySessionTarget)
-	fmt.Printf("Minutes:  %d/%d\n", progress.MinutesToday, goal.DailyMinutes)
+	if progress.RestToday {
+		fmt.Printf("Rest day: %d sessions, %d minutes\n", progress.SessionsToday, progress.MinutesToday)
+	} else {
+		fmt.Printf("Sessions: %d/%d\n", progress.SessionsToday, progress.TargetToday.Sessions)
+		fmt.Printf("Minutes:  %d/%d\n", progress.MinutesToday, progress.TargetToday.Minutes)
+	}
+	if progress.Week.HasTarget() {
+		fmt.Printf("This week:  %s\n", PeriodSummary(progress.Week))
+	}
+	if progress.Month.HasTarget() {
+		fmt.Printf("This month: %s\n", PeriodSummary(progress.Month))
+	}
 	fmt.Printf("Current Streak: %d days\n", progress.CurrentStreak)
 	fmt.Printf("Longest Streak: %d days\n", progress.LongestStreak)
+	if every, max := goal.Freezes(); every > 0 {
+		fmt.Printf("Streak Freezes: %d/%d (one per %d met days, %d used)\n", progress.Freezes, max, every, progress.FreezesUsed)
+	}
+
+	named, err := NamedGoalsProgress()
+	if err != nil {
+		return fmt.Errorf("failed to load goals: %v", err)
+	}
+	if len(named) > 0 {
+		fmt.Printf("\nGoals:\n")
+	}
+	for _, p := range named {
+		mark := ""
+		if p.Met {
+			mark = " ✅"
+		}
+		fmt.Printf("%-16s %3.0f%%  %s (%s)%s\n", p.Goal.Name, p.Percent, p.Summary(), p.Goal.Scope(), mark)
+	}
 
 	return nil
 }
 
-// Helper function to check if two times are on the same day
-func isSameDay(t1, t2 time.Time) bool {
-	y1, m1, d1 := t1.Date()
-	y2, m2, d2 := t2.Date()
-	return y1 == y2 && m1 == m2 && d1 == d2
+// PeriodSummary describes the progress towards a weekly or monthly goal,
+// e.g. "12/20 sessions, 300/500 min"
+func PeriodSummary(period PeriodProgress) string {
+	var parts []string
+	if period.TargetSessions > 0 {
+		parts = append(parts, fmt.Sprintf("%d/%d sessions", period.Sessions, period.TargetSessions))
+	}
+	if period.TargetMinutes > 0 {
+		parts = append(parts, fmt.Sprintf("%d/%d min", period.Minutes, period.TargetMinutes))
+	}
+	summary := strings.Join(parts, ", ")
+	if period.Met {
+		summary += " ✅"
+	}
+	return summary
 }
@@ -0,0 +1,467 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/Flack74/pom/clock"
+	"github.com/Flack74/pom/history"
+)
+
+// Defaults for the insights report
+const (
+	DefaultInsightDays = 28 // Days of history analyzed
+	MaxInsightDays     = 365
+
+	// DeepFocusMinutes is the average focus length that earns the full depth
+	// component of the focus score
+	DeepFocusMinutes = 50.0
+
+	// trendThreshold is the weekly change, relative to the average week,
+	// above which focus time counts as rising or falling
+	trendThreshold = 0.1
+)
+
+// Focus score weights, see FocusScore
+const (
+	scoreWeightCompletion  = 0.4
+	scoreWeightConsistency = 0.2
+	scoreWeightDepth       = 0.2
+	scoreWeightCalm        = 0.2
+)
+
+// Trend directions
+const (
+	TrendUp   = "up"
+	TrendDown = "down"
+	TrendFlat = "flat"
+)
+
+// InsightBucket totals the focus intervals sharing a weekday, hour, week,
+// profile, task or tag
+type InsightBucket struct {
+	Key            string  `json:"key"`   // Weekday or hour number, profile name, task ID or tag
+	Label          string  `json:"label"` // Display name
+	Sessions       int     `json:"sessions"`
+	Completed      int     `json:"completed"`
+	Interrupted    int     `json:"interrupted"` // Interrupted, skipped or abandoned
+	CompletionRate float64 `json:"completion_rate"`
+	Minutes        float64 `json:"minutes"` // Focus time actually spent
+	PauseMinutes   float64 `json:"pause_minutes"`
+}
+
+// add counts a focus interval in the bucket
+func (b *InsightBucket) add(record history.Record) {
+	b.Sessions++
+	b.Minutes += record.ActualMinutes()
+	b.PauseMinutes += float64(record.PausedSeconds) / 60
+	if record.IsCompleted() {
+		b.Completed++
+	} else {
+		b.Interrupted++
+	}
+	b.CompletionRate = float64(b.Completed) / float64(b.Sessions)
+}
+
+// WeekTrend totals one rolling week
+type WeekTrend struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+	InsightBucket
+}
+
+// Trend describes how focus changed over the rolling weeks
+type Trend struct {
+	Direction       string  `json:"direction"`        // TrendUp, TrendDown or TrendFlat
+	MinutesSlope    float64 `json:"minutes_slope"`    // Change in focus minutes per week
+	CompletionSlope float64 `json:"completion_slope"` // Change in completion rate per week
+	Summary         string  `json:"summary"`
+}
+
+// InterruptionStats describes how focus intervals were cut short or paused
+type InterruptionStats struct {
+	Interrupted     int     `json:"interrupted"` // Stopped early
+	Skipped         int     `json:"skipped"`
+	Abandoned       int     `json:"abandoned"`
+	AvgMinutesIn    float64 `json:"avg_minutes_in"`    // Average focus time before an interval was cut short
+	AvgShareDone    float64 `json:"avg_share_done"`    // Average share of the planned time done before it was cut short
+	PausedSessions  int     `json:"paused_sessions"`   // Focus intervals paused at least once
+	PauseMinutes    float64 `json:"pause_minutes"`     // Total pause time
+	AvgPauseMinutes float64 `json:"avg_pause_minutes"` // Average pause per paused interval
+	WorstHour       int     `json:"worst_hour"`        // Hour with the most cut-short intervals, -1 if none
+	WorstWeekday    string  `json:"worst_weekday"`     // Weekday with the most cut-short intervals
+}
+
+// FocusScore is a 0-100 summary of focus quality. It is the weighted sum
+//
+//	100 × (0.4 × completion + 0.2 × consistency + 0.2 × depth + 0.2 × calm)
+//
+// where completion is the share of focus intervals run to the end,
+// consistency the share of days with a completed focus interval, depth the
+// average focus length over DeepFocusMinutes (at most 1), and calm one minus
+// the share of focus time spent paused.
+type FocusScore struct {
+	Score       float64 `json:"score"`
+	Completion  float64 `json:"completion"`
+	Consistency float64 `json:"consistency"`
+	Depth       float64 `json:"depth"`
+	Calm        float64 `json:"calm"`
+}
+
+// Insights is the statistical analysis of the session log
+type Insights struct {
+	Since time.Time `json:"since"`
+	Until time.Time `json:"until"`
+	Days  int       `json:"days"`
+
+	Focus         InsightBucket     `json:"focus"`    // All focus intervals
+	Weekdays      []InsightBucket   `json:"weekdays"` // Monday first
+	Hours         []InsightBucket   `json:"hours"`    // 0-23
+	Weeks         []WeekTrend       `json:"weeks"`    // Rolling weeks, oldest first
+	Trend         Trend             `json:"trend"`
+	Profiles      []InsightBucket   `json:"profiles"` // Most focus time first
+	Tasks         []InsightBucket   `json:"tasks"`
+	Tags          []InsightBucket   `json:"tags"`
+	Interruptions InterruptionStats `json:"interruptions"`
+	Estimates     EstimateStats     `json:"estimates"` // Tasks completed in the period
+	Score         FocusScore        `json:"score"`
+	BestHour      int               `json:"best_hour"`    // Highest completion rate with 3 or more intervals, -1 if none
+	BestWeekday   string            `json:"best_weekday"` // Highest completion rate with 3 or more intervals
+}
+
+// AnalyzeInsights analyzes the focus intervals of the last days days
+func AnalyzeInsights(days int) (Insights, error) {
+	records, err := history.Load()
+	if err != nil {
+		return Insights{}, err
+	}
+	tasks, err := LoadTasks()
+	if err != nil {
+		return Insights{}, err
+	}
+	return analyzeInsights(records, tasks.Tasks, days, clock.Load(), time.Now()), nil
+}
+
+// analyzeInsights analyzes records over the days days up to now, grouping
+// them into days and hours under the day rules
+func analyzeInsights(records []history.Record, tasks []Task, days int, rules clock.Rules, now time.Time) Insights {
+	if days < 1 {
+		days = DefaultInsightDays
+	}
+	now = rules.In(now)
+	today := rules.Day(now)
+	insights := Insights{
+		Since:    rules.Start(today.AddDate(0, 0, 1-days)),
+		Until:    now,
+		Days:     days,
+		Weekdays: make([]InsightBucket, 7),
+		Hours:    make([]InsightBucket, 24),
+		BestHour: -1,
+		Focus:    InsightBucket{Key: "focus", Label: "Focus"},
+		Profiles: []InsightBucket{},
+		Tasks:    []InsightBucket{},
+		Tags:     []InsightBucket{},
+	}
+	insights.Interruptions.WorstHour = -1
+	for i := range insights.Weekdays {
+		day := time.Weekday((i + 1) % 7)
+		insights.Weekdays[i] = InsightBucket{Key: day.String()[:3], Label: day.String()}
+	}
+	for hour := range insights.Hours {
+		insights.Hours[hour] = InsightBucket{Key: strconv.Itoa(hour), Label: fmt.Sprintf("%02d:00", hour)}
+	}
+
+	taskByID := make(map[string]Task, len(tasks))
+	for _, task := range tasks {
+		taskByID[task.ID] = task
+	}
+
+	// Rolling weeks end today; the oldest may be cut short by the window
+	weeks := (days + 6) / 7
+	for i := 0; i < weeks; i++ {
+		end := rules.Start(today.AddDate(0, 0, 1-7*(weeks-1-i)))
+		start := rules.Start(today.AddDate(0, 0, 1-7*(weeks-i)))
+		if start.Before(insights.Since) {
+			start = insights.Since
+		}
+		insights.Weeks = append(insights.Weeks, WeekTrend{
+			Start:         start,
+			End:           end,
+			InsightBucket: InsightBucket{Key: start.Format("2006-01-02"), Label: "Week of " + start.Format("Jan 2")},
+		})
+	}
+
+	profiles := map[string]*InsightBucket{}
+	taskBuckets := map[string]*InsightBucket{}
+	tags := map[string]*InsightBucket{}
+	activeDays := map[string]bool{}
+	var shareDone float64
+
+	for _, record := range records {
+		start := rules.In(record.StartTime)
+		if !record.IsFocus() || start.Before(insights.Since) || start.After(now) {
+			continue
+		}
+		insights.Focus.add(record)
+		insights.Weekdays[(int(rules.Day(start).Weekday())+6)%7].add(record)
+		insights.Hours[start.Hour()].add(record)
+		for i := range insights.Weeks {
+			if !start.Before(insights.Weeks[i].Start) && start.Before(insights.Weeks[i].End) {
+				insights.Weeks[i].add(record)
+			}
+		}
+
+		profile := record.Profile
+		if profile == "" {
+			profile = "(none)"
+		}
+		bucketFor(profiles, profile, profile).add(record)
+		if task, ok := taskByID[record.TaskID]; ok {
+			bucketFor(taskBuckets, task.ID, task.Title).add(record)
+			for _, tag := range task.Tags {
+				bucketFor(tags, tag, tag).add(record)
+			}
+		} else if record.TaskID != "" {
+			bucketFor(taskBuckets, record.TaskID, record.TaskID).add(record)
+		}
+
+		if record.IsCompleted() {
+			activeDays[rules.Key(start)] = true
+		} else {
+			switch record.Status {
+			case history.StatusSkipped:
+				insights.Interruptions.Skipped++
+			case history.StatusAbandoned:
+				insights.Interruptions.Abandoned++
+			default:
+				insights.Interruptions.Interrupted++
+			}
+			insights.Interruptions.AvgMinutesIn += record.ActualMinutes()
+			if record.PlannedSeconds > 0 {
+				shareDone += math.Min(1, float64(record.ActualSeconds)/float64(record.PlannedSeconds))
+			}
+		}
+		if record.PausedSeconds > 0 {
+			insights.Interruptions.PausedSessions++
+			insights.Interruptions.PauseMinutes += float64(record.PausedSeconds) / 60
+		}
+	}
+
+	insights.Profiles = sortedBuckets(profiles)
+	insights.Tasks = sortedBuckets(taskBuckets)
+	insights.Tags = sortedBuckets(tags)
+
+	stops := insights.Focus.Interrupted
+	if stops > 0 {
+		insights.Interruptions.AvgMinutesIn /= float64(stops)
+		insights.Interruptions.AvgShareDone = shareDone / float64(stops)
+	}
+	if insights.Interruptions.PausedSessions > 0 {
+		insights.Interruptions.AvgPauseMinutes = insights.Interruptions.PauseMinutes / float64(insights.Interruptions.PausedSessions)
+	}
+	insights.Interruptions.WorstHour, _ = mostInterrupted(insights.Hours)
+	if _, label := mostInterrupted(insights.Weekdays); label != "" {
+		insights.Interruptions.WorstWeekday = label
+	}
+	insights.BestHour, _ = bestCompletion(insights.Hours)
+	_, insights.BestWeekday = bestCompletion(insights.Weekdays)
+
+	insights.Estimates = EstimateAccuracy(tasks, insights.Since)
+	insights.Trend = weeklyTrend(insights.Weeks)
+	insights.Score = focusScore(insights.Focus, len(activeDays), activeSpan(records, rules, insights.Since, today, days))
+	return insights
+}
+
+// focusScore computes the FocusScore of focus spread over days days, of
+// which activeDays had a completed focus interval
+func focusScore(focus InsightBucket, activeDays, days int) FocusScore {
+	if focus.Sessions == 0 || days == 0 {
+		return FocusScore{}
+	}
+	score := FocusScore{
+		Completion:  focus.CompletionRate,
+		Consistency: math.Min(1, float64(activeDays)/float64(days)),
+		Depth:       math.Min(1, focus.Minutes/float64(focus.Sessions)/DeepFocusMinutes),
+		Calm:        1,
+	}
+	if total := focus.Minutes + focus.PauseMinutes; total > 0 {
+		score.Calm = 1 - focus.PauseMinutes/total
+	}
+	score.Score = round1(100 * (scoreWeightCompletion*score.Completion +
+		scoreWeightConsistency*score.Consistency +
+		scoreWeightDepth*score.Depth +
+		scoreWeightCalm*score.Calm))
+	score.Completion, score.Consistency = round2(score.Completion), round2(score.Consistency)
+	score.Depth, score.Calm = round2(score.Depth), round2(score.Calm)
+	return score
+}
+
+// activeSpan returns the number of days consistency is measured over: the
+// analyzed days, or fewer if the log starts within them
+func activeSpan(records []history.Record, rules clock.Rules, since, today time.Time, days int) int {
+	first := today
+	for _, record := range records {
+		if record.IsFocus() && !record.StartTime.Before(since) && rules.Day(record.StartTime).Before(first) {
+			first = rules.Day(record.StartTime)
+		}
+	}
+	span := daysBetween(first, today) + 1
+	if span > days {
+		return days
+	}
+	return span
+}
+
+// scoreRecords computes the FocusScore of all focus intervals in records,
+// with consistency measured from the first to the last of their days
+func scoreRecords(records []history.Record, rules clock.Rules) FocusScore {
+	var focus InsightBucket
+	var first, last time.Time
+	activeDays := map[string]bool{}
+	for _, record := range records {
+		if !record.IsFocus() {
+			continue
+		}
+		focus.add(record)
+		day := rules.Day(record.StartTime)
+		if first.IsZero() || day.Before(first) {
+			first = day
+		}
+		if day.After(last) {
+			last = day
+		}
+		if record.IsCompleted() {
+			activeDays[rules.Key(record.StartTime)] = true
+		}
+	}
+	if focus.Sessions == 0 {
+		return FocusScore{}
+	}
+	return focusScore(focus, len(activeDays), daysBetween(first, last)+1)
+}
+
+// daysBetween returns the number of calendar days from a to b
+func daysBetween(a, b time.Time) int {
+	a = time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+	b = time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+	return int(b.Sub(a).Hours() / 24)
+}
+
+// weeklyTrend fits a least-squares line through the weekly focus minutes and
+// completion rates. Focus is rising or falling when the weekly change is more
+// than a tenth of the average week.
+func weeklyTrend(weeks []WeekTrend) Trend {
+	trend := Trend{Direction: TrendFlat, Summary: "Not enough weeks to spot a trend"}
+	if len(weeks) < 2 {
+		return trend
+	}
+
+	minutes := make([]float64, len(weeks))
+	completion := make([]float64, len(weeks))
+	var mean float64
+	for i, week := range weeks {
+		minutes[i] = week.Minutes
+		completion[i] = week.CompletionRate
+		mean += week.Minutes
+	}
+	mean /= float64(len(weeks))
+	trend.MinutesSlope = round1(slope(minutes))
+	trend.CompletionSlope = math.Round(slope(completion)*1000) / 1000
+
+	switch {
+	case mean == 0:
+		trend.Summary = "No focus time in this period"
+	case trend.MinutesSlope > trendThreshold*mean:
+		trend.Direction = TrendUp
+		trend.Summary = fmt.Sprintf("Focus time is rising by about %.0f min a week", trend.MinutesSlope)
+	case trend.MinutesSlope < -trendThreshold*mean:
+		trend.Direction = TrendDown
+		trend.Summary = fmt.Sprintf("Focus time is falling by about %.0f min a week", -trend.MinutesSlope)
+	default:
+		trend.Summary = "Focus time is steady"
+	}
+	return trend
+}
+
+// slope returns the least-squares slope of values against their index
+func slope(values []float64) float64 {
+	n := float64(len(values))
+	var sumX, sumY, sumXY, sumXX float64
+	for i, y := range values {
+		x := float64(i)
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		return 0
+	}
+	return (n*sumXY - sumX*sumY) / denominator
+}
+
+// bucketFor returns the bucket for key, adding it if needed
+func bucketFor(buckets map[string]*InsightBucket, key, label string) *InsightBucket {
+	bucket, ok := buckets[key]
+	if !ok {
+		bucket = &InsightBucket{Key: key, Label: label}
+		buckets[key] = bucket
+	}
+	return bucket
+}
+
+// sortedBuckets returns buckets with the most focus time first
+func sortedBuckets(buckets map[string]*InsightBucket) []InsightBucket {
+	sorted := make([]InsightBucket, 0, len(buckets))
+	for _, bucket := range buckets {
+		sorted = append(sorted, *bucket)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Minutes != sorted[j].Minutes {
+			return sorted[i].Minutes > sorted[j].Minutes
+		}
+		return sorted[i].Key < sorted[j].Key
+	})
+	return sorted
+}
+
+// bestCompletion returns the index and label of the bucket with the highest
+// completion rate among those with enough intervals to judge
+func bestCompletion(buckets []InsightBucket) (int, string) {
+	best := -1
+	for i, bucket := range buckets {
+		if bucket.Sessions < adaptiveMinSamples {
+			continue
+		}
+		if best < 0 || bucket.CompletionRate > buckets[best].CompletionRate {
+			best = i
+		}
+	}
+	if best < 0 {
+		return -1, ""
+	}
+	return best, buckets[best].Label
+}
+
+// mostInterrupted returns the index and label of the bucket with the most
+// cut-short intervals
+func mostInterrupted(buckets []InsightBucket) (int, string) {
+	worst := -1
+	for i, bucket := range buckets {
+		if bucket.Interrupted > 0 && (worst < 0 || bucket.Interrupted > buckets[worst].Interrupted) {
+			worst = i
+		}
+	}
+	if worst < 0 {
+		return -1, ""
+	}
+	return worst, buckets[worst].Label
+}
+
+func round1(x float64) float64 {
+	return math.Round(x*10) / 10
+}
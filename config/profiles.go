@@ -0,0 +1,230 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	"github.com/Flack74/pom/store"
+	"github.com/Flack74/pom/timer"
+)
+
+// Limits enforced by ValidateProfile
+const (
+	MaxProfileNameLength = 32
+	MaxWorkMinutes       = 180
+	MaxBreakMinutes      = 60
+	MaxProfileSessions   = 12
+	MaxProfileIntervals  = 24
+	MaxIntervalName      = 32
+)
+
+// ProfileFileVersion is the format version written by ExportProfiles
+const ProfileFileVersion = 1
+
+// ProfileFile is the shareable file format for profiles
+type ProfileFile struct {
+	Version  int       `json:"pom_profiles"` // Marks the file as a pom profile file
+	Profiles []Profile `json:"profiles"`
+}
+
+// profileNamePattern allows names that are easy to type and safe in URLs
+var profileNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// labelPattern allows display names, such as achievement and interval
+// names, that reach plugin scripts: letters, digits, spaces, '_' and '-'
+var labelPattern = regexp.MustCompile(`^[\p{L}\p{N} _-]+$`)
+
+// ValidateProfile checks a profile's name and durations
+func ValidateProfile(profile Profile) error {
+	switch {
+	case profile.Name == "":
+		return fmt.Errorf("%w profile: a name is required", ErrInvalid)
+	case len(profile.Name) > MaxProfileNameLength:
+		return fmt.Errorf("%w profile: name '%s' is longer than %d characters", ErrInvalid, profile.Name, MaxProfileNameLength)
+	case !profileNamePattern.MatchString(profile.Name):
+		return fmt.Errorf("%w profile: name '%s' may only contain letters, digits, '.', '_' and '-'", ErrInvalid, profile.Name)
+	case profile.Adaptive != nil && len(profile.Intervals) > 0:
+		return fmt.Errorf("%w profile: an adaptive profile cannot have an interval sequence", ErrInvalid)
+	case len(profile.Intervals) > 0:
+		return validateIntervals(profile.Intervals)
+	case profile.WorkMinutes < 1 || profile.WorkMinutes > MaxWorkMinutes:
+		return fmt.Errorf("%w profile: work time must be between 1 and %d minutes, got %d", ErrInvalid, MaxWorkMinutes, profile.WorkMinutes)
+	case profile.BreakMinutes < 1 || profile.BreakMinutes > MaxBreakMinutes:
+		return fmt.Errorf("%w profile: break time must be between 1 and %d minutes, got %d", ErrInvalid, MaxBreakMinutes, profile.BreakMinutes)
+	case profile.NumSessions < 1 || profile.NumSessions > MaxProfileSessions:
+		return fmt.Errorf("%w profile: sessions must be between 1 and %d, got %d", ErrInvalid, MaxProfileSessions, profile.NumSessions)
+	case profile.LongBreakMinutes < 0 || profile.LongBreakMinutes > MaxBreakMinutes:
+		return fmt.Errorf("%w profile: long break must be between 0 and %d minutes, got %d", ErrInvalid, MaxBreakMinutes, profile.LongBreakMinutes)
+	case profile.LongBreakEvery < 0 || profile.LongBreakEvery > MaxProfileSessions:
+		return fmt.Errorf("%w profile: long break frequency must be between 0 and %d sessions, got %d", ErrInvalid, MaxProfileSessions, profile.LongBreakEvery)
+	case (profile.LongBreakMinutes > 0) != (profile.LongBreakEvery > 0):
+		return fmt.Errorf("%w profile: a long break needs both a length and a frequency", ErrInvalid)
+	case profile.Adaptive != nil:
+		return validateAdaptive(profile)
+	}
+	return nil
+}
+
+// validateIntervals checks an explicit interval sequence
+func validateIntervals(intervals []timer.Interval) error {
+	if len(intervals) > MaxProfileIntervals {
+		return fmt.Errorf("%w profile: at most %d intervals are allowed, got %d", ErrInvalid, MaxProfileIntervals, len(intervals))
+	}
+	if err := timer.ValidatePlan(intervals); err != nil {
+		return fmt.Errorf("%w profile: %v", ErrInvalid, err)
+	}
+	for i, interval := range intervals {
+		limit := MaxWorkMinutes
+		if !interval.IsFocus() {
+			limit = MaxBreakMinutes
+		}
+		switch {
+		case len(interval.Name) > MaxIntervalName:
+			return fmt.Errorf("%w profile: interval %d name is longer than %d characters", ErrInvalid, i+1, MaxIntervalName)
+		case interval.Minutes > limit:
+			return fmt.Errorf("%w profile: %s interval %d must be between 1 and %d minutes, got %d", ErrInvalid, interval.Kind, i+1, limit, interval.Minutes)
+		}
+	}
+	return nil
+}
+
+// Plan returns the interval sequence the profile runs: its explicit
+// intervals, or work and break intervals with the long break mixed in
+func (p Profile) Plan() []timer.Interval {
+	if len(p.Intervals) > 0 {
+		return p.Intervals
+	}
+	return timer.ClassicPlan(p.WorkMinutes, p.BreakMinutes, p.NumSessions, p.LongBreakMinutes, p.LongBreakEvery)
+}
+
+// syncIntervals fills in the work, break and session settings of a profile
+// with explicit intervals, so lists and older clients show sensible values
+func syncIntervals(profile *Profile) {
+	if len(profile.Intervals) == 0 {
+		return
+	}
+	for i := range profile.Intervals {
+		if profile.Intervals[i].Name == "" {
+			profile.Intervals[i].Name = profile.Intervals[i].Label()
+		}
+	}
+
+	plan := profile.Intervals
+	profile.NumSessions = timer.FocusCount(plan)
+	profile.WorkMinutes, profile.BreakMinutes = 0, 0
+	for _, interval := range plan {
+		if interval.IsFocus() && profile.WorkMinutes == 0 {
+			profile.WorkMinutes = interval.Minutes
+		}
+		if !interval.IsFocus() && profile.BreakMinutes == 0 {
+			profile.BreakMinutes = interval.Minutes
+		}
+	}
+	profile.LongBreakMinutes, profile.LongBreakEvery = 0, 0
+}
+
+// CloneProfile copies the profile called name to a new profile called newName
+func CloneProfile(name, newName string) (Profile, error) {
+	profile, err := GetProfile(name)
+	if err != nil {
+		return Profile{}, err
+	}
+
+	profile.Name = newName
+	if err := AddProfile(profile); err != nil {
+		return Profile{}, err
+	}
+	return profile, nil
+}
+
+// ExportProfiles returns the named profiles, or all of them if names is
+// empty, in the shareable file format
+func ExportProfiles(names ...string) ([]byte, error) {
+	file := ProfileFile{Version: ProfileFileVersion}
+
+	if len(names) == 0 {
+		profiles, err := LoadProfiles()
+		if err != nil {
+			return nil, err
+		}
+		file.Profiles = profiles.Profiles
+	}
+	for _, name := range names {
+		profile, err := GetProfile(name)
+		if err != nil {
+			return nil, err
+		}
+		file.Profiles = append(file.Profiles, profile)
+	}
+
+	return json.MarshalIndent(file, "", "  ")
+}
+
+// ParseProfileFile reads profiles from a profile file. A single bare profile
+// object is accepted as well.
+func ParseProfileFile(data []byte) ([]Profile, error) {
+	var file ProfileFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("not a profile file: %v", err)
+	}
+
+	if file.Version == 0 && len(file.Profiles) == 0 {
+		var profile Profile
+		if err := json.Unmarshal(data, &profile); err != nil || profile.Name == "" {
+			return nil, fmt.Errorf("not a profile file: no profiles found")
+		}
+		return []Profile{profile}, nil
+	}
+	if file.Version > ProfileFileVersion {
+		return nil, fmt.Errorf("profile file version %d is newer than this pom supports (%d)", file.Version, ProfileFileVersion)
+	}
+
+	for _, profile := range file.Profiles {
+		if err := ValidateProfile(profile); err != nil {
+			return nil, err
+		}
+	}
+	return file.Profiles, nil
+}
+
+// ImportProfiles adds profiles in one transaction. Existing profiles with
+// the same name are replaced if overwrite is set; otherwise nothing is
+// imported. It returns the names that were replaced.
+func ImportProfiles(imported []Profile, overwrite bool) ([]string, error) {
+	for i := range imported {
+		syncIntervals(&imported[i])
+	}
+	for _, profile := range imported {
+		if err := ValidateProfile(profile); err != nil {
+			return nil, err
+		}
+	}
+
+	var replaced []string
+	err := store.Update(func(tx *store.Tx) error {
+		profiles, err := getProfiles(tx)
+		if err != nil {
+			return err
+		}
+
+		for _, profile := range imported {
+			if i := findProfile(profiles, profile.Name); i >= 0 {
+				if !overwrite {
+					return fmt.Errorf("profile '%s' %w", profiles.Profiles[i].Name, ErrExists)
+				}
+				profile.Name = profiles.Profiles[i].Name
+				profiles.Profiles[i] = profile
+				replaced = append(replaced, profile.Name)
+				continue
+			}
+			profiles.Profiles = append(profiles.Profiles, profile)
+		}
+
+		return tx.Put(store.KeyProfiles, profiles)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return replaced, nil
+}
@@ -0,0 +1,116 @@
+package config
+
+import (
+	"time"
+
+	"github.com/Flack74/pom/clock"
+	"github.com/Flack74/pom/history"
+	"github.com/Flack74/pom/store"
+)
+
+// Day statuses in the goal history
+const (
+	DayMet     = "met"
+	DayMissed  = "missed"
+	DayRest    = "rest"    // Rest days neither extend nor break the streak
+	DayFrozen  = "frozen"  // Missed, but a streak freeze kept the streak
+	DayPending = "pending" // Today, not met yet
+)
+
+// GoalDay is one day judged against the goal
+type GoalDay struct {
+	Date     time.Time `json:"date"`
+	Sessions int       `json:"sessions"`
+	Minutes  int       `json:"minutes"`
+	Target   DayTarget `json:"target"`
+	Status   string    `json:"status"`
+	Streak   int       `json:"streak"`  // Streak at the end of the day
+	Freezes  int       `json:"freezes"` // Freezes banked at the end of the day
+}
+
+// GoalHistory judges every day from the first logged session up to today
+// against the current goal, oldest first
+func GoalHistory() ([]GoalDay, error) {
+	var days []GoalDay
+	err := store.View(func(tx *store.Tx) error {
+		var goal Goal
+		if _, err := tx.Get(store.KeyGoal, &goal); err != nil {
+			return err
+		}
+		records, err := history.Read(tx)
+		if err != nil {
+			return err
+		}
+		days = GoalDays(records, goal, clock.Read(tx), time.Now())
+		return nil
+	})
+	return days, err
+}
+
+// GoalDays judges the days of records up to now against goal, oldest first.
+// The streak is rebuilt from the first logged day: met days extend it, rest
+// days and today before it is met leave it alone, and a missed day uses a
+// banked freeze if there is one and breaks the streak otherwise. Every
+// FreezeEvery met days in a row earn a freeze, up to MaxFreezes.
+// The result always ends with today.
+func GoalDays(records []history.Record, goal Goal, rules clock.Rules, now time.Time) []GoalDay {
+	type totals struct{ sessions, seconds int }
+	byDay := map[string]*totals{}
+	today := rules.Day(now)
+	first := today
+	for _, record := range records {
+		if !record.IsFocus() {
+			continue
+		}
+		day := rules.Day(record.EndTime)
+		if day.Before(first) {
+			first = day
+		}
+		key := rules.Key(record.EndTime)
+		if byDay[key] == nil {
+			byDay[key] = &totals{}
+		}
+		if record.IsCompleted() {
+			byDay[key].sessions++
+		}
+		byDay[key].seconds += record.ActualSeconds
+	}
+
+	every, max := goal.Freezes()
+	streak, freezes, run := 0, 0, 0
+	var days []GoalDay
+	for date := first; !date.After(today); date = date.AddDate(0, 0, 1) {
+		day := GoalDay{Date: date}
+		if t := byDay[date.Format("2006-01-02")]; t != nil {
+			day.Sessions, day.Minutes = t.sessions, t.seconds/60
+		}
+		target, rest := goal.TargetFor(date)
+		day.Target = target
+
+		switch {
+		case rest:
+			day.Status = DayRest
+		case target.Met(day.Sessions, day.Minutes):
+			day.Status = DayMet
+			streak++
+			run++
+			if every > 0 && run >= every {
+				run = 0
+				if freezes < max {
+					freezes++
+				}
+			}
+		case date.Equal(today):
+			day.Status = DayPending
+		case freezes > 0:
+			day.Status = DayFrozen
+			freezes--
+		default:
+			day.Status = DayMissed
+			streak, run = 0, 0
+		}
+		day.Streak, day.Freezes = streak, freezes
+		days = append(days, day)
+	}
+	return days
+}
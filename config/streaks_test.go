@@ -0,0 +1,172 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/Flack74/pom/clock"
+	"github.com/Flack74/pom/history"
+)
+
+// focusEnding returns a 25 minute focus record ending at end, "2006-01-02 15:04"
+// in loc
+func focusEnding(t *testing.T, loc *time.Location, end, status string) history.Record {
+	t.Helper()
+	at, err := time.ParseInLocation("2006-01-02 15:04", end, loc)
+	if err != nil {
+		t.Fatalf("bad time %q: %v", end, err)
+	}
+	return history.Record{
+		Kind:           history.KindFocus,
+		StartTime:      at.Add(-25 * time.Minute).UTC(),
+		EndTime:        at.UTC(),
+		PlannedSeconds: 25 * 60,
+		ActualSeconds:  25 * 60,
+		Status:         status,
+	}
+}
+
+func TestGoalDays(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("failed to load timezone: %v", err)
+	}
+	midnight := clock.Rules{Location: newYork}
+	early := clock.Rules{Location: newYork, DayStart: 4 * 60} // Days start at 04:00
+
+	// Wednesday; US daylight saving time started on Sunday 2026-03-08
+	noon := "2026-03-11 12:00"
+
+	tests := []struct {
+		name     string
+		goal     Goal
+		rules    clock.Rules
+		now      string
+		sessions []string // End times in New York
+		want     []string // "MM-DD status streak freezes" per day
+	}{
+		{
+			name:     "met days extend the streak and today is pending",
+			rules:    midnight,
+			now:      noon,
+			sessions: []string{"2026-03-09 10:00", "2026-03-10 10:00"},
+			want:     []string{"03-09 met 1 0", "03-10 met 2 0", "03-11 pending 2 0"},
+		},
+		{
+			name:     "today counts once met",
+			rules:    midnight,
+			now:      noon,
+			sessions: []string{"2026-03-10 10:00", "2026-03-11 09:00"},
+			want:     []string{"03-10 met 1 0", "03-11 met 2 0"},
+		},
+		{
+			name:     "a missed day breaks the streak without freezes",
+			goal:     Goal{NoFreezes: true},
+			rules:    midnight,
+			now:      noon,
+			sessions: []string{"2026-03-08 10:00", "2026-03-10 10:00"},
+			want:     []string{"03-08 met 1 0", "03-09 missed 0 0", "03-10 met 1 0", "03-11 pending 1 0"},
+		},
+		{
+			name:     "an interrupted session does not meet the day",
+			goal:     Goal{NoFreezes: true},
+			rules:    midnight,
+			now:      noon,
+			sessions: []string{"2026-03-09 10:00", "2026-03-10 10:00 interrupted"},
+			want:     []string{"03-09 met 1 0", "03-10 missed 0 0", "03-11 pending 0 0"},
+		},
+		{
+			name:     "a banked freeze covers one missed day",
+			goal:     Goal{FreezeEvery: 2},
+			rules:    midnight,
+			now:      noon,
+			sessions: []string{"2026-03-06 10:00", "2026-03-07 10:00", "2026-03-09 10:00"},
+			want: []string{"03-06 met 1 0", "03-07 met 2 1", "03-08 frozen 2 0", "03-09 met 3 0",
+				"03-10 missed 0 0", "03-11 pending 0 0"},
+		},
+		{
+			name:     "freezes are capped",
+			goal:     Goal{FreezeEvery: 1, MaxFreezes: 1},
+			rules:    midnight,
+			now:      noon,
+			sessions: []string{"2026-03-09 10:00", "2026-03-10 10:00"},
+			want:     []string{"03-09 met 1 1", "03-10 met 2 1", "03-11 pending 2 1"},
+		},
+		{
+			name:     "rest days keep the streak",
+			goal:     Goal{RestDays: []string{"sun"}, NoFreezes: true},
+			rules:    midnight,
+			now:      noon,
+			sessions: []string{"2026-03-07 10:00", "2026-03-09 10:00", "2026-03-10 10:00"},
+			want:     []string{"03-07 met 1 0", "03-08 rest 1 0", "03-09 met 2 0", "03-10 met 3 0", "03-11 pending 3 0"},
+		},
+		{
+			name:     "weekday targets",
+			goal:     Goal{Weekdays: map[string]DayTarget{"mon": {Sessions: 2}}, NoFreezes: true},
+			rules:    midnight,
+			now:      noon,
+			sessions: []string{"2026-03-09 10:00", "2026-03-10 10:00"},
+			want:     []string{"03-09 missed 0 0", "03-10 met 1 0", "03-11 pending 1 0"},
+		},
+		{
+			name:     "sessions before the day start count towards the previous day",
+			goal:     Goal{NoFreezes: true},
+			rules:    early,
+			now:      noon,
+			sessions: []string{"2026-03-10 02:30", "2026-03-11 03:59"},
+			want:     []string{"03-09 met 1 0", "03-10 met 2 0", "03-11 pending 2 0"},
+		},
+		{
+			name:     "today is still yesterday before the day start",
+			goal:     Goal{NoFreezes: true},
+			rules:    early,
+			now:      "2026-03-11 03:00",
+			sessions: []string{"2026-03-09 12:00"},
+			want:     []string{"03-09 met 1 0", "03-10 pending 1 0"},
+		},
+		{
+			name:     "days follow the timezone, not UTC",
+			goal:     Goal{NoFreezes: true},
+			rules:    midnight,
+			now:      noon,
+			sessions: []string{"2026-03-10 22:00"}, // 02:00 UTC on the 11th
+			want:     []string{"03-10 met 1 0", "03-11 pending 1 0"},
+		},
+		{
+			name:     "each day appears once across the DST change",
+			goal:     Goal{NoFreezes: true},
+			rules:    early,
+			now:      noon,
+			sessions: []string{"2026-03-07 10:00", "2026-03-08 10:00", "2026-03-09 10:00", "2026-03-10 10:00"},
+			want:     []string{"03-07 met 1 0", "03-08 met 2 0", "03-09 met 3 0", "03-10 met 4 0", "03-11 pending 4 0"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var records []history.Record
+			for _, session := range tt.sessions {
+				status := history.StatusCompleted
+				end := session
+				if len(session) > len("2006-01-02 15:04") {
+					end, status = session[:16], session[17:]
+				}
+				records = append(records, focusEnding(t, newYork, end, status))
+			}
+			now, err := time.ParseInLocation("2006-01-02 15:04", tt.now, newYork)
+			if err != nil {
+				t.Fatalf("bad now %q: %v", tt.now, err)
+			}
+
+			var got []string
+			for _, day := range GoalDays(records, tt.goal, tt.rules, now) {
+				got = append(got, fmt.Sprintf("%s %s %d %d", day.Date.Format("01-02"), day.Status, day.Streak, day.Freezes))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got  %v\nwant %v", got, tt.want)
+			}
+		})
+	}
+}
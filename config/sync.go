@@ -122,6 +122,27 @@ func (d *DropboxSync) Download(remotePath, localPath string) error {
 	return cmd.Run()
 }
 
+// SyncStatus describes the cloud sync configuration
+type SyncStatus struct {
+	Enabled   bool   `json:"enabled"`
+	Provider  string `json:"provider"`
+	Available bool   `json:"available"` // The provider's tools and credentials are present
+}
+
+// GetSyncStatus reports whether cloud sync is configured and usable
+func GetSyncStatus() (SyncStatus, error) {
+	config, err := LoadConfig()
+	if err != nil {
+		return SyncStatus{}, err
+	}
+
+	status := SyncStatus{Enabled: config.CloudSync, Provider: config.CloudProvider}
+	if provider := GetSyncProvider(config); provider != nil {
+		status.Available = provider.IsAvailable()
+	}
+	return status, nil
+}
+
 func GetSyncProvider(config Config) SyncProvider {
 	switch config.CloudProvider {
 	case "github":
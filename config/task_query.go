@@ -0,0 +1,259 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/Flack74/pom/clock"
+)
+
+// TaskQuery is a parsed task query such as "tag:work due:<friday !done".
+// Terms are separated by spaces and must all match; a term starting with
+// '!' matches the tasks the rest of it does not.
+//
+//	tag:NAME project:NAME priority:LEVEL title:TEXT   fields, ignoring case
+//	due:DATE due:<DATE due:<=DATE due:>DATE due:>=DATE due:none
+//	estimate:N estimate:<N estimate:>N estimate:none  (est: for short)
+//	done open overdue archived                         also as is:done etc.
+//	any other word                                     part of the title
+//
+// Double quotes keep spaces in a value, as in title:"write report" or
+// "write report".
+//
+// Dates take YYYY-MM-DD, today, tomorrow or a weekday, as ParseDueDate does.
+type TaskQuery struct {
+	terms []taskTerm
+}
+
+// taskTerm is one term of a task query
+type taskTerm struct {
+	key    string // "done", "archived" and so on, or the field name
+	negate bool
+	match  func(task Task, rules clock.Rules, now time.Time) bool
+}
+
+// ParseTaskQuery parses a task query, reading dates under rules as of now
+func ParseTaskQuery(query string, rules clock.Rules, now time.Time) (TaskQuery, error) {
+	words, err := splitQuery(query)
+	if err != nil {
+		return TaskQuery{}, err
+	}
+
+	var parsed TaskQuery
+	for _, word := range words {
+		term, err := parseTaskTerm(word, rules, now)
+		if err != nil {
+			return TaskQuery{}, err
+		}
+		parsed.terms = append(parsed.terms, term)
+	}
+	return parsed, nil
+}
+
+// splitQuery splits a query into terms at spaces outside double quotes,
+// dropping the quotes
+func splitQuery(query string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord, quoted := false, false
+	for _, r := range query {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			inWord = true
+		case unicode.IsSpace(r) && !quoted:
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quoted {
+		return nil, fmt.Errorf("%w query: unterminated quote", ErrInvalid)
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+
+// parseTaskTerm parses a single query term
+func parseTaskTerm(word string, rules clock.Rules, now time.Time) (taskTerm, error) {
+	var term taskTerm
+	if strings.HasPrefix(word, "!") && len(word) > 1 {
+		term.negate = true
+		word = word[1:]
+	}
+
+	key, value, hasValue := strings.Cut(word, ":")
+	key = strings.ToLower(key)
+	if !hasValue {
+		key, value = "title", word
+		switch strings.ToLower(word) {
+		case "done", "open", "overdue", "archived":
+			key, value = "is", word
+		}
+	}
+	if value == "" {
+		return taskTerm{}, fmt.Errorf("%w query: '%s' needs a value", ErrInvalid, word)
+	}
+
+	switch key {
+	case "is":
+		term.key = strings.ToLower(value)
+		switch term.key {
+		case "done":
+			term.match = func(task Task, _ clock.Rules, _ time.Time) bool { return task.IsCompleted }
+		case "open":
+			term.match = func(task Task, _ clock.Rules, _ time.Time) bool { return !task.IsCompleted }
+		case "overdue":
+			term.match = func(task Task, rules clock.Rules, now time.Time) bool { return task.IsOverdue(rules, now) }
+		case "archived":
+			term.match = func(task Task, _ clock.Rules, _ time.Time) bool { return task.Archived }
+		default:
+			return taskTerm{}, fmt.Errorf("%w query: unknown state '%s', use done, open, overdue or archived", ErrInvalid, value)
+		}
+	case "tag", "project", "title":
+		term.key = key
+		term.match = textMatcher(key, value)
+	case "priority", "p":
+		priority, err := ParsePriority(value)
+		if err != nil {
+			return taskTerm{}, err
+		}
+		term.key = "priority"
+		term.match = func(task Task, _ clock.Rules, _ time.Time) bool { return task.Priority == priority }
+	case "due":
+		match, err := dueMatcher(value, rules, now)
+		if err != nil {
+			return taskTerm{}, err
+		}
+		term.key, term.match = key, match
+	case "estimate", "est":
+		match, err := estimateMatcher(value)
+		if err != nil {
+			return taskTerm{}, err
+		}
+		term.key, term.match = "estimate", match
+	default:
+		return taskTerm{}, fmt.Errorf("%w query: unknown field '%s', use tag, project, priority, title, due, estimate or is", ErrInvalid, key)
+	}
+	return term, nil
+}
+
+// textMatcher matches a tag or project by name, or part of the title,
+// ignoring case
+func textMatcher(key, value string) func(Task, clock.Rules, time.Time) bool {
+	return func(task Task, _ clock.Rules, _ time.Time) bool {
+		switch key {
+		case "tag":
+			return hasTag(task.Tags, value)
+		case "project":
+			return strings.EqualFold(task.Project, value)
+		}
+		return strings.Contains(strings.ToLower(task.Title), strings.ToLower(value))
+	}
+}
+
+// dueMatcher compares due days with a date such as "<friday" or "2024-06-30";
+// tasks without a due date only match "none"
+func dueMatcher(value string, rules clock.Rules, now time.Time) (func(Task, clock.Rules, time.Time) bool, error) {
+	if strings.EqualFold(value, "none") {
+		return func(task Task, _ clock.Rules, _ time.Time) bool { return task.Due.IsZero() }, nil
+	}
+	op, value := splitComparison(value)
+	due, err := ParseDueDate(value, rules, now)
+	if err != nil {
+		return nil, err
+	}
+	if due.IsZero() {
+		return nil, fmt.Errorf("%w query: due needs a date", ErrInvalid)
+	}
+	day := rules.Day(due)
+	return func(task Task, rules clock.Rules, _ time.Time) bool {
+		if task.Due.IsZero() {
+			return false
+		}
+		return compare(op, task.DueDay(rules).Compare(day))
+	}, nil
+}
+
+// estimateMatcher compares estimates with a number such as ">4"; tasks
+// without an estimate only match "none"
+func estimateMatcher(value string) (func(Task, clock.Rules, time.Time) bool, error) {
+	if strings.EqualFold(value, "none") {
+		return func(task Task, _ clock.Rules, _ time.Time) bool { return !task.HasEstimate() }, nil
+	}
+	op, value := splitComparison(value)
+	estimate, err := strconv.Atoi(value)
+	if err != nil || estimate < 0 {
+		return nil, fmt.Errorf("%w query: invalid estimate '%s'", ErrInvalid, value)
+	}
+	return func(task Task, _ clock.Rules, _ time.Time) bool {
+		if !task.HasEstimate() {
+			return false
+		}
+		switch {
+		case task.Estimate < estimate:
+			return compare(op, -1)
+		case task.Estimate > estimate:
+			return compare(op, 1)
+		}
+		return compare(op, 0)
+	}, nil
+}
+
+// splitComparison splits a leading <, <=, >, >= or = off value
+func splitComparison(value string) (string, string) {
+	for _, op := range []string{"<=", ">=", "<", ">", "="} {
+		if strings.HasPrefix(value, op) {
+			return op, value[len(op):]
+		}
+	}
+	return "=", value
+}
+
+// compare reports whether a comparison result of -1, 0 or 1 satisfies op
+func compare(op string, result int) bool {
+	switch op {
+	case "<":
+		return result < 0
+	case "<=":
+		return result <= 0
+	case ">":
+		return result > 0
+	case ">=":
+		return result >= 0
+	}
+	return result == 0
+}
+
+// Match reports whether task matches every term
+func (q TaskQuery) Match(task Task, rules clock.Rules, now time.Time) bool {
+	for _, term := range q.terms {
+		if term.match(task, rules, now) == term.negate {
+			return false
+		}
+	}
+	return true
+}
+
+// mentions reports whether any term is about one of keys, so that the
+// default of hiding completed or archived tasks can give way to the query
+func (q TaskQuery) mentions(keys ...string) bool {
+	for _, term := range q.terms {
+		for _, key := range keys {
+			if term.key == key {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Flack74/pom/clock"
+)
+
+// queryTasks are the tasks the query tests match against, as of Wednesday
+// 2026-03-11
+func queryTasks() []Task {
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 0, 0, 0, 0, time.UTC) }
+	return []Task{
+		{ID: "a", Title: "Write report", Tags: []string{"work"}, Project: "launch", Priority: PriorityHigh, Due: day(12), Estimate: 5},
+		{ID: "b", Title: "Review PR", Tags: []string{"work"}, Due: day(10), Estimate: 2},
+		{ID: "c", Title: "Buy milk", Tags: []string{"home"}, IsCompleted: true},
+		{ID: "d", Title: "Old report", Tags: []string{"work"}, Due: day(1), IsCompleted: true, Archived: true},
+		{ID: "e", Title: "Plan deep work session", Tags: []string{"deep work"}, Priority: PriorityLow, Due: day(13), Estimate: 8},
+	}
+}
+
+func TestTaskQueryMatch(t *testing.T) {
+	rules := clock.Rules{Location: time.UTC}
+	now := time.Date(2026, 3, 11, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"", []string{"a", "b", "e"}},
+		{"tag:work", []string{"a", "b"}},
+		{"tag:WORK !done", []string{"a", "b"}},
+		{"!tag:work", []string{"e"}},
+		{"project:LAUNCH", []string{"a"}},
+		{"priority:h", []string{"a"}},
+		{"p:low", []string{"e"}},
+		{"due:<friday", []string{"a", "b"}},
+		{"due:<=friday", []string{"a", "b", "e"}},
+		{"due:>today", []string{"a", "e"}},
+		{"due:2026-03-12", []string{"a"}},
+		{"due:>=tomorrow due:<2026-03-13", []string{"a"}},
+		{"due:none done", []string{"c"}},
+		{"!due:none", []string{"a", "b", "e"}},
+		{"est:>4", []string{"a", "e"}},
+		{"estimate:<=2", []string{"b"}},
+		{"est:4", []string{}},
+		{"est:none", []string{}},
+		{"overdue", []string{"b"}},
+		{"is:open", []string{"a", "b", "e"}},
+		{"done", []string{"c"}},
+		{"archived", []string{"d"}},
+		{"done !archived", []string{"c"}},
+		{"!open", []string{"c"}},
+		{"report", []string{"a"}},
+		{"report archived", []string{"d"}},
+		{`title:"deep work"`, []string{"e"}},
+		{`tag:"deep work"`, []string{"e"}},
+		{`"write report"`, []string{"a"}},
+		{`  tag:work   "review"  `, []string{"b"}},
+	}
+	for _, tt := range tests {
+		query, err := ParseTaskQuery(tt.query, rules, now)
+		if err != nil {
+			t.Errorf("ParseTaskQuery(%q) failed: %v", tt.query, err)
+			continue
+		}
+		var got []string
+		for _, task := range FilterTasks(queryTasks(), TaskFilter{Query: query}, rules, now) {
+			got = append(got, task.ID)
+		}
+		if got == nil {
+			got = []string{}
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("query %q matched %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestParseTaskQueryErrors(t *testing.T) {
+	rules := clock.Rules{Location: time.UTC}
+	now := time.Date(2026, 3, 11, 10, 0, 0, 0, time.UTC)
+
+	for _, query := range []string{
+		"due:",
+		`title:""`,
+		"colour:red",
+		"is:later",
+		"priority:urgent",
+		"due:someday",
+		"due:<",
+		"est:lots",
+		"est:-1",
+		`title:"unterminated`,
+	} {
+		if _, err := ParseTaskQuery(query, rules, now); !errors.Is(err, ErrInvalid) {
+			t.Errorf("ParseTaskQuery(%q) = %v, want an ErrInvalid error", query, err)
+		}
+	}
+}
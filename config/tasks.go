@@ -1,162 +1,587 @@
 package config
 
 import (
-	"encoding/json"
 	"fmt"
-	"os"
-	"path/filepath"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
+
+	"github.com/Flack74/pom/clock"
+	"github.com/Flack74/pom/store"
 )
 
 // Task represents a task to be completed during Pomodoro sessions
 type Task struct {
-	ID          string    `json:"id"`           // Unique identifier
-	Title       string    `json:"title"`        // Task title
-	Description string    `json:"description"`  // Optional description
-	CreatedAt   time.Time `json:"created_at"`   // When the task was created
-	CompletedAt time.Time `json:"completed_at"` // When the task was completed
-	Sessions    int       `json:"sessions"`     // Number of sessions spent on this task
-	Minutes     int       `json:"minutes"`      // Total minutes spent on this task
-	Tags        []string  `json:"tags"`         // Optional tags for categorization
-	IsCompleted bool      `json:"is_completed"` // Whether the task is completed
+	ID          string    `json:"id"`                 // Unique identifier
+	Title       string    `json:"title"`              // Task title
+	Description string    `json:"description"`        // Optional description
+	CreatedAt   time.Time `json:"created_at"`         // When the task was created
+	CompletedAt time.Time `json:"completed_at"`       // When the task was completed
+	Sessions    int       `json:"sessions"`           // Number of sessions spent on this task
+	Minutes     int       `json:"minutes"`            // Total minutes spent on this task
+	Tags        []string  `json:"tags"`               // Optional tags for categorization
+	IsCompleted bool      `json:"is_completed"`       // Whether the task is completed
+	Estimate    int       `json:"estimate,omitempty"` // Estimated focus sessions, 0 if not estimated
+	Priority    string    `json:"priority,omitempty"` // PriorityHigh, PriorityMedium or PriorityLow
+	Due         time.Time `json:"due"`                // Start of the day the task is due, zero if none
+	Project     string    `json:"project,omitempty"`  // Project the task belongs to
+	Number      int       `json:"number"`             // Short number to refer to the task by, e.g. 3 or #3
+	Archived    bool      `json:"archived,omitempty"` // Hidden from lists unless asked for
 }
 
+// Task priorities
+const (
+	PriorityHigh   = "high"
+	PriorityMedium = "medium"
+	PriorityLow    = "low"
+)
+
+// TaskSortKeys are the orders tasks can be listed in
+var TaskSortKeys = []string{"created", "priority", "due", "estimate", "variance", "project", "title"}
+
 // TaskList represents a list of tasks
 type TaskList struct {
-	Tasks []Task `json:"tasks"`
-}
-
-// GetTaskFilePath returns the path to the tasks file
-func GetTaskFilePath() (string, error) {
-	configDir, err := GetConfigDir()
-	if err != nil {
-		return "", err
-	}
-	return filepath.Join(configDir, "tasks.json"), nil
+	Tasks      []Task `json:"tasks"`
+	LastNumber int    `json:"last_number,omitempty"` // Highest task number handed out, so numbers are not reused
 }
 
-// SaveTasks saves the task list to the configuration file
+// SaveTasks saves the task list to the datastore, with times in UTC
 func SaveTasks(tasks TaskList) error {
-	taskPath, err := GetTaskFilePath()
-	if err != nil {
-		return err
+	for i := range tasks.Tasks {
+		tasks.Tasks[i].CreatedAt = tasks.Tasks[i].CreatedAt.UTC()
+		tasks.Tasks[i].CompletedAt = tasks.Tasks[i].CompletedAt.UTC()
+		tasks.Tasks[i].Due = tasks.Tasks[i].Due.UTC()
 	}
+	return store.Update(func(tx *store.Tx) error {
+		return tx.Put(store.KeyTasks, tasks)
+	})
+}
 
-	data, err := json.MarshalIndent(tasks, "", "  ")
-	if err != nil {
+// LoadTasks loads the task list from the datastore
+func LoadTasks() (TaskList, error) {
+	var tasks TaskList
+	err := store.View(func(tx *store.Tx) error {
+		var err error
+		tasks, err = getTasks(tx)
 		return err
-	}
+	})
+	return tasks, err
+}
 
-	return os.WriteFile(taskPath, data, 0644)
+// updateTasks applies fn to the task list within a single transaction
+func updateTasks(fn func(tasks *TaskList) error) error {
+	return store.Update(func(tx *store.Tx) error {
+		tasks, err := getTasks(tx)
+		if err != nil {
+			return err
+		}
+		if err := fn(&tasks); err != nil {
+			return err
+		}
+		return tx.Put(store.KeyTasks, tasks)
+	})
 }
 
-// LoadTasks loads the task list from the configuration file
-func LoadTasks() (TaskList, error) {
-	taskPath, err := GetTaskFilePath()
-	if err != nil {
+// getTasks reads the task list inside a transaction
+func getTasks(tx *store.Tx) (TaskList, error) {
+	tasks := TaskList{Tasks: []Task{}}
+	if _, err := tx.Get(store.KeyTasks, &tasks); err != nil {
 		return TaskList{}, err
 	}
+	numberTasks(&tasks)
+	return tasks, nil
+}
 
-	data, err := os.ReadFile(taskPath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return TaskList{Tasks: []Task{}}, nil
+// numberTasks gives tasks without a number the next ones, oldest first, so
+// tasks created before numbers existed get stable ones
+func numberTasks(tasks *TaskList) {
+	var unnumbered []int
+	for i, task := range tasks.Tasks {
+		if task.Number == 0 {
+			unnumbered = append(unnumbered, i)
+		} else if task.Number > tasks.LastNumber {
+			tasks.LastNumber = task.Number
 		}
-		return TaskList{}, err
 	}
+	sort.SliceStable(unnumbered, func(a, b int) bool {
+		return tasks.Tasks[unnumbered[a]].CreatedAt.Before(tasks.Tasks[unnumbered[b]].CreatedAt)
+	})
+	for _, i := range unnumbered {
+		tasks.LastNumber++
+		tasks.Tasks[i].Number = tasks.LastNumber
+	}
+}
 
-	var tasks TaskList
-	if err := json.Unmarshal(data, &tasks); err != nil {
-		return TaskList{}, err
+// findTask returns the index of the task ref refers to: a task number such as
+// 3 or #3, an ID, or a prefix of exactly one ID
+func findTask(tasks []Task, ref string) (int, error) {
+	ref = strings.TrimSpace(ref)
+	if number, err := strconv.Atoi(strings.TrimPrefix(ref, "#")); err == nil {
+		for i, task := range tasks {
+			if task.Number == number {
+				return i, nil
+			}
+		}
 	}
 
-	return tasks, nil
+	found := -1
+	for i, task := range tasks {
+		if task.ID == ref {
+			return i, nil
+		}
+		if ref != "" && strings.HasPrefix(task.ID, ref) {
+			if found >= 0 {
+				return -1, fmt.Errorf("%w task: '%s' matches more than one task ID, use the task number", ErrInvalid, ref)
+			}
+			found = i
+		}
+	}
+	if found < 0 {
+		return -1, fmt.Errorf("task %s %w", ref, ErrNotFound)
+	}
+	return found, nil
 }
 
-// AddTask adds a new task to the list
-func AddTask(title, description string, tags []string) error {
-	tasks, err := LoadTasks()
-	if err != nil {
-		return err
+// AddTask adds a new task with the title, description, tags, estimate,
+// priority, due date and project of task to the list and returns it
+func AddTask(task Task) (Task, error) {
+	if err := ValidateTask(task); err != nil {
+		return Task{}, err
 	}
-
 	newTask := Task{
 		ID:          fmt.Sprintf("%d", time.Now().UnixNano()),
-		Title:       title,
-		Description: description,
-		CreatedAt:   time.Now(),
-		Tags:        tags,
+		Title:       task.Title,
+		Description: task.Description,
+		CreatedAt:   time.Now().UTC(),
+		Tags:        task.Tags,
 		IsCompleted: false,
+		Estimate:    task.Estimate,
+		Priority:    task.Priority,
+		Due:         task.Due.UTC(),
+		Project:     task.Project,
 	}
 
-	tasks.Tasks = append(tasks.Tasks, newTask)
-	return SaveTasks(tasks)
+	err := updateTasks(func(tasks *TaskList) error {
+		tasks.LastNumber++
+		newTask.Number = tasks.LastNumber
+		tasks.Tasks = append(tasks.Tasks, newTask)
+		return nil
+	})
+	return newTask, err
 }
 
-// CompleteTask marks a task as completed
-func CompleteTask(id string) error {
+// GetTask returns the task ref refers to: a task number such as 3 or #3, an
+// ID, or a prefix of exactly one ID
+func GetTask(ref string) (Task, error) {
 	tasks, err := LoadTasks()
 	if err != nil {
-		return err
+		return Task{}, err
 	}
 
-	for i := range tasks.Tasks {
-		if tasks.Tasks[i].ID == id {
-			tasks.Tasks[i].IsCompleted = true
-			tasks.Tasks[i].CompletedAt = time.Now()
-			return SaveTasks(tasks)
-		}
+	i, err := findTask(tasks.Tasks, ref)
+	if err != nil {
+		return Task{}, err
 	}
+	return tasks.Tasks[i], nil
+}
+
+// UpdateTask applies fn to the task ref refers to and returns the result
+func UpdateTask(ref string, fn func(task *Task)) (Task, error) {
+	var updated Task
+	err := updateTasks(func(tasks *TaskList) error {
+		i, err := findTask(tasks.Tasks, ref)
+		if err != nil {
+			return err
+		}
+		id, number := tasks.Tasks[i].ID, tasks.Tasks[i].Number
+		fn(&tasks.Tasks[i])
+		tasks.Tasks[i].ID, tasks.Tasks[i].Number = id, number
+		if err := ValidateTask(tasks.Tasks[i]); err != nil {
+			return err
+		}
+		tasks.Tasks[i].Due = tasks.Tasks[i].Due.UTC()
+		updated = tasks.Tasks[i]
+		return nil
+	})
+	return updated, err
+}
 
-	return fmt.Errorf("task with ID %s not found", id)
+// DeleteTask removes the task ref refers to and returns it
+func DeleteTask(ref string) (Task, error) {
+	var deleted Task
+	err := updateTasks(func(tasks *TaskList) error {
+		i, err := findTask(tasks.Tasks, ref)
+		if err != nil {
+			return err
+		}
+		deleted = tasks.Tasks[i]
+		tasks.Tasks = append(tasks.Tasks[:i], tasks.Tasks[i+1:]...)
+		return nil
+	})
+	return deleted, err
+}
+
+// CompleteTask marks a task as completed and returns it
+func CompleteTask(ref string) (Task, error) {
+	return UpdateTask(ref, func(task *Task) {
+		task.IsCompleted = true
+		task.CompletedAt = time.Now().UTC()
+	})
+}
+
+// ReopenTask marks a completed or archived task as open again and returns it
+func ReopenTask(ref string) (Task, error) {
+	return UpdateTask(ref, func(task *Task) {
+		task.IsCompleted = false
+		task.CompletedAt = time.Time{}
+		task.Archived = false
+	})
+}
+
+// ArchiveTask hides a task from lists and returns it. Its sessions still
+// count in insights and reports.
+func ArchiveTask(ref string) (Task, error) {
+	return UpdateTask(ref, func(task *Task) {
+		task.Archived = true
+	})
+}
+
+// ArchiveCompletedTasks archives every completed task and returns how many
+// were archived
+func ArchiveCompletedTasks() (int, error) {
+	archived := 0
+	err := updateTasks(func(tasks *TaskList) error {
+		for i := range tasks.Tasks {
+			if tasks.Tasks[i].IsCompleted && !tasks.Tasks[i].Archived {
+				tasks.Tasks[i].Archived = true
+				archived++
+			}
+		}
+		return nil
+	})
+	return archived, err
 }
 
 // UpdateTaskProgress updates the time spent on a task
 func UpdateTaskProgress(id string, sessions, minutes int) error {
-	tasks, err := LoadTasks()
-	if err != nil {
-		return err
+	return updateTasks(func(tasks *TaskList) error {
+		for i := range tasks.Tasks {
+			if tasks.Tasks[i].ID == id {
+				tasks.Tasks[i].Sessions += sessions
+				tasks.Tasks[i].Minutes += minutes
+				return nil
+			}
+		}
+
+		return fmt.Errorf("task with ID %s %w", id, ErrNotFound)
+	})
+}
+
+// ValidateTask checks a task's title, estimate and priority
+func ValidateTask(task Task) error {
+	switch {
+	case strings.TrimSpace(task.Title) == "":
+		return fmt.Errorf("%w task: a title is required", ErrInvalid)
+	case task.Estimate < 0:
+		return fmt.Errorf("%w task: estimate cannot be negative", ErrInvalid)
+	case task.Priority != "" && task.Priority != PriorityHigh && task.Priority != PriorityMedium && task.Priority != PriorityLow:
+		return fmt.Errorf("%w task: priority must be high, medium or low, got '%s'", ErrInvalid, task.Priority)
 	}
+	return nil
+}
 
-	for i := range tasks.Tasks {
-		if tasks.Tasks[i].ID == id {
-			tasks.Tasks[i].Sessions += sessions
-			tasks.Tasks[i].Minutes += minutes
-			return SaveTasks(tasks)
+// ParsePriority reads a priority such as "high", "m" or "3"; "" and "none"
+// clear it
+func ParsePriority(value string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "none":
+		return "", nil
+	case "high", "h", "1":
+		return PriorityHigh, nil
+	case "medium", "med", "m", "2":
+		return PriorityMedium, nil
+	case "low", "l", "3":
+		return PriorityLow, nil
+	}
+	return "", fmt.Errorf("%w task: unknown priority '%s', use high, medium or low", ErrInvalid, value)
+}
+
+// ParseDueDate reads a due date as YYYY-MM-DD, "today", "tomorrow" or a
+// weekday, meaning the next one from today on, and returns the start of that
+// day under rules. "" and "none" give the zero time.
+func ParseDueDate(value string, rules clock.Rules, now time.Time) (time.Time, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	today := rules.Day(now)
+	switch value {
+	case "", "none":
+		return time.Time{}, nil
+	case "today":
+		return rules.Start(today), nil
+	case "tomorrow":
+		return rules.Start(today.AddDate(0, 0, 1)), nil
+	}
+	if day, err := time.ParseInLocation("2006-01-02", value, rules.Local()); err == nil {
+		return rules.Start(day), nil
+	}
+	if weekday, err := ParseWeekday(value); err == nil {
+		day := today
+		for WeekdayName(day.Weekday()) != weekday {
+			day = day.AddDate(0, 0, 1)
+		}
+		return rules.Start(day), nil
+	}
+	return time.Time{}, fmt.Errorf("%w task: invalid due date '%s', use YYYY-MM-DD, today, tomorrow or a weekday", ErrInvalid, value)
+}
+
+// HasEstimate reports whether the task has an estimate
+func (t Task) HasEstimate() bool {
+	return t.Estimate > 0
+}
+
+// Variance is the number of sessions spent beyond the estimate, negative if
+// under it
+func (t Task) Variance() int {
+	return t.Sessions - t.Estimate
+}
+
+// EstimateSummary describes the sessions spent against the estimate, e.g.
+// "5 of 4 estimated (+1, 25% over)"
+func (t Task) EstimateSummary() string {
+	if !t.HasEstimate() {
+		return fmt.Sprintf("%d", t.Sessions)
+	}
+	variance := t.Variance()
+	percent := math.Round(math.Abs(float64(variance)) / float64(t.Estimate) * 100)
+	switch {
+	case variance > 0:
+		return fmt.Sprintf("%d of %d estimated (+%d, %.0f%% over)", t.Sessions, t.Estimate, variance, percent)
+	case variance < 0 && t.IsCompleted:
+		return fmt.Sprintf("%d of %d estimated (%d, %.0f%% under)", t.Sessions, t.Estimate, variance, percent)
+	}
+	return fmt.Sprintf("%d of %d estimated", t.Sessions, t.Estimate)
+}
+
+// DueDay returns the day the task is due under rules, or the zero time
+func (t Task) DueDay(rules clock.Rules) time.Time {
+	if t.Due.IsZero() {
+		return time.Time{}
+	}
+	return rules.Day(t.Due)
+}
+
+// IsOverdue reports whether an open task's due day is before the day now
+// counts towards
+func (t Task) IsOverdue(rules clock.Rules, now time.Time) bool {
+	return !t.IsCompleted && !t.Due.IsZero() && t.DueDay(rules).Before(rules.Day(now))
+}
+
+// TaskFilter selects the tasks to list. Empty fields match every task.
+// Completed and archived tasks are left out unless asked for, by the flags
+// or by a query term about them.
+type TaskFilter struct {
+	Completed bool      // Include completed tasks
+	Archived  bool      // Include archived tasks
+	Query     TaskQuery // Only tasks matching the query
+	Project   string    // Only tasks in this project
+	Tag       string    // Only tasks with this tag
+	Priority  string    // Only tasks with this priority
+	Overdue   bool      // Only open tasks past their due day
+	DueBy     time.Time // Only tasks due on or before this day
+}
+
+// Match reports whether task passes the filter
+func (f TaskFilter) Match(task Task, rules clock.Rules, now time.Time) bool {
+	switch {
+	case !f.Completed && task.IsCompleted && !f.Query.mentions("done", "open", "archived"):
+		return false
+	case !f.Archived && task.Archived && !f.Query.mentions("archived"):
+		return false
+	case !f.Query.Match(task, rules, now):
+		return false
+	case f.Project != "" && !strings.EqualFold(task.Project, f.Project):
+		return false
+	case f.Tag != "" && !hasTag(task.Tags, f.Tag):
+		return false
+	case f.Priority != "" && task.Priority != f.Priority:
+		return false
+	case f.Overdue && !task.IsOverdue(rules, now):
+		return false
+	case !f.DueBy.IsZero() && (task.Due.IsZero() || task.DueDay(rules).After(rules.Day(f.DueBy))):
+		return false
+	}
+	return true
+}
+
+// FilterTasks returns the tasks that pass filter
+func FilterTasks(tasks []Task, filter TaskFilter, rules clock.Rules, now time.Time) []Task {
+	result := []Task{}
+	for _, task := range tasks {
+		if filter.Match(task, rules, now) {
+			result = append(result, task)
+		}
+	}
+	return result
+}
+
+// SortTasks sorts tasks in place by one of TaskSortKeys. Tasks without a
+// priority, due date or estimate come last; ties keep their order.
+func SortTasks(tasks []Task, by string) error {
+	var less func(a, b Task) bool
+	switch by {
+	case "", "created":
+		less = func(a, b Task) bool { return a.CreatedAt.Before(b.CreatedAt) }
+	case "priority":
+		less = func(a, b Task) bool { return priorityRank(a.Priority) < priorityRank(b.Priority) }
+	case "due":
+		less = func(a, b Task) bool {
+			return !a.Due.IsZero() && (b.Due.IsZero() || a.Due.Before(b.Due))
+		}
+	case "estimate":
+		less = func(a, b Task) bool {
+			return a.HasEstimate() && (!b.HasEstimate() || a.Estimate > b.Estimate)
+		}
+	case "variance":
+		less = func(a, b Task) bool {
+			return a.HasEstimate() && (!b.HasEstimate() || a.Variance() > b.Variance())
+		}
+	case "project":
+		less = func(a, b Task) bool {
+			return a.Project != "" && (b.Project == "" || strings.ToLower(a.Project) < strings.ToLower(b.Project))
+		}
+	case "title":
+		less = func(a, b Task) bool { return strings.ToLower(a.Title) < strings.ToLower(b.Title) }
+	default:
+		return fmt.Errorf("%w task: unknown sort '%s', use %s", ErrInvalid, by, strings.Join(TaskSortKeys, ", "))
+	}
+	sort.SliceStable(tasks, func(i, j int) bool { return less(tasks[i], tasks[j]) })
+	return nil
+}
+
+// priorityRank orders priorities from high to none
+func priorityRank(priority string) int {
+	switch priority {
+	case PriorityHigh:
+		return 0
+	case PriorityMedium:
+		return 1
+	case PriorityLow:
+		return 2
+	}
+	return 3
+}
+
+// EstimateStats compares estimated and actual sessions of completed tasks
+type EstimateStats struct {
+	Tasks     int     `json:"tasks"`     // Completed tasks with an estimate
+	Estimated int     `json:"estimated"` // Sessions estimated for them
+	Actual    int     `json:"actual"`    // Sessions spent on them
+	Ratio     float64 `json:"ratio"`     // Actual over estimated sessions, 0 if no tasks
+	Accuracy  float64 `json:"accuracy"`  // One minus the mean error relative to each estimate, at least 0
+	Over      int     `json:"over"`      // Tasks that took more sessions than estimated
+	Under     int     `json:"under"`     // Tasks that took fewer
+	OnTarget  int     `json:"on_target"` // Tasks that took as many
+	Summary   string  `json:"summary"`
+}
+
+// EstimateAccuracy compares the estimates of tasks completed since since
+// with the sessions they took
+func EstimateAccuracy(tasks []Task, since time.Time) EstimateStats {
+	var stats EstimateStats
+	var relativeError float64
+	for _, task := range tasks {
+		if !task.IsCompleted || !task.HasEstimate() || task.CompletedAt.Before(since) {
+			continue
+		}
+		stats.Tasks++
+		stats.Estimated += task.Estimate
+		stats.Actual += task.Sessions
+		relativeError += math.Abs(float64(task.Variance())) / float64(task.Estimate)
+		switch {
+		case task.Variance() > 0:
+			stats.Over++
+		case task.Variance() < 0:
+			stats.Under++
+		default:
+			stats.OnTarget++
 		}
 	}
+	if stats.Tasks == 0 {
+		stats.Summary = "No completed tasks with an estimate yet"
+		return stats
+	}
 
-	return fmt.Errorf("task with ID %s not found", id)
+	stats.Ratio = round2(float64(stats.Actual) / float64(stats.Estimated))
+	stats.Accuracy = round2(math.Max(0, 1-relativeError/float64(stats.Tasks)))
+	off := math.Round(math.Abs(stats.Ratio-1) * 100)
+	switch {
+	case off == 0:
+		stats.Summary = fmt.Sprintf("Tasks took as many sessions as estimated (%d tasks)", stats.Tasks)
+	case stats.Ratio > 1:
+		stats.Summary = fmt.Sprintf("Tasks took %.0f%% more sessions than estimated (%d tasks)", off, stats.Tasks)
+	default:
+		stats.Summary = fmt.Sprintf("Tasks took %.0f%% fewer sessions than estimated (%d tasks)", off, stats.Tasks)
+	}
+	return stats
 }
 
-// ListTasks displays all tasks
-func ListTasks(showCompleted bool) error {
+// ListTasks displays the tasks that pass filter, sorted by one of TaskSortKeys
+func ListTasks(filter TaskFilter, sortBy string) error {
 	tasks, err := LoadTasks()
 	if err != nil {
 		return err
 	}
+	rules, now := clock.Load(), time.Now()
+	listed := FilterTasks(tasks.Tasks, filter, rules, now)
+	if err := SortTasks(listed, sortBy); err != nil {
+		return err
+	}
 
 	fmt.Println("\nTasks:")
-	for _, task := range tasks.Tasks {
-		if !showCompleted && task.IsCompleted {
-			continue
-		}
-
+	if len(listed) == 0 {
+		fmt.Println("No matching tasks.")
+	}
+	for _, task := range listed {
 		status := "[ ]"
 		if task.IsCompleted {
 			status = "[✓]"
 		}
 
-		fmt.Printf("%s %s (ID: %s)\n", status, task.Title, task.ID)
+		archived := ""
+		if task.Archived {
+			archived = " (archived)"
+		}
+		fmt.Printf("%s #%d %s%s\n", status, task.Number, task.Title, archived)
 		if task.Description != "" {
 			fmt.Printf("   Description: %s\n", task.Description)
 		}
+		var details []string
+		if task.Project != "" {
+			details = append(details, "Project: "+task.Project)
+		}
+		if task.Priority != "" {
+			details = append(details, "Priority: "+task.Priority)
+		}
+		if !task.Due.IsZero() {
+			due := "Due: " + task.DueDay(rules).Format("Mon Jan 2")
+			if task.IsOverdue(rules, now) {
+				due += " (overdue)"
+			}
+			details = append(details, due)
+		}
+		if len(details) > 0 {
+			fmt.Printf("   %s\n", strings.Join(details, ", "))
+		}
 		if len(task.Tags) > 0 {
 			fmt.Printf("   Tags: %v\n", task.Tags)
 		}
-		fmt.Printf("   Sessions: %d, Total Time: %d minutes\n", task.Sessions, task.Minutes)
+		fmt.Printf("   Sessions: %s, Total Time: %d minutes\n", task.EstimateSummary(), task.Minutes)
 		fmt.Println()
 	}
 
+	if estimates := EstimateAccuracy(tasks.Tasks, time.Time{}); estimates.Tasks > 0 {
+		fmt.Printf("📏 %s, %.0f%% accurate\n", estimates.Summary, estimates.Accuracy*100)
+	}
 	return nil
 }
@@ -1,10 +1,9 @@
 package config
 
 import (
-	"encoding/json"
 	"fmt"
-	"os"
-	"path/filepath"
+
+	"github.com/Flack74/pom/store"
 )
 
 // Theme represents a color scheme for the application
@@ -61,47 +60,21 @@ var (
 	}
 )
 
-// GetThemeFilePath returns the path to the theme configuration file
-func GetThemeFilePath() (string, error) {
-	configDir, err := GetConfigDir()
-	if err != nil {
-		return "", err
-	}
-	return filepath.Join(configDir, "theme.json"), nil
-}
-
-// SaveTheme saves the current theme to the configuration file
+// SaveTheme saves the current theme to the datastore
 func SaveTheme(theme Theme) error {
-	themePath, err := GetThemeFilePath()
-	if err != nil {
-		return err
-	}
-
-	data, err := json.MarshalIndent(theme, "", "  ")
-	if err != nil {
-		return err
-	}
-
-	return os.WriteFile(themePath, data, 0644)
+	return store.Update(func(tx *store.Tx) error {
+		return tx.Put(store.KeyTheme, theme)
+	})
 }
 
-// LoadTheme loads the theme from the configuration file
+// LoadTheme loads the theme from the datastore
 func LoadTheme() (Theme, error) {
-	themePath, err := GetThemeFilePath()
-	if err != nil {
-		return DefaultTheme, err
-	}
-
-	data, err := os.ReadFile(themePath)
+	theme := DefaultTheme
+	err := store.View(func(tx *store.Tx) error {
+		_, err := tx.Get(store.KeyTheme, &theme)
+		return err
+	})
 	if err != nil {
-		if os.IsNotExist(err) {
-			return DefaultTheme, nil
-		}
-		return DefaultTheme, err
-	}
-
-	var theme Theme
-	if err := json.Unmarshal(data, &theme); err != nil {
 		return DefaultTheme, err
 	}
 
@@ -0,0 +1,199 @@
+package daemon
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/Flack74/pom/timer"
+)
+
+// spawnTimeout is how long Connect waits for a freshly spawned daemon
+const spawnTimeout = 5 * time.Second
+
+// Client is a connection to the daemon
+type Client struct {
+	conn    net.Conn
+	reader  *bufio.Reader
+	encoder *json.Encoder
+	nextID  int
+}
+
+// Dial connects to a running daemon
+func Dial() (*Client, error) {
+	path, err := SocketPath()
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("pom daemon is not running: %v", err)
+	}
+
+	return &Client{
+		conn:    conn,
+		reader:  bufio.NewReader(conn),
+		encoder: json.NewEncoder(conn),
+	}, nil
+}
+
+// Connect connects to the daemon, starting it in the background first if it
+// is not running
+func Connect() (*Client, error) {
+	if client, err := Dial(); err == nil {
+		return client, nil
+	}
+
+	if err := spawn(); err != nil {
+		return nil, fmt.Errorf("failed to start pom daemon: %v", err)
+	}
+
+	deadline := time.Now().Add(spawnTimeout)
+	for {
+		client, err := Dial()
+		if err == nil {
+			return client, nil
+		}
+		if time.Now().After(deadline) {
+			logPath, _ := LogPath()
+			return nil, fmt.Errorf("pom daemon did not start (see %s): %v", logPath, err)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// Close closes the connection
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// Call invokes method with params and returns the resulting snapshot
+func (c *Client) Call(method string, params interface{}) (timer.Snapshot, error) {
+	if err := c.send(method, params); err != nil {
+		return timer.Snapshot{}, err
+	}
+	return c.receive()
+}
+
+// Watch turns the connection into a stream of snapshots. The first one is
+// the current state; the channel is closed when the connection ends.
+func (c *Client) Watch() (<-chan timer.Snapshot, error) {
+	current, err := c.Call(MethodWatch, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := make(chan timer.Snapshot, 16)
+	updates <- current
+
+	go func() {
+		defer close(updates)
+		for {
+			msg, err := c.read()
+			if err != nil {
+				return
+			}
+			if msg.Method == NotifyState && msg.Params != nil {
+				updates <- *msg.Params
+			}
+		}
+	}()
+
+	return updates, nil
+}
+
+// send writes a request
+func (c *Client) send(method string, params interface{}) error {
+	c.nextID++
+
+	req := request{JSONRPC: "2.0", ID: c.nextID, Method: method}
+	if params != nil {
+		raw, err := json.Marshal(params)
+		if err != nil {
+			return fmt.Errorf("failed to encode params: %v", err)
+		}
+		req.Params = raw
+	}
+
+	if err := c.encoder.Encode(req); err != nil {
+		return fmt.Errorf("failed to send %s request: %v", method, err)
+	}
+	return nil
+}
+
+// receive reads the response to the last request
+func (c *Client) receive() (timer.Snapshot, error) {
+	for {
+		msg, err := c.read()
+		if err != nil {
+			return timer.Snapshot{}, err
+		}
+		if msg.ID != c.nextID {
+			continue
+		}
+		if msg.Error != nil {
+			return timer.Snapshot{}, errors.New(msg.Error.Message)
+		}
+		if msg.Result == nil {
+			return timer.Snapshot{}, nil
+		}
+		return *msg.Result, nil
+	}
+}
+
+// read decodes the next message from the daemon
+func (c *Client) read() (message, error) {
+	var msg message
+
+	line, err := c.reader.ReadBytes('\n')
+	if err != nil {
+		return msg, fmt.Errorf("lost connection to pom daemon: %v", err)
+	}
+	if err := json.Unmarshal(line, &msg); err != nil {
+		return msg, fmt.Errorf("invalid response from pom daemon: %v", err)
+	}
+
+	return msg, nil
+}
+
+// spawn starts `pom daemon` as a detached background process
+func spawn() error {
+	logPath, err := LogPath()
+	if err != nil {
+		return err
+	}
+	_, err = Spawn(logPath, "daemon")
+	return err
+}
+
+// Spawn runs this executable with args as a detached background process that
+// outlives the terminal, appending its output to logPath. It returns the pid.
+func Spawn(logPath string, args ...string) (int, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return 0, err
+	}
+
+	logFile, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return 0, err
+	}
+	defer logFile.Close()
+
+	cmd := exec.Command(exe, args...)
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	cmd.SysProcAttr = detachedProcAttr()
+
+	if err := cmd.Start(); err != nil {
+		return 0, err
+	}
+	pid := cmd.Process.Pid
+	return pid, cmd.Process.Release()
+}
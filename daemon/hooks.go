@@ -0,0 +1,145 @@
+package daemon
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Flack74/pom/config"
+	"github.com/Flack74/pom/history"
+	"github.com/Flack74/pom/logs"
+	"github.com/Flack74/pom/timer"
+)
+
+// Hooks returns the engine hooks that credit tasks and goals, fire plugins
+// and show desktop notifications as a run progresses
+func Hooks() timer.Hooks {
+	return timer.Hooks{
+		RunStart:   runStart,
+		PhaseStart: phaseStart,
+		PhaseEnd:   phaseEnd,
+		RunEnd:     runEnd,
+	}
+}
+
+// runStart fires the session_start plugins
+func runStart(state timer.State) {
+	config.ExecutePlugins("session_start", sessionData(state))
+}
+
+// phaseStart fires the break_start plugins when a break begins
+func phaseStart(state timer.State) {
+	if state.Phase == history.KindBreak {
+		config.ExecutePlugins("break_start", breakData(state))
+	}
+}
+
+// phaseEnd credits focus time to the linked task and daily goals, and
+// announces completed intervals and achievements
+func phaseEnd(record history.Record) {
+	if record.IsFocus() {
+		recordFocusProgress(record)
+	}
+	if !record.IsCompleted() {
+		return
+	}
+
+	if record.IsFocus() {
+		notify("work_end", "Work session complete!", "Time for a break!")
+		return
+	}
+
+	config.ExecutePlugins("break_end", map[string]string{
+		"DURATION": fmt.Sprintf("%d", record.PlannedSeconds/60),
+		"INTERVAL": record.Name,
+		"SESSION":  fmt.Sprintf("%d", record.Index),
+		"DATE":     record.StartTime.UTC().Format(time.RFC3339),
+	})
+	notify("break_end", "Break complete!", "Time to focus!")
+}
+
+// runEnd fires the session_end plugins and announces a finished run
+func runEnd(state timer.State, completed bool) {
+	data := sessionData(state)
+	data["COMPLETED"] = fmt.Sprintf("%t", completed)
+	data["TOTAL_MINUTES"] = fmt.Sprintf("%d", timer.FocusMinutes(state.Intervals))
+	config.ExecutePlugins("session_end", data)
+
+	if completed {
+		notify("work_end", "Pomodoro Complete!", "Great job on completing all your sessions!")
+	}
+}
+
+// recordFocusProgress credits a finished focus interval to the linked task and daily goals
+func recordFocusProgress(record history.Record) {
+	if record.TaskID != "" {
+		sessions := 0
+		if record.IsCompleted() {
+			sessions = 1
+		}
+		if err := config.UpdateTaskProgress(record.TaskID, sessions, record.ActualSeconds/60); err != nil {
+			log.Printf("failed to update task progress: %v", err)
+		}
+	}
+
+	if err := config.UpdateProgress(); err != nil {
+		log.Printf("failed to update goals progress: %v", err)
+	}
+	AnnounceAchievements()
+}
+
+// AnnounceAchievements unlocks the achievements that have been earned, fires
+// the achievement plugins for each and shows a notification. It returns the
+// new unlocks.
+func AnnounceAchievements() []config.Unlock {
+	unlocked, err := config.CheckAchievements()
+	if err != nil {
+		log.Printf("failed to check achievements: %v", err)
+		return nil
+	}
+	if len(unlocked) == 0 {
+		return nil
+	}
+
+	for _, unlock := range unlocked {
+		config.ExecutePlugins("achievement", unlock.PluginData())
+	}
+	msg := unlocked[len(unlocked)-1].Name
+	if len(unlocked) > 1 {
+		msg = fmt.Sprintf("%s and %d more", msg, len(unlocked)-1)
+	}
+	notify("achievement", "Achievement unlocked!", msg)
+	return unlocked
+}
+
+// notify plays a sound and shows a desktop notification
+func notify(sound, title, msg string) {
+	if err := logs.PlaySound(sound); err != nil {
+		log.Printf("error playing sound: %v", err)
+	}
+	if err := logs.ShowNotification(title, msg); err != nil {
+		log.Printf("error showing notification: %v", err)
+	}
+}
+
+// sessionData is the plugin environment for session_start and session_end
+func sessionData(state timer.State) map[string]string {
+	return map[string]string{
+		"DURATION":       fmt.Sprintf("%d", state.WorkMinutes),
+		"BREAK_DURATION": fmt.Sprintf("%d", state.BreakMinutes),
+		"SESSIONS":       fmt.Sprintf("%d", state.Sessions),
+		"DATE":           time.Now().UTC().Format(time.RFC3339),
+		"TASK_ID":        state.TaskID,
+	}
+}
+
+// breakData is the plugin environment for break_start
+func breakData(state timer.State) map[string]string {
+	interval := state.Current()
+	return map[string]string{
+		"DURATION": fmt.Sprintf("%d", interval.Minutes),
+		"INTERVAL": interval.Label(),
+		"SESSION":  fmt.Sprintf("%d", state.Session),
+		"DATE":     time.Now().UTC().Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,90 @@
+// Package daemon runs the timer engine in a background process and exposes
+// it over a Unix domain socket. Clients speak JSON-RPC 2.0, one JSON object
+// per line: each request gets a response, and a "watch" request turns the
+// connection into a stream of "state" notifications.
+package daemon
+
+import (
+	"encoding/json"
+	"path/filepath"
+
+	"github.com/Flack74/pom/store"
+	"github.com/Flack74/pom/timer"
+)
+
+// RPC methods
+const (
+	MethodStart    = "start"    // Params: StartParams
+	MethodPause    = "pause"    // Pause the current phase
+	MethodResume   = "resume"   // Resume a paused or recovered run
+	MethodStop     = "stop"     // End the run early
+	MethodSkip     = "skip"     // Skip to the next phase
+	MethodStatus   = "status"   // Return the current snapshot
+	MethodWatch    = "watch"    // Stream snapshots as "state" notifications
+	MethodShutdown = "shutdown" // Stop the daemon
+)
+
+// NotifyState is the method name of streamed snapshot notifications
+const NotifyState = "state"
+
+// JSON-RPC error codes
+const (
+	codeParseError     = -32700
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+	codeServerError    = -32000
+)
+
+// StartParams are the parameters of the start method. Intervals, when set,
+// is the run's interval sequence; otherwise the run alternates work and
+// break intervals.
+type StartParams struct {
+	WorkMinutes  int              `json:"work_minutes"`
+	BreakMinutes int              `json:"break_minutes"`
+	Sessions     int              `json:"sessions"`
+	Intervals    []timer.Interval `json:"intervals,omitempty"`
+	Profile      string           `json:"profile,omitempty"`
+	TaskID       string           `json:"task_id,omitempty"`
+}
+
+// request is a JSON-RPC request
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      int             `json:"id"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// message is a JSON-RPC response or notification
+type message struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      int             `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  *timer.Snapshot `json:"params,omitempty"`
+	Result  *timer.Snapshot `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+// rpcError is a JSON-RPC error object
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// SocketPath returns the path of the control socket
+func SocketPath() (string, error) {
+	dir, err := store.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "pom.sock"), nil
+}
+
+// LogPath returns the path of the log file used by a spawned daemon
+func LogPath() (string, error) {
+	dir, err := store.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "daemon.log"), nil
+}
@@ -0,0 +1,205 @@
+package daemon
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+
+	"github.com/Flack74/pom/timer"
+)
+
+// Server serves the timer engine on the control socket
+type Server struct {
+	engine   *timer.Engine
+	listener net.Listener
+	path     string
+	done     chan struct{}
+	once     sync.Once
+}
+
+// Listen opens the control socket for engine. It fails if another daemon
+// is already listening.
+func Listen(engine *timer.Engine) (*Server, error) {
+	path, err := SocketPath()
+	if err != nil {
+		return nil, err
+	}
+
+	if conn, err := net.Dial("unix", path); err == nil {
+		conn.Close()
+		return nil, fmt.Errorf("a pom daemon is already running on %s", path)
+	}
+
+	// A socket file left by a daemon that did not exit cleanly
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to remove stale socket: %v", err)
+	}
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %v", path, err)
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to secure socket: %v", err)
+	}
+
+	return &Server{
+		engine:   engine,
+		listener: listener,
+		path:     path,
+		done:     make(chan struct{}),
+	}, nil
+}
+
+// Serve runs the engine and accepts clients until Close is called
+func (s *Server) Serve() error {
+	stopped := make(chan struct{})
+	go func() {
+		s.engine.Run(s.done)
+		close(stopped)
+	}()
+
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			select {
+			case <-s.done:
+				<-stopped
+				return nil
+			default:
+				return fmt.Errorf("failed to accept connection: %v", err)
+			}
+		}
+		go s.handle(conn)
+	}
+}
+
+// Done is closed once the server starts shutting down
+func (s *Server) Done() <-chan struct{} {
+	return s.done
+}
+
+// Close stops the engine, saving the active run, and removes the socket
+func (s *Server) Close() {
+	s.once.Do(func() {
+		close(s.done)
+		s.listener.Close()
+		os.Remove(s.path)
+	})
+}
+
+// handle serves requests from one client connection
+func (s *Server) handle(conn net.Conn) {
+	defer conn.Close()
+
+	scanner := bufio.NewScanner(conn)
+	encoder := json.NewEncoder(conn)
+
+	for scanner.Scan() {
+		var req request
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			encoder.Encode(errorMessage(0, codeParseError, fmt.Sprintf("invalid request: %v", err)))
+			continue
+		}
+
+		if req.Method == MethodWatch {
+			s.watch(scanner, encoder, req.ID)
+			return
+		}
+
+		if err := encoder.Encode(s.call(req)); err != nil {
+			return
+		}
+
+		if req.Method == MethodShutdown {
+			s.Close()
+			return
+		}
+	}
+}
+
+// call runs a single request against the engine
+func (s *Server) call(req request) message {
+	var err error
+
+	switch req.Method {
+	case MethodStart:
+		var params StartParams
+		if jsonErr := json.Unmarshal(req.Params, &params); jsonErr != nil {
+			return errorMessage(req.ID, codeInvalidParams, fmt.Sprintf("invalid start params: %v", jsonErr))
+		}
+		plan := params.Intervals
+		if len(plan) == 0 {
+			if params.WorkMinutes <= 0 || params.BreakMinutes < 0 || params.Sessions <= 0 {
+				return errorMessage(req.ID, codeInvalidParams, "work minutes and sessions must be positive")
+			}
+			plan = timer.ClassicPlan(params.WorkMinutes, params.BreakMinutes, params.Sessions, 0, 0)
+		}
+		if planErr := timer.ValidatePlan(plan); planErr != nil {
+			return errorMessage(req.ID, codeInvalidParams, fmt.Sprintf("invalid intervals: %v", planErr))
+		}
+		err = s.engine.Start(plan, params.Profile, params.TaskID)
+	case MethodPause:
+		err = s.engine.Pause()
+	case MethodResume:
+		err = s.engine.Resume()
+	case MethodStop:
+		err = s.engine.Stop()
+	case MethodSkip:
+		err = s.engine.Skip()
+	case MethodStatus, MethodShutdown:
+	default:
+		return errorMessage(req.ID, codeMethodNotFound, fmt.Sprintf("unknown method %q", req.Method))
+	}
+
+	if err != nil {
+		return errorMessage(req.ID, codeServerError, err.Error())
+	}
+
+	snapshot := s.engine.Snapshot()
+	return message{JSONRPC: "2.0", ID: req.ID, Result: &snapshot}
+}
+
+// watch streams snapshots to the client until it disconnects
+func (s *Server) watch(scanner *bufio.Scanner, encoder *json.Encoder, id int) {
+	updates, cancel := s.engine.Subscribe()
+	defer cancel()
+
+	snapshot := s.engine.Snapshot()
+	if err := encoder.Encode(message{JSONRPC: "2.0", ID: id, Result: &snapshot}); err != nil {
+		return
+	}
+
+	// Watchers send nothing more; a read returning means they went away
+	closed := make(chan struct{})
+	go func() {
+		for scanner.Scan() {
+		}
+		close(closed)
+	}()
+
+	for {
+		select {
+		case <-closed:
+			return
+		case <-s.done:
+			return
+		case snapshot, ok := <-updates:
+			if !ok {
+				return
+			}
+			if err := encoder.Encode(message{JSONRPC: "2.0", Method: NotifyState, Params: &snapshot}); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// errorMessage builds a JSON-RPC error response
+func errorMessage(id, code int, msg string) message {
+	return message{JSONRPC: "2.0", ID: id, Error: &rpcError{Code: code, Message: msg}}
+}
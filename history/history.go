@@ -0,0 +1,225 @@
+package history
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Flack74/pom/clock"
+	"github.com/Flack74/pom/store"
+)
+
+// Interval kinds
+const (
+	KindFocus = "focus"
+	KindBreak = "break"
+)
+
+// Interval statuses
+const (
+	StatusCompleted   = "completed"   // Ran for the full planned duration
+	StatusInterrupted = "interrupted" // Stopped early by quit or Ctrl+C
+	StatusAbandoned   = "abandoned"   // Lost when the process exited and never resumed
+	StatusSkipped     = "skipped"     // Cut short to move on to the next phase
+)
+
+// Record is a single focus or break interval in the session log
+type Record struct {
+	RunID          string    `json:"run_id,omitempty"` // Groups the intervals of one `pom start` run
+	Kind           string    `json:"kind"`             // KindFocus or KindBreak
+	Name           string    `json:"name,omitempty"`   // Interval name from the run's sequence, e.g. "Long break"
+	Index          int       `json:"index"`            // 1-based session number within the run
+	StartTime      time.Time `json:"start_time"`
+	EndTime        time.Time `json:"end_time"`
+	PlannedSeconds int       `json:"planned_seconds"` // Length the interval was set to
+	ActualSeconds  int       `json:"actual_seconds"`  // Time actually spent, excluding pauses
+	PausedSeconds  int       `json:"paused_seconds"`  // Time spent paused
+	Status         string    `json:"status"`
+	Profile        string    `json:"profile,omitempty"` // Profile the run was started with
+	TaskID         string    `json:"task_id,omitempty"` // Linked task, if any
+}
+
+// IsFocus reports whether the record is a focus interval
+func (r Record) IsFocus() bool {
+	return r.Kind == KindFocus
+}
+
+// IsCompleted reports whether the interval ran to the end
+func (r Record) IsCompleted() bool {
+	return r.Status == StatusCompleted
+}
+
+// PlannedMinutes returns the planned length in minutes
+func (r Record) PlannedMinutes() float64 {
+	return float64(r.PlannedSeconds) / 60
+}
+
+// ActualMinutes returns the time actually spent in minutes
+func (r Record) ActualMinutes() float64 {
+	return float64(r.ActualSeconds) / 60
+}
+
+// legacyRecord is the pre-interval format, where one entry covered a whole run
+type legacyRecord struct {
+	Record
+	WorkMinutes  int  `json:"work_minutes"`
+	BreakMinutes int  `json:"break_minutes"`
+	NumSessions  int  `json:"num_sessions"`
+	IsCompleted  bool `json:"is_completed"`
+}
+
+// Load reads all records from the session log
+func Load() ([]Record, error) {
+	var records []Record
+	err := store.View(func(tx *store.Tx) error {
+		var err error
+		records, err = Read(tx)
+		return err
+	})
+	return records, err
+}
+
+// Read reads all records inside a transaction, converting legacy run entries
+// into per-interval records
+func Read(tx *store.Tx) ([]Record, error) {
+	var raw []legacyRecord
+	if _, err := tx.Get(store.KeySessions, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse session log: %v", err)
+	}
+
+	records := []Record{}
+	var lastLegacy *legacyRecord
+	for i := range raw {
+		entry := raw[i]
+		if entry.Kind != "" {
+			records = append(records, entry.Record)
+			continue
+		}
+
+		// Older versions logged every run twice, a few milliseconds apart
+		if lastLegacy != nil && isDuplicateRun(*lastLegacy, entry) {
+			continue
+		}
+		lastLegacy = &raw[i]
+		records = append(records, expandLegacy(entry)...)
+	}
+
+	return records, nil
+}
+
+// Append adds records to the session log, storing their times in UTC
+func Append(newRecords ...Record) error {
+	return store.Update(func(tx *store.Tx) error {
+		records, err := Read(tx)
+		if err != nil {
+			return err
+		}
+		for _, record := range newRecords {
+			record.StartTime, record.EndTime = record.StartTime.UTC(), record.EndTime.UTC()
+			records = append(records, record)
+		}
+		return tx.Put(store.KeySessions, records)
+	})
+}
+
+// DayTotals returns the completed focus intervals and focus minutes for the
+// day containing t under the given day rules. Intervals count towards the day
+// they ended on, and interrupted focus intervals still contribute the minutes
+// actually spent.
+func DayTotals(records []Record, rules clock.Rules, t time.Time) (sessions int, minutes int) {
+	startOfDay, endOfDay := rules.Bounds(t)
+
+	seconds := 0
+	for _, record := range records {
+		if !record.IsFocus() {
+			continue
+		}
+		if record.EndTime.Before(startOfDay) || !record.EndTime.Before(endOfDay) {
+			continue
+		}
+		if record.IsCompleted() {
+			sessions++
+		}
+		seconds += record.ActualSeconds
+	}
+
+	return sessions, seconds / 60
+}
+
+// isDuplicateRun reports whether two legacy entries describe the same run
+func isDuplicateRun(a, b legacyRecord) bool {
+	gap := b.EndTime.Sub(a.EndTime)
+	if gap < 0 {
+		gap = -gap
+	}
+	return gap < 5*time.Second &&
+		a.WorkMinutes == b.WorkMinutes &&
+		a.BreakMinutes == b.BreakMinutes &&
+		a.NumSessions == b.NumSessions
+}
+
+// expandLegacy converts a whole-run entry into focus and break intervals.
+// Completed runs are laid out back to back from the start time; for
+// incomplete runs only the elapsed time is known, so it is recorded as a
+// single interrupted focus interval.
+func expandLegacy(entry legacyRecord) []Record {
+	runID := fmt.Sprintf("%d", entry.StartTime.UnixNano())
+	work := entry.WorkMinutes * 60
+	brk := entry.BreakMinutes * 60
+
+	if !entry.IsCompleted {
+		actual := int(entry.EndTime.Sub(entry.StartTime).Seconds())
+		if actual > work {
+			actual = work
+		}
+		return []Record{{
+			RunID:          runID,
+			Kind:           KindFocus,
+			Index:          1,
+			StartTime:      entry.StartTime,
+			EndTime:        entry.EndTime,
+			PlannedSeconds: work,
+			ActualSeconds:  actual,
+			Status:         StatusInterrupted,
+			Profile:        entry.Profile,
+			TaskID:         entry.TaskID,
+		}}
+	}
+
+	var records []Record
+	cursor := entry.StartTime
+	for i := 1; i <= entry.NumSessions; i++ {
+		end := cursor.Add(time.Duration(work) * time.Second)
+		records = append(records, Record{
+			RunID:          runID,
+			Kind:           KindFocus,
+			Index:          i,
+			StartTime:      cursor,
+			EndTime:        end,
+			PlannedSeconds: work,
+			ActualSeconds:  work,
+			Status:         StatusCompleted,
+			Profile:        entry.Profile,
+			TaskID:         entry.TaskID,
+		})
+		cursor = end
+
+		if i < entry.NumSessions {
+			end = cursor.Add(time.Duration(brk) * time.Second)
+			records = append(records, Record{
+				RunID:          runID,
+				Kind:           KindBreak,
+				Index:          i,
+				StartTime:      cursor,
+				EndTime:        end,
+				PlannedSeconds: brk,
+				ActualSeconds:  brk,
+				Status:         StatusCompleted,
+				Profile:        entry.Profile,
+				TaskID:         entry.TaskID,
+			})
+			cursor = end
+		}
+	}
+
+	return records
+}
@@ -1,127 +1,54 @@
 package logs
 
 import (
-	"encoding/json"
-	"fmt"
-	"os"
-	"path/filepath"
+	"sort"
 	"time"
-)
-
-// Session represents a completed Pomodoro session
-type Session struct {
-	WorkMinutes  int       `json:"work_minutes"`
-	BreakMinutes int       `json:"break_minutes"`
-	NumSessions  int       `json:"num_sessions"`
-	StartTime    time.Time `json:"start_time"`
-	EndTime      time.Time `json:"end_time"`
-	IsCompleted  bool      `json:"is_completed"`
-}
-
-// getLogFilePath returns the path to the session log file
-func getLogFilePath() (string, error) {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return "", err
-	}
-
-	logDir := filepath.Join(homeDir, ".config", "pom", "logs")
-	if err := os.MkdirAll(logDir, 0755); err != nil {
-		return "", err
-	}
-
-	return filepath.Join(logDir, "sessions.json"), nil
-}
-
-// LogSession logs a completed Pomodoro session
-func LogSession(workMin, breakMin, numSessions int, startTime, endTime time.Time, isCompleted bool) error {
-	logPath, err := getLogFilePath()
-	if err != nil {
-		return fmt.Errorf("failed to get log path: %v", err)
-	}
 
-	// Create new session entry
-	session := Session{
-		WorkMinutes:  workMin,
-		BreakMinutes: breakMin,
-		NumSessions:  numSessions,
-		StartTime:    startTime,
-		EndTime:      endTime,
-		IsCompleted:  isCompleted,
-	}
-
-	// Read existing sessions
-	var sessions []Session
-	data, err := os.ReadFile(logPath)
-	if err != nil && !os.IsNotExist(err) {
-		return fmt.Errorf("failed to read log file: %v", err)
-	}
-
-	if len(data) > 0 {
-		if err := json.Unmarshal(data, &sessions); err != nil {
-			return fmt.Errorf("failed to parse log file: %v", err)
-		}
-	}
-
-	// Add new session
-	sessions = append(sessions, session)
-
-	// Save updated sessions
-	data, err = json.MarshalIndent(sessions, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal log data: %v", err)
-	}
+	"github.com/Flack74/pom/clock"
+	"github.com/Flack74/pom/history"
+)
 
-	if err := os.WriteFile(logPath, data, 0644); err != nil {
-		return fmt.Errorf("failed to write log file: %v", err)
-	}
+// Session represents a single logged focus or break interval
+type Session = history.Record
 
-	return nil
+// LogInterval logs a finished focus or break interval
+func LogInterval(session Session) error {
+	return history.Append(session)
 }
 
-// GetSessionStats returns statistics about completed Pomodoro sessions
+// GetSessionStats returns statistics about completed Pomodoro sessions.
+// A session is one completed focus interval; focus minutes include the time
+// spent in interrupted intervals.
 func GetSessionStats() (totalSessions int, totalFocusMinutes float64, avgSessionsPerDay float64, err error) {
-	logPath, err := getLogFilePath()
+	sessions, err := history.Load()
 	if err != nil {
-		return 0, 0, 0, fmt.Errorf("failed to get log path: %v", err)
+		return 0, 0, 0, err
 	}
 
-	// Load sessions
-	var sessions []Session
-	data, err := os.ReadFile(logPath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return 0, 0, 0, nil
-		}
-		return 0, 0, 0, fmt.Errorf("failed to read log file: %v", err)
-	}
-
-	if err := json.Unmarshal(data, &sessions); err != nil {
-		return 0, 0, 0, fmt.Errorf("failed to parse log file: %v", err)
-	}
-
-	// Calculate statistics
-	if len(sessions) == 0 {
-		return 0, 0, 0, nil
-	}
-
-	var completedSessions []Session
+	var first, last time.Time
 	for _, session := range sessions {
-		if session.IsCompleted {
-			completedSessions = append(completedSessions, session)
-			totalSessions += session.NumSessions
-			totalFocusMinutes += float64(session.WorkMinutes * session.NumSessions)
+		if !session.IsFocus() {
+			continue
+		}
+		totalFocusMinutes += session.ActualMinutes()
+		if !session.IsCompleted() {
+			continue
+		}
+		totalSessions++
+		if first.IsZero() || session.StartTime.Before(first) {
+			first = session.StartTime
+		}
+		if session.EndTime.After(last) {
+			last = session.EndTime
 		}
 	}
 
-	if len(completedSessions) == 0 {
-		return 0, 0, 0, nil
+	if totalSessions == 0 {
+		return 0, totalFocusMinutes, 0, nil
 	}
 
 	// Calculate average sessions per day
-	firstSession := completedSessions[0].StartTime
-	lastSession := completedSessions[len(completedSessions)-1].EndTime
-	daysDiff := lastSession.Sub(firstSession).Hours() / 24
+	daysDiff := last.Sub(first).Hours() / 24
 	if daysDiff < 1 {
 		daysDiff = 1
 	}
@@ -130,38 +57,66 @@ func GetSessionStats() (totalSessions int, totalFocusMinutes float64, avgSession
 	return totalSessions, totalFocusMinutes, avgSessionsPerDay, nil
 }
 
-// GetDailyStats returns statistics for the current day
-func GetDailyStats() (sessions int, minutes int, err error) {
-	logPath, err := getLogFilePath()
+// IntervalStat totals the logged intervals that share a name, such as all
+// long breaks
+type IntervalStat struct {
+	Name      string  `json:"name"`
+	Kind      string  `json:"kind"`
+	Count     int     `json:"count"`     // Intervals logged, whatever their status
+	Completed int     `json:"completed"` // Intervals that ran to the end
+	Minutes   float64 `json:"minutes"`   // Time actually spent
+}
+
+// GetIntervalStats totals the session log by interval name, focus intervals
+// first and the most used names first within each kind. Intervals logged
+// before runs had named intervals count as "Focus" or "Break".
+func GetIntervalStats() ([]IntervalStat, error) {
+	sessions, err := history.Load()
 	if err != nil {
-		return 0, 0, fmt.Errorf("failed to get log path: %v", err)
+		return nil, err
 	}
 
-	// Load sessions
-	var allSessions []Session
-	data, err := os.ReadFile(logPath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return 0, 0, nil
+	index := make(map[string]int)
+	var stats []IntervalStat
+	for _, session := range sessions {
+		name := session.Name
+		if name == "" {
+			name = "Focus"
+			if !session.IsFocus() {
+				name = "Break"
+			}
 		}
-		return 0, 0, fmt.Errorf("failed to read log file: %v", err)
-	}
 
-	if err := json.Unmarshal(data, &allSessions); err != nil {
-		return 0, 0, fmt.Errorf("failed to parse log file: %v", err)
+		key := session.Kind + "/" + name
+		i, ok := index[key]
+		if !ok {
+			i = len(stats)
+			index[key] = i
+			stats = append(stats, IntervalStat{Name: name, Kind: session.Kind})
+		}
+		stats[i].Count++
+		stats[i].Minutes += session.ActualMinutes()
+		if session.IsCompleted() {
+			stats[i].Completed++
+		}
 	}
 
-	// Get today's date
-	today := time.Now()
-	startOfDay := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, today.Location())
-
-	// Calculate today's statistics
-	for _, session := range allSessions {
-		if session.IsCompleted && session.EndTime.After(startOfDay) {
-			sessions += session.NumSessions
-			minutes += session.WorkMinutes * session.NumSessions
+	sort.SliceStable(stats, func(a, b int) bool {
+		if stats[a].Kind != stats[b].Kind {
+			return stats[a].Kind == history.KindFocus
 		}
+		return stats[a].Minutes > stats[b].Minutes
+	})
+	return stats, nil
+}
+
+// GetDailyStats returns statistics for the current day
+func GetDailyStats() (sessions int, minutes int, err error) {
+	allSessions, err := history.Load()
+	if err != nil {
+		return 0, 0, err
 	}
 
+	sessions, minutes = history.DayTotals(allSessions, clock.Load(), time.Now())
 	return sessions, minutes, nil
 }
@@ -0,0 +1,390 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/Flack74/pom/config"
+)
+
+// Output formats
+const (
+	FormatText     = "text"
+	FormatMarkdown = "markdown"
+	FormatHTML     = "html"
+)
+
+// Render writes the report in the given format
+func Render(w io.Writer, r Report, format string) error {
+	switch format {
+	case FormatText, "":
+		return Text(w, r)
+	case FormatMarkdown, "md":
+		return Markdown(w, r)
+	case FormatHTML:
+		return HTML(w, r)
+	}
+	return fmt.Errorf("unknown format '%s', use text, markdown or html", format)
+}
+
+// Text writes the report for the terminal
+func Text(w io.Writer, r Report) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "📅 %s (%s – %s)\n\n", r.Title, r.Start.Format("Jan 2"), lastDay(r).Format("Jan 2"))
+
+	fmt.Fprintln(&b, "⏱️  Focus")
+	fmt.Fprintf(&b, "  Focus time:      %.1f h (%d min)\n", r.Totals.Hours, r.Totals.Minutes)
+	fmt.Fprintf(&b, "  Sessions:        %d of %d completed (%.0f%%)\n", r.Totals.Sessions, r.Totals.Started, r.Totals.CompletionRate*100)
+	fmt.Fprintf(&b, "  Active days:     %d of %d\n", r.Totals.ActiveDays, len(r.Days))
+	fmt.Fprintf(&b, "  vs previous:     %s\n\n", changeSummary(r))
+
+	fmt.Fprintln(&b, "🎯 Goals")
+	if r.Goal.Set {
+		fmt.Fprintf(&b, "  Daily goal:      %s\n", goalSummary(r.Goal))
+		fmt.Fprintf(&b, "  Days met:        %s\n", daysMetSummary(r.Goal))
+		if r.Goal.TargetMinutes > 0 {
+			fmt.Fprintf(&b, "  Focus target:    %d of %d min (%.0f%%)\n", r.Totals.Minutes, r.Goal.TargetMinutes, percent(r.Totals.Minutes, r.Goal.TargetMinutes))
+		}
+		if summary := periodGoalSummary(r); summary != "" {
+			fmt.Fprintf(&b, "  %-17s%s\n", adjective(r.Period)+" target:", summary)
+		}
+	} else {
+		fmt.Fprintln(&b, "  No daily goal set (pom goals set)")
+	}
+	fmt.Fprintf(&b, "  Streak:          %d → %d days (longest in period: %d)\n\n", r.Streak.Start, r.Streak.End, r.Streak.Longest)
+
+	fmt.Fprintln(&b, "📊 Days")
+	if r.BestDay != nil {
+		fmt.Fprintf(&b, "  Best day:        %s (%d min, %d sessions)\n", r.BestDay.Date.Format("Mon Jan 2"), r.BestDay.Minutes, r.BestDay.Sessions)
+	}
+	if r.WorstDay != nil {
+		fmt.Fprintf(&b, "  Worst day:       %s (%d min, %d sessions)\n", r.WorstDay.Date.Format("Mon Jan 2"), r.WorstDay.Minutes, r.WorstDay.Sessions)
+	}
+	most := 0
+	for _, day := range r.Days {
+		if day.Minutes > most {
+			most = day.Minutes
+		}
+	}
+	for _, day := range r.Days {
+		mark := goalMark(day)
+		if mark == "" {
+			mark = " "
+		}
+		fmt.Fprintf(&b, "  %s %s %-20s %4d min\n", day.Date.Format("Mon 02"), mark, bar(day.Minutes, most, 20), day.Minutes)
+	}
+	fmt.Fprintln(&b)
+
+	fmt.Fprintln(&b, "📋 Tasks")
+	if len(r.Tasks) == 0 {
+		fmt.Fprintln(&b, "  No task work in this period")
+	}
+	for _, task := range r.Tasks {
+		status := ""
+		if task.Completed {
+			status = " ✅ completed"
+		}
+		fmt.Fprintf(&b, "  • %s: %d sessions, %d min%s\n", task.Title, task.Sessions, task.Minutes, status)
+	}
+	fmt.Fprintln(&b)
+
+	fmt.Fprintln(&b, "💡 Suggestions")
+	for _, suggestion := range r.Suggestions {
+		fmt.Fprintf(&b, "  • %s\n", suggestion)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Markdown writes the report as a Markdown document
+func Markdown(w io.Writer, r Report) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", r.Title)
+	fmt.Fprintf(&b, "_%s – %s_\n\n", r.Start.Format("Mon Jan 2, 2006"), lastDay(r).Format("Mon Jan 2, 2006"))
+
+	fmt.Fprintln(&b, "## Focus")
+	fmt.Fprintln(&b)
+	fmt.Fprintln(&b, "| | This period | Previous | Change |")
+	fmt.Fprintln(&b, "|---|---|---|---|")
+	fmt.Fprintf(&b, "| Focus time | %.1f h | %.1f h | %s |\n", r.Totals.Hours, r.Previous.Hours, signed(r.Change.Minutes, " min"))
+	fmt.Fprintf(&b, "| Sessions | %d | %d | %s |\n", r.Totals.Sessions, r.Previous.Sessions, signed(r.Change.Sessions, ""))
+	fmt.Fprintf(&b, "| Completion rate | %.0f%% | %.0f%% | %+.1f pts |\n", r.Totals.CompletionRate*100, r.Previous.CompletionRate*100, r.Change.CompletionRate)
+	fmt.Fprintf(&b, "| Active days | %d | %d | %s |\n\n", r.Totals.ActiveDays, r.Previous.ActiveDays, signed(r.Totals.ActiveDays-r.Previous.ActiveDays, ""))
+
+	fmt.Fprintln(&b, "## Goals")
+	fmt.Fprintln(&b)
+	if r.Goal.Set {
+		fmt.Fprintf(&b, "- Daily goal: %s\n", goalSummary(r.Goal))
+		fmt.Fprintf(&b, "- Days met: %s\n", daysMetSummary(r.Goal))
+		if r.Goal.TargetMinutes > 0 {
+			fmt.Fprintf(&b, "- Focus target: %d of %d min (%.0f%%)\n", r.Totals.Minutes, r.Goal.TargetMinutes, percent(r.Totals.Minutes, r.Goal.TargetMinutes))
+		}
+		if summary := periodGoalSummary(r); summary != "" {
+			fmt.Fprintf(&b, "- %s target: %s\n", adjective(r.Period), summary)
+		}
+	} else {
+		fmt.Fprintln(&b, "- No daily goal set")
+	}
+	fmt.Fprintf(&b, "- Streak: %d → %d days (longest in period: %d)\n\n", r.Streak.Start, r.Streak.End, r.Streak.Longest)
+
+	fmt.Fprintln(&b, "## Days")
+	fmt.Fprintln(&b)
+	if r.BestDay != nil {
+		fmt.Fprintf(&b, "- **Best day:** %s, %d min\n", r.BestDay.Date.Format("Monday Jan 2"), r.BestDay.Minutes)
+	}
+	if r.WorstDay != nil {
+		fmt.Fprintf(&b, "- **Worst day:** %s, %d min\n", r.WorstDay.Date.Format("Monday Jan 2"), r.WorstDay.Minutes)
+	}
+	fmt.Fprintln(&b)
+	fmt.Fprintln(&b, "| Day | Sessions | Minutes | Goal |")
+	fmt.Fprintln(&b, "|---|---|---|---|")
+	for _, day := range r.Days {
+		fmt.Fprintf(&b, "| %s | %d | %d | %s |\n", day.Date.Format("Mon Jan 2"), day.Sessions, day.Minutes, goalMark(day))
+	}
+	fmt.Fprintln(&b)
+
+	fmt.Fprintln(&b, "## Tasks")
+	fmt.Fprintln(&b)
+	if len(r.Tasks) == 0 {
+		fmt.Fprintln(&b, "No task work in this period.")
+	}
+	for _, task := range r.Tasks {
+		status := ""
+		if task.Completed {
+			status = " — completed"
+		}
+		fmt.Fprintf(&b, "- %s: %d sessions, %d min%s\n", markdownEscape(task.Title), task.Sessions, task.Minutes, status)
+	}
+	fmt.Fprintln(&b)
+
+	fmt.Fprintln(&b, "## Suggestions")
+	fmt.Fprintln(&b)
+	for _, suggestion := range r.Suggestions {
+		fmt.Fprintf(&b, "- %s\n", suggestion)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// HTML writes the report as a self-contained HTML page
+func HTML(w io.Writer, r Report) error {
+	var buf bytes.Buffer
+	if err := htmlTemplate.Execute(&buf, r); err != nil {
+		return fmt.Errorf("error rendering report: %v", err)
+	}
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"date":       func(format string, r Report) string { return lastDay(r).Format(format) },
+	"change":     changeSummary,
+	"goal":       goalSummary,
+	"daysMet":    daysMetSummary,
+	"periodGoal": periodGoalSummary,
+	"adjective":  adjective,
+	"mark":       goalMark,
+	"percent":    percent,
+	"width": func(minutes int, days []Day) int {
+		most := 0
+		for _, day := range days {
+			if day.Minutes > most {
+				most = day.Minutes
+			}
+		}
+		if most == 0 {
+			return 0
+		}
+		return minutes * 100 / most
+	},
+	"pct": func(rate float64) string { return fmt.Sprintf("%.0f%%", rate*100) },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Pom review: {{.Title}}</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", sans-serif; max-width: 760px; margin: 2em auto; padding: 0 1em; color: #2d3436; }
+h1 { margin-bottom: 0; }
+.range { color: #636e72; margin-top: 0.2em; }
+.cards { display: flex; flex-wrap: wrap; gap: 1em; }
+.card { flex: 1; min-width: 140px; background: #f5f6fa; border-radius: 8px; padding: 0.8em 1em; }
+.card .value { font-size: 1.6em; font-weight: bold; }
+.card .label { color: #636e72; font-size: 0.9em; }
+table { width: 100%; border-collapse: collapse; }
+td, th { padding: 0.3em 0.5em; text-align: left; border-bottom: 1px solid #dfe6e9; }
+.bar { background: #e17055; height: 0.8em; border-radius: 4px; }
+.met { color: #00b894; }
+</style>
+</head>
+<body>
+<h1>🍅 {{.Title}}</h1>
+<p class="range">{{.Start.Format "Mon Jan 2, 2006"}} – {{date "Mon Jan 2, 2006" .}}</p>
+
+<div class="cards">
+<div class="card"><div class="value">{{printf "%.1f" .Totals.Hours}} h</div><div class="label">Focus time</div></div>
+<div class="card"><div class="value">{{.Totals.Sessions}}</div><div class="label">Sessions ({{pct .Totals.CompletionRate}} completed)</div></div>
+<div class="card"><div class="value">{{.Totals.ActiveDays}}/{{len .Days}}</div><div class="label">Active days</div></div>
+<div class="card"><div class="value">{{.Streak.End}}</div><div class="label">Day streak (was {{.Streak.Start}})</div></div>
+</div>
+<p>Compared with the previous {{.Period}}: {{change .}}</p>
+
+<h2>🎯 Goals</h2>
+{{if .Goal.Set}}<ul>
+<li>Daily goal: {{goal .Goal}}</li>
+<li>Days met: {{daysMet .Goal}}</li>
+{{if .Goal.TargetMinutes}}<li>Focus target: {{.Totals.Minutes}} of {{.Goal.TargetMinutes}} min ({{printf "%.0f" (percent .Totals.Minutes .Goal.TargetMinutes)}}%)</li>{{end}}
+{{with periodGoal .}}<li>{{adjective $.Period}} target: {{.}}</li>{{end}}
+<li>Longest streak in period: {{.Streak.Longest}} days</li>
+</ul>{{else}}<p>No daily goal set.</p>{{end}}
+
+<h2>📊 Days</h2>
+<ul>
+{{with .BestDay}}<li>Best day: {{.Date.Format "Monday Jan 2"}}, {{.Minutes}} min</li>{{end}}
+{{with .WorstDay}}<li>Worst day: {{.Date.Format "Monday Jan 2"}}, {{.Minutes}} min</li>{{end}}
+</ul>
+<table>
+<tr><th>Day</th><th>Sessions</th><th>Minutes</th><th></th><th>Goal</th></tr>
+{{range .Days}}<tr><td>{{.Date.Format "Mon Jan 2"}}</td><td>{{.Sessions}}</td><td>{{.Minutes}}</td><td style="width:40%"><div class="bar" style="width:{{width .Minutes $.Days}}%"></div></td><td class="met">{{mark .}}</td></tr>
+{{end}}</table>
+
+<h2>📋 Tasks</h2>
+{{if .Tasks}}<table>
+<tr><th>Task</th><th>Sessions</th><th>Minutes</th><th></th></tr>
+{{range .Tasks}}<tr><td>{{.Title}}</td><td>{{.Sessions}}</td><td>{{.Minutes}}</td><td class="met">{{if .Completed}}✅ completed{{end}}</td></tr>
+{{end}}</table>{{else}}<p>No task work in this period.</p>{{end}}
+
+<h2>💡 Suggestions</h2>
+<ul>
+{{range .Suggestions}}<li>{{.}}</li>
+{{end}}</ul>
+</body>
+</html>
+`))
+
+// lastDay returns the last day the report covers
+func lastDay(r Report) time.Time {
+	if len(r.Days) > 0 {
+		return r.Days[len(r.Days)-1].Date
+	}
+	return r.End.AddDate(0, 0, -1)
+}
+
+// changeSummary describes the change from the previous period
+func changeSummary(r Report) string {
+	if r.Previous.Started == 0 {
+		return "no focus sessions in the previous " + r.Period
+	}
+	summary := signed(r.Change.Minutes, " min")
+	if r.Change.MinutesPercent != 0 {
+		summary += fmt.Sprintf(" (%+.0f%%)", r.Change.MinutesPercent)
+	}
+	return fmt.Sprintf("%s, %s sessions, %+.1f pts completion", summary, signed(r.Change.Sessions, ""), r.Change.CompletionRate)
+}
+
+// goalSummary describes the daily goal
+func goalSummary(goal GoalReview) string {
+	var parts []string
+	if goal.DailySessions > 0 {
+		parts = append(parts, fmt.Sprintf("%d sessions", goal.DailySessions))
+	}
+	if goal.DailyMinutes > 0 {
+		parts = append(parts, fmt.Sprintf("%d min", goal.DailyMinutes))
+	}
+	summary := strings.Join(parts, " and ")
+	if summary == "" {
+		summary = "1 session"
+	}
+	if goal.ByWeekday {
+		summary += " (varies by weekday)"
+	}
+	return summary
+}
+
+// daysMetSummary describes the days that met the goal, e.g. "4 of 5 (1 frozen, 2 rest days)"
+func daysMetSummary(goal GoalReview) string {
+	summary := fmt.Sprintf("%d of %d", goal.DaysMet, goal.Days)
+	var notes []string
+	if goal.DaysFrozen > 0 {
+		notes = append(notes, fmt.Sprintf("%d frozen", goal.DaysFrozen))
+	}
+	if goal.RestDays == 1 {
+		notes = append(notes, "1 rest day")
+	} else if goal.RestDays > 1 {
+		notes = append(notes, fmt.Sprintf("%d rest days", goal.RestDays))
+	}
+	if len(notes) > 0 {
+		summary += " (" + strings.Join(notes, ", ") + ")"
+	}
+	return summary
+}
+
+// periodGoalSummary describes the progress towards the weekly or monthly
+// target, or returns "" if there is none
+func periodGoalSummary(r Report) string {
+	var parts []string
+	if r.Goal.PeriodSessions > 0 {
+		parts = append(parts, fmt.Sprintf("%d of %d sessions", r.Totals.Sessions, r.Goal.PeriodSessions))
+	}
+	if r.Goal.PeriodMinutes > 0 {
+		parts = append(parts, fmt.Sprintf("%d of %d min", r.Totals.Minutes, r.Goal.PeriodMinutes))
+	}
+	summary := strings.Join(parts, ", ")
+	if summary != "" && r.Totals.Sessions >= r.Goal.PeriodSessions && r.Totals.Minutes >= r.Goal.PeriodMinutes {
+		summary += " ✓"
+	}
+	return summary
+}
+
+// adjective returns "Weekly" or "Monthly"
+func adjective(period string) string {
+	if period == Week {
+		return "Weekly"
+	}
+	return "Monthly"
+}
+
+// goalMark marks a day's goal status
+func goalMark(day Day) string {
+	switch day.Status {
+	case config.DayMet:
+		return "✓"
+	case config.DayFrozen:
+		return "❄"
+	case config.DayRest:
+		return "–"
+	}
+	return ""
+}
+
+// signed formats n with an explicit sign
+func signed(n int, unit string) string {
+	return fmt.Sprintf("%+d%s", n, unit)
+}
+
+// percent returns part as a percentage of whole
+func percent(part, whole int) float64 {
+	if whole == 0 {
+		return 0
+	}
+	return float64(part) * 100 / float64(whole)
+}
+
+// bar draws a bar of up to width blocks
+func bar(value, max, width int) string {
+	if max == 0 {
+		return ""
+	}
+	return strings.Repeat("█", value*width/max)
+}
+
+// markdownEscape escapes the characters that would format a task title
+func markdownEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "[", `\[`, "]", `\]`, "|", `\|`).Replace(s)
+}
@@ -0,0 +1,403 @@
+// Package report builds weekly and monthly reviews of the session log
+package report
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/Flack74/pom/clock"
+	"github.com/Flack74/pom/config"
+	"github.com/Flack74/pom/history"
+)
+
+// Report periods
+const (
+	Week  = "week"
+	Month = "month"
+)
+
+// Totals sums the focus intervals of a period
+type Totals struct {
+	Sessions       int     `json:"sessions"` // Completed focus intervals
+	Started        int     `json:"started"`  // All focus intervals
+	Minutes        int     `json:"minutes"`
+	Hours          float64 `json:"hours"`
+	ActiveDays     int     `json:"active_days"` // Days with a completed focus interval
+	CompletionRate float64 `json:"completion_rate"`
+}
+
+// Day is one day of a report
+type Day struct {
+	Date     time.Time `json:"date"`
+	Sessions int       `json:"sessions"`
+	Minutes  int       `json:"minutes"`
+	GoalMet  bool      `json:"goal_met"`
+	Status   string    `json:"status"` // Goal history status: met, missed, rest, frozen or pending
+}
+
+// Comparison is the change from the previous period
+type Comparison struct {
+	Sessions       int     `json:"sessions"`
+	Minutes        int     `json:"minutes"`
+	MinutesPercent float64 `json:"minutes_percent"` // 0 if the previous period had no focus time
+	CompletionRate float64 `json:"completion_rate"` // Change in percentage points
+}
+
+// GoalReview compares the period with the goals
+type GoalReview struct {
+	Set            bool `json:"set"`
+	DailySessions  int  `json:"daily_sessions"`
+	DailyMinutes   int  `json:"daily_minutes"`
+	ByWeekday      bool `json:"by_weekday"`      // Some weekdays have their own target or are rest days
+	TargetMinutes  int  `json:"target_minutes"`  // Sum of the daily targets of the days so far
+	TargetSessions int  `json:"target_sessions"` // Sum of the daily targets of the days so far
+	DaysMet        int  `json:"days_met"`
+	DaysFrozen     int  `json:"days_frozen"` // Missed days covered by a streak freeze
+	Days           int  `json:"days"`        // Days of the period so far, not counting rest days
+	RestDays       int  `json:"rest_days"`
+	PeriodSessions int  `json:"period_sessions"` // Weekly or monthly target
+	PeriodMinutes  int  `json:"period_minutes"`
+}
+
+// TaskProgress is the focus time a task got in the period
+type TaskProgress struct {
+	ID        string   `json:"id"`
+	Title     string   `json:"title"`
+	Tags      []string `json:"tags,omitempty"`
+	Sessions  int      `json:"sessions"`
+	Minutes   int      `json:"minutes"`
+	Completed bool     `json:"completed"` // Completed during the period
+}
+
+// Streak is the goal streak at the start and end of the period, as kept by
+// the goal history. A day counts if it met its target, or had a completed
+// focus interval when no goal is set.
+type Streak struct {
+	Start   int `json:"start"`
+	End     int `json:"end"`
+	Longest int `json:"longest"` // Longest streak reached within the period
+}
+
+// Report is a review of a week or month
+type Report struct {
+	Period      string         `json:"period"` // Week or Month
+	Title       string         `json:"title"`
+	Start       time.Time      `json:"start"`
+	End         time.Time      `json:"end"` // Exclusive
+	Totals      Totals         `json:"totals"`
+	Previous    Totals         `json:"previous"`
+	Change      Comparison     `json:"change"`
+	Goal        GoalReview     `json:"goal"`
+	Days        []Day          `json:"days"` // Days of the period so far
+	BestDay     *Day           `json:"best_day,omitempty"`
+	WorstDay    *Day           `json:"worst_day,omitempty"`
+	Tasks       []TaskProgress `json:"tasks"`
+	Streak      Streak         `json:"streak"`
+	Suggestions []string       `json:"suggestions"`
+}
+
+// Bounds returns the start and exclusive end of the period containing date.
+// Weeks start on Monday.
+func Bounds(period string, date time.Time) (time.Time, time.Time, error) {
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+	switch period {
+	case Week:
+		start := day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+		return start, start.AddDate(0, 0, 7), nil
+	case Month:
+		start := time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, day.Location())
+		return start, start.AddDate(0, 1, 0), nil
+	}
+	return time.Time{}, time.Time{}, fmt.Errorf("unknown period '%s', use week or month", period)
+}
+
+// ParseDate parses a YYYY-MM-DD date, or returns today under the configured
+// day rules if value is empty
+func ParseDate(value string) (time.Time, error) {
+	rules := clock.Load()
+	if value == "" {
+		return rules.Today(), nil
+	}
+	date, err := time.ParseInLocation("2006-01-02", value, rules.Local())
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date '%s', use YYYY-MM-DD", value)
+	}
+	return date, nil
+}
+
+// Build reviews the week or month containing the calendar date of date
+func Build(period string, date time.Time) (Report, error) {
+	if _, _, err := Bounds(period, date); err != nil {
+		return Report{}, err
+	}
+	records, err := history.Load()
+	if err != nil {
+		return Report{}, err
+	}
+	goal, err := config.LoadGoal()
+	if err != nil {
+		return Report{}, err
+	}
+	tasks, err := config.LoadTasks()
+	if err != nil {
+		return Report{}, err
+	}
+	return build(period, date, time.Now(), clock.Load(), records, goal, tasks.Tasks), nil
+}
+
+// build reviews the period containing date as seen at now. Periods are
+// made of whole days under the day rules.
+func build(period string, date, now time.Time, rules clock.Rules, records []history.Record, goal config.Goal, tasks []config.Task) Report {
+	date = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, rules.Local())
+	start, end, _ := Bounds(period, date)
+	prevStart, _, _ := Bounds(period, start.AddDate(0, 0, -1))
+
+	report := Report{
+		Period: period,
+		Start:  start,
+		End:    end,
+		Tasks:  []TaskProgress{},
+	}
+	if period == Week {
+		report.Title = "Week of " + start.Format("Jan 2, 2006")
+	} else {
+		report.Title = start.Format("January 2006")
+	}
+
+	// Only count the days so far in the current period
+	last := end
+	today := rules.Day(now)
+	if today.Before(end) {
+		last = today.AddDate(0, 0, 1)
+	}
+	prevLast := prevStart.AddDate(0, 0, daysBetween(start, last))
+	if prevLast.After(start) {
+		prevLast = start
+	}
+
+	report.Totals = totals(records, rules, start, last)
+	report.Previous = totals(records, rules, prevStart, prevLast)
+	report.Change = compare(report.Totals, report.Previous)
+
+	report.Goal = GoalReview{
+		Set:            goal.IsSet(),
+		DailySessions:  goal.DailySessionTarget,
+		DailyMinutes:   goal.DailyMinutes,
+		ByWeekday:      len(goal.Weekdays) > 0 || len(goal.RestDays) > 0,
+		PeriodSessions: goal.WeeklySessions,
+		PeriodMinutes:  goal.WeeklyMinutes,
+	}
+	if period == Month {
+		report.Goal.PeriodSessions, report.Goal.PeriodMinutes = goal.MonthlySessions, goal.MonthlyMinutes
+	}
+
+	goalDays := map[string]config.GoalDay{}
+	for _, day := range config.GoalDays(records, goal, rules, now) {
+		goalDays[day.Date.Format("2006-01-02")] = day
+	}
+	report.Streak.Start = goalDays[start.AddDate(0, 0, -1).Format("2006-01-02")].Streak
+	report.Streak.End = report.Streak.Start
+	for day := start; day.Before(last); day = day.AddDate(0, 0, 1) {
+		sessions, minutes := history.DayTotals(records, rules, rules.Start(day))
+		judged, ok := goalDays[day.Format("2006-01-02")]
+		if !ok {
+			// Before the first logged session
+			target, rest := goal.TargetFor(day)
+			judged = config.GoalDay{Target: target, Status: config.DayMissed}
+			if rest {
+				judged.Status = config.DayRest
+			}
+		}
+		entry := Day{Date: day, Sessions: sessions, Minutes: minutes, GoalMet: judged.Status == config.DayMet, Status: judged.Status}
+		report.Days = append(report.Days, entry)
+
+		switch judged.Status {
+		case config.DayRest:
+			report.Goal.RestDays++
+			continue
+		case config.DayMet:
+			report.Goal.DaysMet++
+		case config.DayFrozen:
+			report.Goal.DaysFrozen++
+		}
+		report.Goal.Days++
+		report.Goal.TargetSessions += judged.Target.Sessions
+		report.Goal.TargetMinutes += judged.Target.Minutes
+		report.Streak.End = judged.Streak
+		if judged.Streak > report.Streak.Longest {
+			report.Streak.Longest = judged.Streak
+		}
+	}
+	report.BestDay, report.WorstDay = bestAndWorst(report.Days)
+
+	report.Tasks = taskProgress(records, tasks, rules, start, last)
+	report.Suggestions = suggestions(report, records, rules, start, last, now)
+	return report
+}
+
+// totals sums the focus intervals that ended on the days from start up to end
+func totals(records []history.Record, rules clock.Rules, start, end time.Time) Totals {
+	var t Totals
+	seconds := 0
+	days := map[string]bool{}
+	for _, record := range records {
+		if !record.IsFocus() || !within(rules, record.EndTime, start, end) {
+			continue
+		}
+		t.Started++
+		seconds += record.ActualSeconds
+		if record.IsCompleted() {
+			t.Sessions++
+			days[rules.Key(record.EndTime)] = true
+		}
+	}
+	t.Minutes = seconds / 60
+	t.Hours = math.Round(float64(seconds)/360) / 10
+	t.ActiveDays = len(days)
+	if t.Started > 0 {
+		t.CompletionRate = float64(t.Sessions) / float64(t.Started)
+	}
+	return t
+}
+
+// compare returns the change from previous to current
+func compare(current, previous Totals) Comparison {
+	change := Comparison{
+		Sessions:       current.Sessions - previous.Sessions,
+		Minutes:        current.Minutes - previous.Minutes,
+		CompletionRate: math.Round((current.CompletionRate-previous.CompletionRate)*1000) / 10,
+	}
+	if previous.Minutes > 0 {
+		change.MinutesPercent = math.Round(float64(change.Minutes)/float64(previous.Minutes)*1000) / 10
+	}
+	return change
+}
+
+// bestAndWorst returns the days with the most and least focus time
+func bestAndWorst(days []Day) (*Day, *Day) {
+	if len(days) == 0 {
+		return nil, nil
+	}
+	best, worst := 0, 0
+	for i, day := range days {
+		if busier(day, days[best]) {
+			best = i
+		}
+		if busier(days[worst], day) {
+			worst = i
+		}
+	}
+	if days[best].Minutes == 0 {
+		return nil, nil
+	}
+	bestDay, worstDay := days[best], days[worst]
+	if best == worst {
+		return &bestDay, nil
+	}
+	return &bestDay, &worstDay
+}
+
+// busier reports whether a had more focus time than b, or as much with more
+// completed sessions
+func busier(a, b Day) bool {
+	if a.Minutes != b.Minutes {
+		return a.Minutes > b.Minutes
+	}
+	return a.Sessions > b.Sessions
+}
+
+// within reports whether t falls on the days from start up to end
+func within(rules clock.Rules, t, start, end time.Time) bool {
+	return !t.Before(rules.Start(start)) && t.Before(rules.Start(end))
+}
+
+// daysBetween returns the number of calendar days from start to end
+func daysBetween(start, end time.Time) int {
+	days := 0
+	for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+		days++
+	}
+	return days
+}
+
+// taskProgress returns the tasks worked on or completed on the days from
+// start up to end, most focus time first
+func taskProgress(records []history.Record, tasks []config.Task, rules clock.Rules, start, end time.Time) []TaskProgress {
+	byID := map[string]*TaskProgress{}
+	progress := func(task config.Task) *TaskProgress {
+		entry, ok := byID[task.ID]
+		if !ok {
+			entry = &TaskProgress{ID: task.ID, Title: task.Title, Tags: task.Tags}
+			byID[task.ID] = entry
+		}
+		return entry
+	}
+
+	titles := make(map[string]config.Task, len(tasks))
+	for _, task := range tasks {
+		titles[task.ID] = task
+		if task.IsCompleted && within(rules, task.CompletedAt, start, end) {
+			progress(task).Completed = true
+		}
+	}
+	for _, record := range records {
+		if !record.IsFocus() || record.TaskID == "" || !within(rules, record.EndTime, start, end) {
+			continue
+		}
+		task, ok := titles[record.TaskID]
+		if !ok {
+			task = config.Task{ID: record.TaskID, Title: "(deleted task)"}
+		}
+		entry := progress(task)
+		entry.Minutes += record.ActualSeconds / 60
+		if record.IsCompleted() {
+			entry.Sessions++
+		}
+	}
+
+	result := make([]TaskProgress, 0, len(byID))
+	for _, entry := range byID {
+		result = append(result, *entry)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Minutes != result[j].Minutes {
+			return result[i].Minutes > result[j].Minutes
+		}
+		return result[i].Title < result[j].Title
+	})
+	return result
+}
+
+// suggestions combines observations about the period with the insights
+// engine's suggestions for its sessions
+func suggestions(report Report, records []history.Record, rules clock.Rules, start, end, now time.Time) []string {
+	var result []string
+	if report.Totals.Started == 0 {
+		return []string{"No focus sessions in this period. Plan one short session a day to get back into the rhythm."}
+	}
+	if report.Goal.Set && report.Goal.Days > 0 && report.Goal.DaysMet*2 < report.Goal.Days {
+		result = append(result, fmt.Sprintf("The daily goal was met on %d of %d days. Consider a smaller goal you can hit every day.",
+			report.Goal.DaysMet, report.Goal.Days))
+	}
+	if report.Previous.Minutes > 0 && report.Change.MinutesPercent <= -20 {
+		result = append(result, fmt.Sprintf("Focus time fell %.0f%% from the previous %s. Block out fixed focus times in your calendar.",
+			-report.Change.MinutesPercent, report.Period))
+	}
+	if report.WorstDay != nil && report.WorstDay.Minutes == 0 && report.BestDay != nil {
+		result = append(result, fmt.Sprintf("%s was your strongest day; %s had no focus time.",
+			report.BestDay.Date.Format("Monday Jan 2"), report.WorstDay.Date.Format("Monday Jan 2")))
+	}
+
+	var period []history.Record
+	for _, record := range records {
+		if within(rules, record.EndTime, start, end) {
+			period = append(period, record)
+		}
+	}
+	for _, suggestion := range config.SuggestFor(period, now) {
+		result = append(result, suggestion.Message+".")
+	}
+	return result
+}
@@ -0,0 +1,247 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// MaxBackups is the number of rolling backups kept in the backups directory
+	MaxBackups = 10
+	// BackupInterval is the minimum time between automatic backups
+	BackupInterval = time.Hour
+
+	backupPrefix = "pom-"
+	backupSuffix = ".db"
+	backupLayout = "20060102-150405"
+
+	// lastGoodName is the copy of the database refreshed on every write. It
+	// does not match backupPrefix, so it is never pruned or listed as a
+	// rolling backup.
+	lastGoodName = "last-good.db"
+)
+
+// BackupDir returns the directory holding database backups
+func BackupDir() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+
+	backupDir := filepath.Join(dir, "backups")
+	if err := os.MkdirAll(backupDir, 0755); err != nil {
+		return "", err
+	}
+
+	return backupDir, nil
+}
+
+// Backups returns the paths of all backups, newest first
+func Backups() ([]string, error) {
+	backupDir, err := BackupDir()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(backupDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var backups []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, backupPrefix) || !strings.HasSuffix(name, backupSuffix) {
+			continue
+		}
+		backups = append(backups, filepath.Join(backupDir, name))
+	}
+
+	// Timestamped names sort chronologically
+	sort.Sort(sort.Reverse(sort.StringSlice(backups)))
+	return backups, nil
+}
+
+// BackupTime returns the time a backup was taken, parsed from its file name
+func BackupTime(path string) (time.Time, error) {
+	name := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(path), backupPrefix), backupSuffix)
+	return time.ParseInLocation(backupLayout, name, time.Local)
+}
+
+// Backup copies the current database into the backups directory and prunes old backups
+func Backup() (string, error) {
+	var backupPath string
+	err := View(func(tx *Tx) error {
+		path, err := Path()
+		if err != nil {
+			return err
+		}
+		backupPath, err = backupFile(path)
+		return err
+	})
+	return backupPath, err
+}
+
+// LastGood returns the path of the copy of the database saved after the
+// latest successful write, or "" if there is none yet
+func LastGood() (string, error) {
+	backupDir, err := BackupDir()
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(backupDir, lastGoodName)
+	if _, err := os.Stat(path); err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	return path, nil
+}
+
+// saveLastGood replaces the last-good copy with data, which has just been
+// written to the database. It is called with the lock held.
+func saveLastGood(data []byte) {
+	backupDir, err := BackupDir()
+	if err == nil {
+		err = WriteFileAtomic(filepath.Join(backupDir, lastGoodName), data, 0600)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  Failed to save last-good copy of database: %v\n", err)
+	}
+}
+
+// maybeBackup takes an automatic backup of the database file if the newest
+// one is older than BackupInterval. It is called with the lock held, before
+// the file is replaced, so the backup holds the database as it was before
+// this write; it may be up to BackupInterval old. The last-good copy kept by
+// saveLastGood is what tracks every write.
+func maybeBackup(path string) {
+	if _, err := os.Stat(path); err != nil {
+		return
+	}
+
+	backups, err := Backups()
+	if err == nil && len(backups) > 0 {
+		if taken, err := BackupTime(backups[0]); err == nil && time.Since(taken) < BackupInterval {
+			return
+		}
+	}
+
+	if _, err := backupFile(path); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  Failed to back up database: %v\n", err)
+	}
+}
+
+// backupFile copies the database file to a new timestamped backup
+func backupFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read database: %v", err)
+	}
+
+	backupDir, err := BackupDir()
+	if err != nil {
+		return "", err
+	}
+
+	backupPath := filepath.Join(backupDir, backupPrefix+time.Now().Format(backupLayout)+backupSuffix)
+	if err := WriteFileAtomic(backupPath, data, 0600); err != nil {
+		return "", fmt.Errorf("failed to write backup: %v", err)
+	}
+
+	pruneBackups()
+	return backupPath, nil
+}
+
+// pruneBackups removes all but the newest MaxBackups backups
+func pruneBackups() {
+	backups, err := Backups()
+	if err != nil {
+		return
+	}
+	for i := MaxBackups; i < len(backups); i++ {
+		os.Remove(backups[i])
+	}
+}
+
+// Verify checks that the database file can be parsed and that every key holds valid JSON
+func Verify() error {
+	return withLock(false, func(path string) error {
+		return verifyFile(path)
+	})
+}
+
+// verifyFile checks a database or backup file without touching the live database
+func verifyFile(path string) error {
+	doc, err := readDocument(path)
+	if err != nil {
+		return err
+	}
+	for key, raw := range doc.Data {
+		if !json.Valid(raw) {
+			return fmt.Errorf("key %s holds invalid JSON", key)
+		}
+	}
+	return nil
+}
+
+// Inspect runs fn in a read-only transaction over a backup file
+func Inspect(backupPath string, fn func(tx *Tx) error) error {
+	doc, err := readDocument(backupPath)
+	if err != nil {
+		return err
+	}
+	return fn(&Tx{doc: doc})
+}
+
+// Restore replaces the live database with a backup. The file being replaced
+// is kept next to it with a .corrupt suffix.
+func Restore(backupPath string) error {
+	if err := verifyFile(backupPath); err != nil {
+		return fmt.Errorf("backup %s is not usable: %v", filepath.Base(backupPath), err)
+	}
+
+	data, err := os.ReadFile(backupPath)
+	if err != nil {
+		return err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	return withLock(true, func(path string) error {
+		if _, err := os.Stat(path); err == nil {
+			os.Rename(path, path+".corrupt-"+time.Now().Format(backupLayout))
+		}
+		return WriteFileAtomic(path, data, 0600)
+	})
+}
+
+// RestoreKey copies a single key from a backup into the live database
+func RestoreKey(key, backupPath string) error {
+	var raw json.RawMessage
+	err := Inspect(backupPath, func(tx *Tx) error {
+		value, ok := tx.doc.Data[key]
+		if !ok {
+			return fmt.Errorf("backup has no %s data", key)
+		}
+		raw = value
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return Update(func(tx *Tx) error {
+		tx.doc.Data[key] = raw
+		tx.dirty = true
+		return nil
+	})
+}
@@ -0,0 +1,165 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Keys used by the subsystems that keep their state in the database
+const (
+	KeyConfig   = "config"
+	KeyProfiles = "profiles"
+	KeyTasks    = "tasks"
+	KeyGoal     = "goal"
+	KeyGoals    = "goals" // Named goals
+	KeyProgress = "progress"
+	KeyPlugins  = "plugins"
+	KeyTheme    = "theme"
+	KeySessions = "sessions"
+	KeyTimer    = "timer"
+	KeyAdaptive = "adaptive"
+
+	KeyAchievements = "achievements"
+)
+
+// migrations[i] upgrades a document from schema version i to i+1
+var migrations = []func(tx *Tx) error{
+	importLegacyFiles,
+	normalizeTimestamps,
+}
+
+// ensureMigrated brings the database up to SchemaVersion once per process
+func ensureMigrated() error {
+	migrateMu.Lock()
+	defer migrateMu.Unlock()
+
+	if migrated {
+		return nil
+	}
+
+	err := update(func(tx *Tx) error {
+		for tx.doc.Version < SchemaVersion {
+			if err := migrations[tx.doc.Version](tx); err != nil {
+				return fmt.Errorf("failed to migrate database to version %d: %v", tx.doc.Version+1, err)
+			}
+			tx.doc.Version++
+			tx.dirty = true
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	migrated = true
+	return nil
+}
+
+// legacyFiles maps database keys to the JSON files that held them before the
+// datastore existed, relative to the home directory
+var legacyFiles = map[string]string{
+	KeyConfig:   ".pomorc",
+	KeyProfiles: filepath.Join(".config", "pom", "profiles.json"),
+	KeyTasks:    filepath.Join(".config", "pom", "tasks.json"),
+	KeyGoal:     filepath.Join(".config", "pom", "goals.json"),
+	KeyProgress: filepath.Join(".config", "pom", "progress.json"),
+	KeyPlugins:  filepath.Join(".config", "pom", "plugins.json"),
+	KeyTheme:    filepath.Join(".config", "pom", "theme.json"),
+	KeySessions: filepath.Join(".config", "pom", "logs", "sessions.json"),
+}
+
+// importLegacyFiles copies the old per-subsystem JSON files into the
+// database. The files themselves are left in place as a fallback copy.
+func importLegacyFiles(tx *Tx) error {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return err
+	}
+
+	for key, rel := range legacyFiles {
+		path := filepath.Join(homeDir, rel)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return fmt.Errorf("failed to read %s: %v", path, err)
+		}
+
+		if len(data) == 0 {
+			continue
+		}
+		if !json.Valid(data) {
+			fmt.Fprintf(os.Stderr, "⚠️  Skipping unreadable %s during migration\n", path)
+			continue
+		}
+
+		tx.doc.Data[key] = json.RawMessage(data)
+	}
+
+	return nil
+}
+
+// timestampFields are the JSON fields holding timestamps in the keys that
+// normalizeTimestamps rewrites
+var timestampFields = map[string]bool{
+	"start_time":       true, // Session log
+	"end_time":         true,
+	"created_at":       true, // Tasks
+	"completed_at":     true,
+	"start_date":       true, // Goal
+	"end_date":         true,
+	"current_date":     true, // Goal progress
+	"last_update_date": true,
+	"time":             true, // Adaptive log
+	"phase_start":      true, // Timer
+	"segment_start":    true,
+	"paused_at":        true,
+	"updated_at":       true,
+}
+
+// normalizeTimestamps rewrites the stored timestamps, which were saved with
+// the local offset of whichever machine wrote them, in UTC
+func normalizeTimestamps(tx *Tx) error {
+	for _, key := range []string{KeySessions, KeyTasks, KeyGoal, KeyProgress, KeyAdaptive, KeyTimer} {
+		raw, ok := tx.doc.Data[key]
+		if !ok {
+			continue
+		}
+		var value interface{}
+		if err := json.Unmarshal(raw, &value); err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  Skipping unreadable %s during migration\n", key)
+			continue
+		}
+		data, err := json.Marshal(toUTC(value))
+		if err != nil {
+			return err
+		}
+		tx.doc.Data[key] = data
+	}
+	return nil
+}
+
+// toUTC converts the timestamp fields anywhere in a decoded JSON value to UTC
+func toUTC(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for field, child := range v {
+			if text, ok := child.(string); ok && timestampFields[field] {
+				if t, err := time.Parse(time.RFC3339Nano, text); err == nil {
+					v[field] = t.UTC().Format(time.RFC3339Nano)
+				}
+				continue
+			}
+			v[field] = toUTC(child)
+		}
+	case []interface{}:
+		for i, child := range v {
+			v[i] = toUTC(child)
+		}
+	}
+	return value
+}
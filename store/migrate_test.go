@@ -0,0 +1,181 @@
+package store
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// freshHome points HOME at an empty temporary directory and makes the next
+// transaction run the migrations again
+func freshHome(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	migrateMu.Lock()
+	migrated = false
+	migrateMu.Unlock()
+	t.Cleanup(func() {
+		migrateMu.Lock()
+		migrated = false
+		migrateMu.Unlock()
+	})
+	return home
+}
+
+// writeFile creates the file at rel under home with contents
+func writeFile(t *testing.T, home, rel, contents string) {
+	t.Helper()
+	path := filepath.Join(home, rel)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// readDisk parses the database file at path
+func readDisk(t *testing.T, path string) document {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", path, err)
+	}
+	var doc document
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("failed to parse %s: %v", path, err)
+	}
+	return doc
+}
+
+func TestMigrateLegacyFiles(t *testing.T) {
+	home := freshHome(t)
+
+	config := `{"work_duration":"25m","break_duration":"5m"}`
+	writeFile(t, home, ".pomorc", config)
+	writeFile(t, home, ".config/pom/tasks.json",
+		`[{"id":"1","title":"Write report","created_at":"2026-03-10T09:30:00+02:00","completed_at":null}]`)
+	writeFile(t, home, ".config/pom/logs/sessions.json",
+		`[{"start_time":"2026-03-10T20:00:00-05:00","end_time":"2026-03-10T20:25:00-05:00","type":"work"}]`)
+	writeFile(t, home, ".config/pom/profiles.json", `{"broken":`)
+	writeFile(t, home, ".config/pom/theme.json", ``)
+
+	var gotConfig map[string]string
+	var gotTasks, gotSessions []map[string]interface{}
+	var keys []string
+	err := View(func(tx *Tx) error {
+		keys = tx.Keys()
+		if _, err := tx.Get(KeyConfig, &gotConfig); err != nil {
+			return err
+		}
+		if _, err := tx.Get(KeyTasks, &gotTasks); err != nil {
+			return err
+		}
+		_, err := tx.Get(KeySessions, &gotSessions)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("View failed: %v", err)
+	}
+
+	// Unreadable and empty files are skipped
+	if want := []string{KeyConfig, KeySessions, KeyTasks}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("imported keys %v, want %v", keys, want)
+	}
+	if gotConfig["work_duration"] != "25m" || gotConfig["break_duration"] != "5m" {
+		t.Errorf("imported config %v, want the contents of .pomorc", gotConfig)
+	}
+	if len(gotTasks) != 1 || gotTasks[0]["title"] != "Write report" {
+		t.Fatalf("imported tasks %v, want the task from tasks.json", gotTasks)
+	}
+	if got := gotTasks[0]["created_at"]; got != "2026-03-10T07:30:00Z" {
+		t.Errorf("task created_at = %v, want it in UTC", got)
+	}
+	if gotTasks[0]["completed_at"] != nil {
+		t.Errorf("task completed_at = %v, want null kept", gotTasks[0]["completed_at"])
+	}
+	if len(gotSessions) != 1 {
+		t.Fatalf("imported sessions %v, want the session from sessions.json", gotSessions)
+	}
+	if got := gotSessions[0]["start_time"]; got != "2026-03-11T01:00:00Z" {
+		t.Errorf("session start_time = %v, want it in UTC", got)
+	}
+	if got := gotSessions[0]["end_time"]; got != "2026-03-11T01:25:00Z" {
+		t.Errorf("session end_time = %v, want it in UTC", got)
+	}
+
+	// The legacy files stay behind as a fallback copy
+	if data, err := os.ReadFile(filepath.Join(home, ".pomorc")); err != nil || string(data) != config {
+		t.Errorf(".pomorc was changed by the migration")
+	}
+
+	path, err := Path()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc := readDisk(t, path); doc.Version != SchemaVersion {
+		t.Errorf("database saved at version %d, want %d", doc.Version, SchemaVersion)
+	}
+
+	// The migrated database is also the last-good copy
+	lastGood, err := LastGood()
+	if err != nil || lastGood == "" {
+		t.Fatalf("LastGood() = %q, %v, want the copy written by the migration", lastGood, err)
+	}
+	saved, _ := os.ReadFile(path)
+	if copied, _ := os.ReadFile(lastGood); string(copied) != string(saved) {
+		t.Errorf("last-good copy differs from the migrated database")
+	}
+}
+
+func TestMigrateBacksUpOldDatabase(t *testing.T) {
+	home := freshHome(t)
+
+	// A version 1 database, written before timestamps were stored in UTC
+	old := `{"version":1,"data":{"timer":{"phase_start":"2026-03-10T10:00:00+01:00","remaining":300}}}`
+	writeFile(t, home, ".config/pom/pom.db", old)
+	// Legacy files are only imported into a version 0 database
+	writeFile(t, home, ".pomorc", `{"work_duration":"50m"}`)
+
+	var timer map[string]interface{}
+	var hasConfig bool
+	err := View(func(tx *Tx) error {
+		var err error
+		if hasConfig, err = tx.Get(KeyConfig, &map[string]string{}); err != nil {
+			return err
+		}
+		_, err = tx.Get(KeyTimer, &timer)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("View failed: %v", err)
+	}
+	if hasConfig {
+		t.Errorf("legacy .pomorc was imported into a version 1 database")
+	}
+	if got := timer["phase_start"]; got != "2026-03-10T09:00:00Z" {
+		t.Errorf("timer phase_start = %v, want it in UTC", got)
+	}
+	if timer["remaining"] != float64(300) {
+		t.Errorf("timer remaining = %v, want 300 kept", timer["remaining"])
+	}
+
+	// The database as it was before the migration is kept as a backup
+	backups, err := Backups()
+	if err != nil {
+		t.Fatalf("Backups failed: %v", err)
+	}
+	if len(backups) != 1 {
+		t.Fatalf("found %d backups, want 1 taken before the migration", len(backups))
+	}
+	if data, _ := os.ReadFile(backups[0]); string(data) != old {
+		t.Errorf("backup holds %s, want the version 1 database", data)
+	}
+	if doc := readDisk(t, backups[0]); doc.Version != 1 {
+		t.Errorf("backup is at version %d, want 1", doc.Version)
+	}
+}
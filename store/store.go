@@ -0,0 +1,226 @@
+// Package store is pom's embedded datastore. All persistent state lives in a
+// single JSON document (~/.config/pom/pom.db) made of named keys, one per
+// subsystem. Every read and write happens inside a transaction that holds an
+// OS-level file lock, so a CLI timer and the web server can run side by side
+// without clobbering each other's changes.
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// SchemaVersion is the current layout version of the database document
+const SchemaVersion = 2
+
+// document is the on-disk representation of the database
+type document struct {
+	Version int                        `json:"version"`
+	Data    map[string]json.RawMessage `json:"data"`
+}
+
+// Tx is a transaction over the database. Changes made through Put and
+// Delete only reach disk if the transaction function returns nil.
+type Tx struct {
+	doc      *document
+	writable bool
+	dirty    bool
+}
+
+var (
+	// mu serializes transactions within this process; the file lock
+	// serializes them across processes.
+	mu        sync.RWMutex
+	migrateMu sync.Mutex
+	migrated  bool
+)
+
+// Dir returns the directory holding the database
+func Dir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	dir := filepath.Join(homeDir, ".config", "pom")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	return dir, nil
+}
+
+// Path returns the path to the database file
+func Path() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "pom.db"), nil
+}
+
+// View runs fn in a read-only transaction
+func View(fn func(tx *Tx) error) error {
+	if err := ensureMigrated(); err != nil {
+		return err
+	}
+
+	mu.RLock()
+	defer mu.RUnlock()
+
+	return withLock(false, func(path string) error {
+		doc, err := readDocument(path)
+		if err != nil {
+			return err
+		}
+		return fn(&Tx{doc: doc})
+	})
+}
+
+// Update runs fn in a read-write transaction. If fn returns an error, none
+// of its changes are written.
+func Update(fn func(tx *Tx) error) error {
+	if err := ensureMigrated(); err != nil {
+		return err
+	}
+	return update(fn)
+}
+
+func update(fn func(tx *Tx) error) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	return withLock(true, func(path string) error {
+		doc, err := readDocument(path)
+		if err != nil {
+			return err
+		}
+
+		tx := &Tx{doc: doc, writable: true}
+		if err := fn(tx); err != nil {
+			return err
+		}
+		if !tx.dirty {
+			return nil
+		}
+		maybeBackup(path)
+		return writeDocument(path, doc)
+	})
+}
+
+// Get decodes the value stored under key into v. It reports whether the key exists.
+func (tx *Tx) Get(key string, v interface{}) (bool, error) {
+	raw, ok := tx.doc.Data[key]
+	if !ok {
+		return false, nil
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return true, fmt.Errorf("failed to decode %s: %v", key, err)
+	}
+	return true, nil
+}
+
+// Put stores v under key
+func (tx *Tx) Put(key string, v interface{}) error {
+	if !tx.writable {
+		return fmt.Errorf("cannot write %s in a read-only transaction", key)
+	}
+
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %v", key, err)
+	}
+
+	tx.doc.Data[key] = raw
+	tx.dirty = true
+	return nil
+}
+
+// Delete removes key from the database
+func (tx *Tx) Delete(key string) error {
+	if !tx.writable {
+		return fmt.Errorf("cannot delete %s in a read-only transaction", key)
+	}
+
+	if _, ok := tx.doc.Data[key]; ok {
+		delete(tx.doc.Data, key)
+		tx.dirty = true
+	}
+	return nil
+}
+
+// Keys returns the stored keys in sorted order
+func (tx *Tx) Keys() []string {
+	keys := make([]string, 0, len(tx.doc.Data))
+	for key := range tx.doc.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// withLock holds the database file lock while fn runs
+func withLock(exclusive bool, fn func(path string) error) error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+
+	lockFile, err := os.OpenFile(path+".lock", os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open lock file: %v", err)
+	}
+	defer lockFile.Close()
+
+	if err := lockFileHandle(lockFile, exclusive); err != nil {
+		return fmt.Errorf("failed to lock database: %v", err)
+	}
+	defer unlockFileHandle(lockFile)
+
+	return fn(path)
+}
+
+// readDocument loads the database, returning an empty version 0 document if it does not exist yet
+func readDocument(path string) (*document, error) {
+	doc := &document{Data: map[string]json.RawMessage{}}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return doc, nil
+		}
+		return nil, fmt.Errorf("failed to read database: %v", err)
+	}
+
+	if err := json.Unmarshal(data, doc); err != nil {
+		return nil, fmt.Errorf("failed to parse database %s (run 'pom doctor --fix' to repair): %v", path, err)
+	}
+	if doc.Data == nil {
+		doc.Data = map[string]json.RawMessage{}
+	}
+	if doc.Version > SchemaVersion {
+		return nil, fmt.Errorf("database schema version %d is newer than supported version %d; please upgrade pom", doc.Version, SchemaVersion)
+	}
+
+	return doc, nil
+}
+
+// writeDocument atomically replaces the database file, then refreshes the
+// last-good copy with what was written
+func writeDocument(path string, doc *document) error {
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode database: %v", err)
+	}
+
+	if err := WriteFileAtomic(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write database: %v", err)
+	}
+
+	saveLastGood(data)
+	return nil
+}
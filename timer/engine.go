@@ -0,0 +1,425 @@
+package timer
+
+import (
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/Flack74/pom/history"
+)
+
+// Snapshot events
+const (
+	EventTick     = "tick"     // Once a second while a run is active
+	EventStarted  = "started"  // A new run began
+	EventPhase    = "phase"    // The run moved to its next phase
+	EventPaused   = "paused"   // The current phase was paused
+	EventResumed  = "resumed"  // The current phase was resumed
+	EventFinished = "finished" // The run completed all of its sessions
+	EventStopped  = "stopped"  // The run was stopped early
+	EventSkipped  = "skipped"  // The current phase was skipped and the next one began
+)
+
+var (
+	// ErrNoRun is returned when a command needs an active run and there is none
+	ErrNoRun = errors.New("no session is running")
+	// ErrRunActive is returned when starting a run while another one is active
+	ErrRunActive = errors.New("a session is already running")
+)
+
+// Snapshot is the externally visible state of the engine
+type Snapshot struct {
+	Event        string `json:"event,omitempty"`
+	Active       bool   `json:"active"`
+	RunID        string `json:"run_id,omitempty"`
+	Phase        string `json:"phase,omitempty"`
+	Session      int    `json:"session,omitempty"`
+	Sessions     int    `json:"sessions,omitempty"`
+	WorkMinutes  int    `json:"work_minutes,omitempty"`
+	BreakMinutes int    `json:"break_minutes,omitempty"`
+	Profile      string `json:"profile,omitempty"`
+	TaskID       string `json:"task_id,omitempty"`
+	Interval     string `json:"interval,omitempty"` // Name of the current interval
+	Step         int    `json:"step"`               // Index of the current interval in Intervals
+	Paused       bool   `json:"paused"`
+	Recovered    bool   `json:"recovered,omitempty"` // Restored after the timer process exited; resume or stop it
+	Remaining    int    `json:"remaining"`           // Seconds left in the current phase
+	Duration     int    `json:"duration"`            // Planned seconds of the current phase
+	Open         bool   `json:"open,omitempty"`      // The current interval counts up until it is ended
+	Elapsed      int    `json:"elapsed"`             // Seconds spent in the current phase, excluding pauses
+
+	Intervals []Interval `json:"intervals,omitempty"` // The run's interval sequence
+}
+
+// Hooks are called by the engine as a run progresses. They run on their own
+// goroutine, in order, so slow plugins or notifications never stall the clock.
+type Hooks struct {
+	RunStart   func(state State)
+	PhaseStart func(state State)
+	PhaseEnd   func(record history.Record)
+	RunEnd     func(state State, completed bool)
+}
+
+// Engine is the single authoritative timer. It owns the active run, logs
+// each interval as it ends and persists its state on every transition.
+type Engine struct {
+	mu        sync.Mutex
+	state     *State
+	recovered bool
+	hooks     Hooks
+	subs      map[chan Snapshot]struct{}
+
+	// Hooks wait in an unbounded FIFO, so queueing never blocks the clock
+	// and they always run in the order they were queued
+	hookMu    sync.Mutex
+	pending   []func()
+	hookReady chan struct{}
+}
+
+// NewEngine creates an engine, restoring any run left behind by a previous
+// timer process as a paused, recovered run
+func NewEngine(hooks Hooks) (*Engine, error) {
+	e := &Engine{
+		hooks:     hooks,
+		hookReady: make(chan struct{}, 1),
+		subs:      make(map[chan Snapshot]struct{}),
+	}
+
+	state, err := Load()
+	if err != nil {
+		return nil, err
+	}
+	if state != nil {
+		state.Recover()
+		e.state = state
+		e.recovered = true
+	}
+
+	go e.runHooks()
+
+	return e, nil
+}
+
+// Run drives the clock until stop is closed
+func (e *Engine) Run(stop <-chan struct{}) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			e.mu.Lock()
+			if e.state != nil {
+				Save(e.state)
+			}
+			e.mu.Unlock()
+			return
+		case now := <-ticker.C:
+			e.tick(now)
+		}
+	}
+}
+
+// Start begins a new run of plan
+func (e *Engine) Start(plan []Interval, profile, taskID string) error {
+	if err := ValidatePlan(plan); err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.state != nil {
+		return ErrRunActive
+	}
+
+	e.state = NewRun(plan, profile, taskID, time.Now())
+	if err := Save(e.state); err != nil {
+		e.state = nil
+		return err
+	}
+
+	state := *e.state
+	e.queueHook(func() {
+		if e.hooks.RunStart != nil {
+			e.hooks.RunStart(state)
+		}
+		if e.hooks.PhaseStart != nil {
+			e.hooks.PhaseStart(state)
+		}
+	})
+	e.broadcast(EventStarted, time.Now())
+	return nil
+}
+
+// Pause stops the clock
+func (e *Engine) Pause() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.state == nil {
+		return ErrNoRun
+	}
+	if e.state.IsPaused() {
+		return nil
+	}
+
+	now := time.Now()
+	e.state.Pause(now)
+	Save(e.state)
+	e.broadcast(EventPaused, now)
+	return nil
+}
+
+// Resume restarts the clock, including a run recovered from a previous process
+func (e *Engine) Resume() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.state == nil {
+		return ErrNoRun
+	}
+
+	now := time.Now()
+	if e.recovered {
+		e.state.Continue(now)
+		e.recovered = false
+	} else if e.state.IsPaused() {
+		e.state.Resume(now)
+	} else {
+		return nil
+	}
+
+	Save(e.state)
+	e.broadcast(EventResumed, now)
+	return nil
+}
+
+// Stop ends the run early. The current phase is logged as interrupted, or
+// as abandoned if the run was recovered and never resumed. An open focus
+// interval is logged as completed, as stopping is how it ends, unless it
+// ran for less than MinOpenFocus.
+func (e *Engine) Stop() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.state == nil {
+		return ErrNoRun
+	}
+
+	now := time.Now()
+	status := history.StatusInterrupted
+	if e.recovered {
+		now = e.state.UpdatedAt
+		status = history.StatusAbandoned
+	}
+
+	e.endPhase(now, status)
+	e.endRun(EventStopped, false)
+	return nil
+}
+
+// Skip ends the current phase early, logging it as skipped, and moves on
+// to the next one. Skipping the last interval finishes the run. Skipping an
+// open focus interval ends it as completed, or as interrupted if it ran for
+// less than MinOpenFocus, and starts its break.
+func (e *Engine) Skip() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.state == nil {
+		return ErrNoRun
+	}
+
+	now := time.Now()
+	if e.recovered {
+		e.state.Continue(now)
+		e.recovered = false
+	}
+	if e.state.IsPaused() {
+		e.state.Resume(now)
+	}
+
+	e.endPhase(now, history.StatusSkipped)
+	e.nextPhase(now, EventSkipped)
+	return nil
+}
+
+// Snapshot returns the current state
+func (e *Engine) Snapshot() Snapshot {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.snapshot("", time.Now())
+}
+
+// Subscribe returns a channel receiving a snapshot on every tick and
+// transition, and a function to cancel the subscription
+func (e *Engine) Subscribe() (<-chan Snapshot, func()) {
+	ch := make(chan Snapshot, 16)
+
+	e.mu.Lock()
+	e.subs[ch] = struct{}{}
+	e.mu.Unlock()
+
+	return ch, func() {
+		e.mu.Lock()
+		if _, ok := e.subs[ch]; ok {
+			delete(e.subs, ch)
+			close(ch)
+		}
+		e.mu.Unlock()
+	}
+}
+
+// tick advances the clock by one step
+func (e *Engine) tick(now time.Time) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.state == nil || e.recovered || e.state.IsPaused() {
+		return
+	}
+
+	if e.state.IsOpen() || e.state.Remaining(now) > 0 {
+		if now.Sub(e.state.UpdatedAt) >= HeartbeatInterval {
+			Save(e.state)
+		}
+		e.broadcast(EventTick, now)
+		return
+	}
+
+	e.endPhase(now, history.StatusCompleted)
+	e.nextPhase(now, EventPhase)
+}
+
+// nextPhase moves the run on to its next phase, or finishes it after the last one
+func (e *Engine) nextPhase(now time.Time, event string) {
+	if !e.state.Advance(now) {
+		e.endRun(EventFinished, true)
+		return
+	}
+
+	Save(e.state)
+	state := *e.state
+	e.queueHook(func() {
+		if e.hooks.PhaseStart != nil {
+			e.hooks.PhaseStart(state)
+		}
+	})
+	e.broadcast(event, now)
+}
+
+// endPhase logs the current phase with the given status
+func (e *Engine) endPhase(now time.Time, status string) {
+	record := e.state.Record(now, status)
+	e.state.Settle(now)
+	if err := history.Append(record); err != nil {
+		// Still credit tasks and goals; only the session log misses the interval
+		log.Printf("failed to log %s interval ending %s: %v", record.Kind, record.EndTime.Format(time.RFC3339), err)
+	}
+
+	e.queueHook(func() {
+		if e.hooks.PhaseEnd != nil {
+			e.hooks.PhaseEnd(record)
+		}
+	})
+}
+
+// endRun clears the active run
+func (e *Engine) endRun(event string, completed bool) {
+	state := *e.state
+	Clear()
+
+	// The final snapshot still describes the run that just ended
+	snap := e.snapshot(event, time.Now())
+	snap.Active = false
+	e.send(snap)
+	e.state = nil
+	e.recovered = false
+
+	e.queueHook(func() {
+		if e.hooks.RunEnd != nil {
+			e.hooks.RunEnd(state, completed)
+		}
+	})
+}
+
+// queueHook schedules a hook to run after the engine lock is released
+func (e *Engine) queueHook(hook func()) {
+	e.hookMu.Lock()
+	e.pending = append(e.pending, hook)
+	e.hookMu.Unlock()
+
+	select {
+	case e.hookReady <- struct{}{}:
+	default:
+		// The hook goroutine has already been woken up
+	}
+}
+
+// runHooks runs queued hooks one at a time, in order
+func (e *Engine) runHooks() {
+	for range e.hookReady {
+		for {
+			e.hookMu.Lock()
+			if len(e.pending) == 0 {
+				e.hookMu.Unlock()
+				break
+			}
+			hook := e.pending[0]
+			e.pending[0] = nil
+			e.pending = e.pending[1:]
+			e.hookMu.Unlock()
+
+			hook()
+		}
+	}
+}
+
+// snapshot builds a Snapshot; callers must hold e.mu
+func (e *Engine) snapshot(event string, now time.Time) Snapshot {
+	if e.state == nil {
+		return Snapshot{Event: event}
+	}
+
+	s := e.state
+	return Snapshot{
+		Event:        event,
+		Active:       true,
+		RunID:        s.RunID,
+		Phase:        s.Phase,
+		Session:      s.Session,
+		Sessions:     s.Sessions,
+		WorkMinutes:  s.WorkMinutes,
+		BreakMinutes: s.BreakMinutes,
+		Profile:      s.Profile,
+		TaskID:       s.TaskID,
+		Interval:     s.Current().Label(),
+		Step:         s.Step,
+		Paused:       s.IsPaused(),
+		Recovered:    e.recovered,
+		Remaining:    int(s.Remaining(now).Round(time.Second).Seconds()),
+		Duration:     int(s.PhaseDuration().Seconds()),
+		Open:         s.IsOpen(),
+		Elapsed:      int(s.ElapsedAt(now).Round(time.Second).Seconds()),
+		Intervals:    s.Intervals,
+	}
+}
+
+// broadcast sends a snapshot to all subscribers, dropping it for any that
+// are not keeping up; callers must hold e.mu
+func (e *Engine) broadcast(event string, now time.Time) {
+	e.send(e.snapshot(event, now))
+}
+
+// send delivers snap to all subscribers; callers must hold e.mu
+func (e *Engine) send(snap Snapshot) {
+	for ch := range e.subs {
+		select {
+		case ch <- snap:
+		default:
+		}
+	}
+}
@@ -0,0 +1,230 @@
+package timer
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Flack74/pom/history"
+)
+
+// Default interval names
+const (
+	NameFocus     = "Focus"
+	NameBreak     = "Break"
+	NameLongBreak = "Long break"
+	NameFlow      = "Flow"
+)
+
+// intervalNamePattern allows interval names, which plugins receive as
+// INTERVAL: letters, digits, spaces, '_' and '-'
+var intervalNamePattern = regexp.MustCompile(`^[\p{L}\p{N} _-]*$`)
+
+// Interval is one step of a run
+type Interval struct {
+	Name    string `json:"name,omitempty"`
+	Kind    string `json:"kind"` // history.KindFocus or history.KindBreak
+	Minutes int    `json:"minutes"`
+
+	// Open focus intervals count up until they are ended; Minutes is filled
+	// in with the time spent once they are
+	Open bool `json:"open,omitempty"`
+
+	// Ratio sets a break's length from the focus interval before it: one
+	// break minute per Ratio focus minutes. Minutes is filled in when the
+	// break begins.
+	Ratio float64 `json:"ratio,omitempty"`
+}
+
+// IsFocus reports whether the interval is a focus interval
+func (i Interval) IsFocus() bool {
+	return i.Kind == history.KindFocus
+}
+
+// Label returns the interval's name, or a name derived from its kind
+func (i Interval) Label() string {
+	if i.Name != "" {
+		return i.Name
+	}
+	if i.IsFocus() {
+		return NameFocus
+	}
+	return NameBreak
+}
+
+// Length describes the interval's length, such as "25m", "open" or "focus/5"
+func (i Interval) Length() string {
+	switch {
+	case i.Open && i.Minutes == 0:
+		return "open"
+	case i.Ratio > 0 && i.Minutes == 0:
+		return fmt.Sprintf("focus/%g", i.Ratio)
+	}
+	return fmt.Sprintf("%dm", i.Minutes)
+}
+
+// FlowPlan builds a flow run: cycles of an open focus interval, each followed
+// by a break of one minute per ratio minutes of focus
+func FlowPlan(cycles int, ratio float64) []Interval {
+	var plan []Interval
+	for i := 0; i < cycles; i++ {
+		plan = append(plan,
+			Interval{Name: NameFlow, Kind: history.KindFocus, Open: true},
+			Interval{Name: NameBreak, Kind: history.KindBreak, Ratio: ratio})
+	}
+	return plan
+}
+
+// RatioBreak returns the length in minutes of a break earned by focus time,
+// at least one minute
+func RatioBreak(focus time.Duration, ratio float64) int {
+	minutes := int(math.Round(focus.Minutes() / ratio))
+	if minutes < 1 {
+		return 1
+	}
+	return minutes
+}
+
+// ClassicPlan builds the interval sequence of a work/break run. If
+// longBreak and every are set, every every-th break is a long break. The
+// run ends with its last focus interval, never a break.
+func ClassicPlan(workMin, breakMin, sessions, longBreak, every int) []Interval {
+	var plan []Interval
+	for i := 1; i <= sessions; i++ {
+		plan = append(plan, Interval{Name: NameFocus, Kind: history.KindFocus, Minutes: workMin})
+
+		long := longBreak > 0 && every > 0 && i%every == 0 && i < sessions
+		switch {
+		case long:
+			plan = append(plan, Interval{Name: NameLongBreak, Kind: history.KindBreak, Minutes: longBreak})
+		case i < sessions && breakMin > 0:
+			plan = append(plan, Interval{Name: NameBreak, Kind: history.KindBreak, Minutes: breakMin})
+		}
+	}
+	return plan
+}
+
+// FocusCount returns the number of focus intervals in plan
+func FocusCount(plan []Interval) int {
+	count := 0
+	for _, interval := range plan {
+		if interval.IsFocus() {
+			count++
+		}
+	}
+	return count
+}
+
+// FocusMinutes returns the planned focus time of plan
+func FocusMinutes(plan []Interval) int {
+	total := 0
+	for _, interval := range plan {
+		if interval.IsFocus() {
+			total += interval.Minutes
+		}
+	}
+	return total
+}
+
+// ValidatePlan checks that plan can be run: it must start with a focus
+// interval and every interval needs a known kind and a positive length,
+// unless it is an open focus interval or a break set by a ratio
+func ValidatePlan(plan []Interval) error {
+	if len(plan) == 0 {
+		return fmt.Errorf("no intervals")
+	}
+	if !plan[0].IsFocus() {
+		return fmt.Errorf("the first interval must be a focus interval")
+	}
+	for i, interval := range plan {
+		if interval.Kind != history.KindFocus && interval.Kind != history.KindBreak {
+			return fmt.Errorf("interval %d has unknown kind '%s'", i+1, interval.Kind)
+		}
+		switch {
+		case !intervalNamePattern.MatchString(interval.Name):
+			return fmt.Errorf("interval %d name '%s' may only contain letters, digits, spaces, '_' and '-'", i+1, interval.Name)
+		case interval.Open && !interval.IsFocus():
+			return fmt.Errorf("interval %d: only focus intervals can be open", i+1)
+		case interval.Ratio < 0 || (interval.Ratio > 0 && interval.IsFocus()):
+			return fmt.Errorf("interval %d: only breaks can have a positive ratio", i+1)
+		case interval.Minutes < 1 && !interval.Open && interval.Ratio == 0:
+			return fmt.Errorf("interval %d must be at least 1 minute", i+1)
+		}
+	}
+	return nil
+}
+
+// ParsePlan reads a comma-separated interval list such as
+// "Deep work:focus:52,Walk:break:17". The name may be left out
+// ("focus:52"), and a bare number is a focus interval. A focus length of
+// "open" counts up until ended, and a break length of "focus/5" gives one
+// break minute per 5 minutes of the focus before it.
+func ParsePlan(spec string) ([]Interval, error) {
+	var plan []Interval
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		parts := strings.Split(item, ":")
+		interval := Interval{Kind: history.KindFocus}
+		switch len(parts) {
+		case 1:
+		case 2:
+			interval.Kind = strings.ToLower(strings.TrimSpace(parts[0]))
+		case 3:
+			interval.Name = strings.TrimSpace(parts[0])
+			interval.Kind = strings.ToLower(strings.TrimSpace(parts[1]))
+		default:
+			return nil, fmt.Errorf("invalid interval '%s', expected name:kind:minutes", item)
+		}
+
+		length := strings.TrimSpace(parts[len(parts)-1])
+		switch {
+		case length == "open":
+			interval.Open = true
+		case strings.HasPrefix(length, "focus/"):
+			ratio, err := strconv.ParseFloat(strings.TrimPrefix(length, "focus/"), 64)
+			if err != nil || ratio <= 0 {
+				return nil, fmt.Errorf("invalid ratio in interval '%s'", item)
+			}
+			interval.Ratio = ratio
+		default:
+			minutes, err := strconv.Atoi(length)
+			if err != nil {
+				return nil, fmt.Errorf("invalid minutes in interval '%s'", item)
+			}
+			interval.Minutes = minutes
+		}
+		plan = append(plan, interval)
+	}
+
+	if err := ValidatePlan(plan); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// FormatPlan returns plan in the form read by ParsePlan
+func FormatPlan(plan []Interval) string {
+	parts := make([]string, len(plan))
+	for i, interval := range plan {
+		length := strings.TrimSuffix(interval.Length(), "m")
+		parts[i] = fmt.Sprintf("%s:%s:%s", interval.Label(), interval.Kind, length)
+	}
+	return strings.Join(parts, ",")
+}
+
+// SummarizePlan returns a short human-readable description of plan, such as
+// "Focus 25m → Break 5m → Focus 25m → Long break 15m"
+func SummarizePlan(plan []Interval) string {
+	parts := make([]string, len(plan))
+	for i, interval := range plan {
+		parts[i] = interval.Label() + " " + interval.Length()
+	}
+	return strings.Join(parts, " → ")
+}
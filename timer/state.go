@@ -0,0 +1,296 @@
+// Package timer holds the state of the active Pomodoro run. The state is
+// persisted in the datastore on every transition (and periodically while
+// running) so a run can be picked up again after the process exits.
+package timer
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Flack74/pom/history"
+	"github.com/Flack74/pom/store"
+)
+
+// HeartbeatInterval is how often a running timer refreshes its saved state
+const HeartbeatInterval = 10 * time.Second
+
+// MinOpenFocus is the shortest open interval logged as completed; shorter
+// ones are logged as interrupted so a flow started by mistake does not count
+const MinOpenFocus = 5 * time.Minute
+
+// State is the active timer run
+type State struct {
+	RunID        string `json:"run_id"`
+	Phase        string `json:"phase"`    // history.KindFocus or history.KindBreak
+	Session      int    `json:"session"`  // 1-based session number
+	Sessions     int    `json:"sessions"` // Total sessions in the run
+	WorkMinutes  int    `json:"work_minutes"`
+	BreakMinutes int    `json:"break_minutes"`
+	Profile      string `json:"profile,omitempty"`
+	TaskID       string `json:"task_id,omitempty"`
+
+	Intervals []Interval `json:"intervals,omitempty"` // The run's interval sequence
+	Step      int        `json:"step"`                // Index of the current interval in Intervals
+
+	PhaseStart   time.Time     `json:"phase_start"`             // When the current phase began
+	SegmentStart time.Time     `json:"segment_start,omitempty"` // Start of the current running stretch; zero while paused
+	Elapsed      time.Duration `json:"elapsed"`                 // Running time accrued before SegmentStart
+	PausedAt     time.Time     `json:"paused_at,omitempty"`     // When the timer was paused; zero while running
+	Paused       time.Duration `json:"paused"`                  // Pause time accrued in the current phase
+	UpdatedAt    time.Time     `json:"updated_at"`              // Last time the state was saved
+}
+
+// NewRun creates the state for a fresh run of plan, starting with its first
+// interval, which must be a focus interval
+func NewRun(plan []Interval, profile, taskID string, now time.Time) *State {
+	s := &State{
+		RunID:        fmt.Sprintf("%d", now.UnixNano()),
+		Phase:        history.KindFocus,
+		Session:      1,
+		Sessions:     FocusCount(plan),
+		Profile:      profile,
+		TaskID:       taskID,
+		Intervals:    plan,
+		PhaseStart:   now,
+		SegmentStart: now,
+	}
+
+	// Work and break minutes describe the first pair for clients that do
+	// not know about interval sequences
+	for _, interval := range plan {
+		if interval.IsFocus() && s.WorkMinutes == 0 {
+			s.WorkMinutes = interval.Minutes
+		}
+		if !interval.IsFocus() && s.BreakMinutes == 0 {
+			s.BreakMinutes = interval.Minutes
+		}
+	}
+	return s
+}
+
+// Current returns the current interval
+func (s *State) Current() Interval {
+	if s.Step >= 0 && s.Step < len(s.Intervals) {
+		return s.Intervals[s.Step]
+	}
+	return Interval{Kind: s.Phase}
+}
+
+// Next returns the interval after the current one, if there is one
+func (s *State) Next() (Interval, bool) {
+	if s.Step+1 < len(s.Intervals) {
+		return s.Intervals[s.Step+1], true
+	}
+	return Interval{}, false
+}
+
+// upgrade fills in the interval sequence of a state saved before runs had
+// one, so it carries on as the work/break run it was started as
+func (s *State) upgrade() {
+	if len(s.Intervals) > 0 {
+		return
+	}
+	s.Intervals = ClassicPlan(s.WorkMinutes, s.BreakMinutes, s.Sessions, 0, 0)
+	s.Step = 2 * (s.Session - 1)
+	if s.Phase == history.KindBreak {
+		s.Step++
+	}
+}
+
+// IsPaused reports whether the current phase is paused
+func (s *State) IsPaused() bool {
+	return !s.PausedAt.IsZero()
+}
+
+// IsOpen reports whether the current interval counts up until it is ended
+func (s *State) IsOpen() bool {
+	return s.Current().Open
+}
+
+// PhaseDuration returns the planned length of the current phase, which is
+// zero for an open interval
+func (s *State) PhaseDuration() time.Duration {
+	return time.Duration(s.Current().Minutes) * time.Minute
+}
+
+// ElapsedAt returns the running time spent in the current phase, excluding pauses
+func (s *State) ElapsedAt(now time.Time) time.Duration {
+	if s.IsPaused() || s.SegmentStart.IsZero() {
+		return s.Elapsed
+	}
+	return s.Elapsed + now.Sub(s.SegmentStart)
+}
+
+// PausedAtTime returns the pause time in the current phase, including an ongoing pause
+func (s *State) PausedAtTime(now time.Time) time.Duration {
+	if s.IsPaused() {
+		return s.Paused + now.Sub(s.PausedAt)
+	}
+	return s.Paused
+}
+
+// Remaining returns the time left in the current phase. Open intervals have
+// no time left; they run until they are ended.
+func (s *State) Remaining(now time.Time) time.Duration {
+	if s.IsOpen() {
+		return 0
+	}
+	remaining := s.PhaseDuration() - s.ElapsedAt(now)
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
+// Pause stops the clock for the current phase
+func (s *State) Pause(now time.Time) {
+	if s.IsPaused() {
+		return
+	}
+	s.Elapsed = s.ElapsedAt(now)
+	s.SegmentStart = time.Time{}
+	s.PausedAt = now
+}
+
+// Resume restarts the clock after Pause
+func (s *State) Resume(now time.Time) {
+	if !s.IsPaused() {
+		return
+	}
+	s.Paused += now.Sub(s.PausedAt)
+	s.PausedAt = time.Time{}
+	s.SegmentStart = now
+}
+
+// Advance moves to the next interval. It returns false when the run is over.
+func (s *State) Advance(now time.Time) bool {
+	next, ok := s.Next()
+	if !ok {
+		return false
+	}
+	if next.Ratio > 0 {
+		// Copy before writing, as snapshots share the slice
+		s.Intervals = append([]Interval(nil), s.Intervals...)
+		focus := time.Duration(0)
+		if s.Current().IsFocus() {
+			focus = time.Duration(s.Current().Minutes) * time.Minute
+		}
+		next.Minutes = RatioBreak(focus, next.Ratio)
+		s.Intervals[s.Step+1] = next
+	}
+	s.Step++
+	s.Phase = next.Kind
+	if next.IsFocus() {
+		s.Session++
+	}
+
+	s.PhaseStart = now
+	s.SegmentStart = now
+	s.Elapsed = 0
+	s.PausedAt = time.Time{}
+	s.Paused = 0
+	return true
+}
+
+// Record builds the session log record for the current phase ending at now.
+// An open interval is planned to last as long as it ran, and ending it is
+// its natural end, so it is logged as completed unless it was abandoned or
+// ran for less than MinOpenFocus, in which case it was interrupted.
+func (s *State) Record(now time.Time, status string) history.Record {
+	planned := s.PhaseDuration()
+	actual := s.ElapsedAt(now)
+	if s.IsOpen() {
+		planned = actual
+		switch {
+		case status == history.StatusAbandoned:
+		case actual < MinOpenFocus:
+			status = history.StatusInterrupted
+		default:
+			status = history.StatusCompleted
+		}
+	}
+	if status == history.StatusCompleted || actual > planned {
+		actual = planned
+	}
+
+	return history.Record{
+		RunID:          s.RunID,
+		Kind:           s.Phase,
+		Name:           s.Current().Label(),
+		Index:          s.Session,
+		StartTime:      s.PhaseStart,
+		EndTime:        now,
+		PlannedSeconds: int(planned.Seconds()),
+		ActualSeconds:  int(actual.Seconds()),
+		PausedSeconds:  int(s.PausedAtTime(now).Seconds()),
+		Status:         status,
+		Profile:        s.Profile,
+		TaskID:         s.TaskID,
+	}
+}
+
+// Settle records the time spent in an open interval as its length, once it
+// has ended at now
+func (s *State) Settle(now time.Time) {
+	if !s.IsOpen() {
+		return
+	}
+	s.Intervals = append([]Interval(nil), s.Intervals...)
+	s.Intervals[s.Step].Minutes = int(s.ElapsedAt(now).Round(time.Minute).Minutes())
+}
+
+// Recover freezes a state left behind by a process that exited. Running
+// time is only counted up to the last saved heartbeat, and the phase is left
+// paused as of that moment without counting the downtime as pause time.
+func (s *State) Recover() {
+	if !s.IsPaused() && !s.SegmentStart.IsZero() {
+		s.Elapsed += s.UpdatedAt.Sub(s.SegmentStart)
+		if s.Elapsed > s.PhaseDuration() && !s.IsOpen() {
+			s.Elapsed = s.PhaseDuration()
+		}
+	} else if s.IsPaused() {
+		s.Paused += s.UpdatedAt.Sub(s.PausedAt)
+	}
+	s.SegmentStart = time.Time{}
+	s.PausedAt = s.UpdatedAt
+}
+
+// Continue resumes a recovered state at now without counting the time the process was gone
+func (s *State) Continue(now time.Time) {
+	s.PausedAt = time.Time{}
+	s.SegmentStart = now
+}
+
+// Load returns the saved timer state, or nil if no run is in progress
+func Load() (*State, error) {
+	var state *State
+	err := store.View(func(tx *store.Tx) error {
+		var saved State
+		found, err := tx.Get(store.KeyTimer, &saved)
+		if found && err == nil {
+			saved.upgrade()
+			state = &saved
+		}
+		return err
+	})
+	return state, err
+}
+
+// Save persists the timer state, with times in UTC
+func Save(s *State) error {
+	s.UpdatedAt = time.Now().UTC()
+	saved := *s
+	saved.PhaseStart = saved.PhaseStart.UTC()
+	saved.SegmentStart = saved.SegmentStart.UTC()
+	saved.PausedAt = saved.PausedAt.UTC()
+	return store.Update(func(tx *store.Tx) error {
+		return tx.Put(store.KeyTimer, saved)
+	})
+}
+
+// Clear removes the saved timer state once a run is finished
+func Clear() error {
+	return store.Update(func(tx *store.Tx) error {
+		return tx.Delete(store.KeyTimer)
+	})
+}
@@ -0,0 +1,890 @@
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Flack74/pom/clock"
+	"github.com/Flack74/pom/config"
+	"github.com/Flack74/pom/daemon"
+	"github.com/Flack74/pom/logs"
+	"github.com/Flack74/pom/report"
+	"github.com/gorilla/mux"
+)
+
+// Stats is the response of GET /api/stats
+type Stats struct {
+	TodaySessions      int     `json:"today_sessions"`
+	TodayMinutes       int     `json:"today_minutes"`
+	TotalSessions      int     `json:"total_sessions"`
+	TotalMinutes       float64 `json:"total_minutes"`
+	AvgSessionsPerDay  float64 `json:"avg_sessions_per_day"`
+	CurrentStreak      int     `json:"current_streak"`
+	LongestStreak      int     `json:"longest_streak"`
+	DailySessionTarget int     `json:"daily_session_target"` // Today's target, by weekday
+	DailyMinutesTarget int     `json:"daily_minutes_target"`
+	RestToday          bool    `json:"rest_today"`
+	Freezes            int     `json:"freezes"` // Streak freezes banked
+
+	Week  config.PeriodProgress      `json:"week"`
+	Month config.PeriodProgress      `json:"month"`
+	Goals []config.NamedGoalProgress `json:"goals"` // Named goals
+
+	Intervals []logs.IntervalStat `json:"intervals"` // Totals by interval name
+}
+
+// AdaptiveExplanation is the response of GET /api/profiles/{name}/adaptive
+type AdaptiveExplanation struct {
+	Profile string                    `json:"profile"`
+	Bounds  config.Adaptive           `json:"bounds"` // With defaults filled in
+	Next    config.AdaptiveDecision   `json:"next"`   // Lengths a run started now would use
+	Log     []config.AdaptiveDecision `json:"log"`    // Lengths chosen for earlier runs, oldest first
+}
+
+// WebTheme is a CLI theme with the colors the web UI uses for it
+type WebTheme struct {
+	Name      string `json:"name"`
+	Accent    string `json:"accent"`    // CSS color of the timer, headings and primary buttons
+	Secondary string `json:"secondary"` // CSS color of secondary buttons and the progress gradient
+}
+
+// webPalettes maps CLI theme names to web colors. Themes without an entry use
+// the default Galactic Flux colors.
+var webPalettes = map[string]WebTheme{
+	"default": {Accent: "#18FFFF", Secondary: "#FF4081"},
+	"minimal": {Accent: "#E0E0E0", Secondary: "#757575"},
+	"vibrant": {Accent: "#E040FB", Secondary: "#FFD600"},
+}
+
+// webTheme returns the web colors of the named theme
+func webTheme(name string) WebTheme {
+	theme, ok := webPalettes[name]
+	if !ok {
+		theme = webPalettes["default"]
+	}
+	theme.Name = name
+	return theme
+}
+
+// taskRequest is the body of POST /api/tasks and PUT /api/tasks/{id}
+type taskRequest struct {
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Tags        []string `json:"tags"`
+	Estimate    int      `json:"estimate"`
+	Priority    string   `json:"priority"`
+	Due         string   `json:"due"` // YYYY-MM-DD, today, tomorrow or a weekday
+	Project     string   `json:"project"`
+}
+
+// task converts the request to a task, reading the priority and due date
+func (req taskRequest) task() (config.Task, error) {
+	task := config.Task{
+		Title:       req.Title,
+		Description: req.Description,
+		Tags:        req.Tags,
+		Estimate:    req.Estimate,
+		Project:     req.Project,
+	}
+	var err error
+	if task.Priority, err = config.ParsePriority(req.Priority); err != nil {
+		return config.Task{}, err
+	}
+	if task.Due, err = config.ParseDueDate(req.Due, clock.Load(), time.Now()); err != nil {
+		return config.Task{}, err
+	}
+	return task, config.ValidateTask(task)
+}
+
+// registerAPI adds the REST endpoints to the /api router
+func (s *Server) registerAPI(api *mux.Router) {
+	api.HandleFunc("/openapi.json", handleOpenAPI).Methods("GET")
+
+	api.HandleFunc("/goals", s.handleGetGoals).Methods("GET")
+	api.HandleFunc("/goals", s.handleSetGoals).Methods("PUT")
+	api.HandleFunc("/goals/history", s.handleGoalHistory).Methods("GET")
+	api.HandleFunc("/goals/named", s.handleNamedGoals).Methods("GET")
+	api.HandleFunc("/goals/named", s.handleAddNamedGoal).Methods("POST")
+	api.HandleFunc("/goals/named/{name}", s.handleRemoveNamedGoal).Methods("DELETE")
+
+	api.HandleFunc("/achievements", s.handleAchievements).Methods("GET")
+	api.HandleFunc("/achievements/rules", s.handleAddAchievement).Methods("POST")
+	api.HandleFunc("/achievements/rules/{id}", s.handleRemoveAchievement).Methods("DELETE")
+
+	api.HandleFunc("/profiles", s.handleCreateProfile).Methods("POST")
+	api.HandleFunc("/profiles/{name}", s.handleGetProfile).Methods("GET")
+	api.HandleFunc("/profiles/{name}", s.handleUpdateProfile).Methods("PUT")
+	api.HandleFunc("/profiles/{name}", s.handleDeleteProfile).Methods("DELETE")
+	api.HandleFunc("/profiles/{name}/use", s.handleUseProfile).Methods("POST")
+	api.HandleFunc("/profiles/{name}/adaptive", s.handleAdaptiveProfile).Methods("GET")
+
+	api.HandleFunc("/themes", s.handleThemes).Methods("GET")
+	api.HandleFunc("/theme", s.handleSetTheme).Methods("PUT")
+
+	api.HandleFunc("/tasks", s.handleListTasks).Methods("GET")
+	api.HandleFunc("/tasks", s.handleCreateTask).Methods("POST")
+	api.HandleFunc("/tasks/{id}", s.handleGetTask).Methods("GET")
+	api.HandleFunc("/tasks/{id}", s.handleUpdateTask).Methods("PUT")
+	api.HandleFunc("/tasks/{id}", s.handleDeleteTask).Methods("DELETE")
+	api.HandleFunc("/tasks/{id}/complete", s.handleCompleteTask).Methods("POST")
+	api.HandleFunc("/tasks/{id}/reopen", s.handleReopenTask).Methods("POST")
+	api.HandleFunc("/tasks/{id}/archive", s.handleArchiveTask).Methods("POST")
+
+	api.HandleFunc("/stats", s.handleStats).Methods("GET")
+	api.HandleFunc("/calendar", s.handleCalendar).Methods("GET")
+	api.HandleFunc("/insights", s.handleInsights).Methods("GET")
+	api.HandleFunc("/insights/coach", s.handleCoach).Methods("GET")
+	api.HandleFunc("/report/{period}", s.handleReport).Methods("GET")
+
+	api.HandleFunc("/plugins", localOnly(s.handleAddPlugin)).Methods("POST")
+	api.HandleFunc("/plugins/{name}/enable", localOnly(s.handleEnablePlugin(true))).Methods("POST")
+	api.HandleFunc("/plugins/{name}/disable", localOnly(s.handleEnablePlugin(false))).Methods("POST")
+
+	api.HandleFunc("/privacy", s.handlePrivacyStatus).Methods("GET")
+	api.HandleFunc("/privacy", s.handleSetPrivacy).Methods("PUT")
+	api.HandleFunc("/day", s.handleDay).Methods("GET")
+	api.HandleFunc("/day", s.handleSetDay).Methods("PUT")
+	api.HandleFunc("/sync/status", s.handleSyncStatus).Methods("GET")
+
+	api.HandleFunc("/export", s.handleExport).Methods("GET")
+	api.HandleFunc("/import", s.handleImport).Methods("POST")
+}
+
+// writeJSON writes v as a JSON response
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes err as a JSON error response. Missing resources, name
+// clashes and validation errors map to 404, 409 and 400 regardless of status.
+func writeError(w http.ResponseWriter, status int, err error) {
+	switch {
+	case errors.Is(err, config.ErrNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, config.ErrExists), errors.Is(err, config.ErrInUse):
+		status = http.StatusConflict
+	case errors.Is(err, config.ErrInvalid):
+		status = http.StatusBadRequest
+	}
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+// decodeJSON reads the request body into v
+func decodeJSON(r *http.Request, v interface{}) error {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		return fmt.Errorf("invalid request body: %v", err)
+	}
+	return nil
+}
+
+func (s *Server) handleGetGoals(w http.ResponseWriter, r *http.Request) {
+	goal, err := config.LoadGoal()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	progress, err := config.LoadProgress()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"goal":     goal,
+		"progress": progress,
+	})
+}
+
+func (s *Server) handleSetGoals(w http.ResponseWriter, r *http.Request) {
+	var goal config.Goal
+	if err := decodeJSON(r, &goal); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := config.ValidateGoal(goal); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if goal.StartDate.IsZero() {
+		goal.StartDate = time.Now().UTC()
+	}
+
+	if err := config.SaveGoal(goal); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if err := config.UpdateProgress(); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, goal)
+}
+
+func (s *Server) handleGoalHistory(w http.ResponseWriter, r *http.Request) {
+	days := 30
+	if value := r.URL.Query().Get("days"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > 366 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("days must be between 1 and 366"))
+			return
+		}
+		days = parsed
+	}
+
+	history, err := config.GoalHistory()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if len(history) > days {
+		history = history[len(history)-days:]
+	}
+	writeJSON(w, http.StatusOK, history)
+}
+
+func (s *Server) handleNamedGoals(w http.ResponseWriter, r *http.Request) {
+	named, err := config.NamedGoalsProgress()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, named)
+}
+
+func (s *Server) handleAddNamedGoal(w http.ResponseWriter, r *http.Request) {
+	var goal config.NamedGoal
+	if err := decodeJSON(r, &goal); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	goal, err := config.AddNamedGoal(goal)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, goal)
+}
+
+func (s *Server) handleRemoveNamedGoal(w http.ResponseWriter, r *http.Request) {
+	if err := config.RemoveNamedGoal(mux.Vars(r)["name"]); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleAchievements(w http.ResponseWriter, r *http.Request) {
+	daemon.AnnounceAchievements()
+	achievements, err := config.Achievements()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, achievements)
+}
+
+func (s *Server) handleAddAchievement(w http.ResponseWriter, r *http.Request) {
+	var rule config.Achievement
+	if err := decodeJSON(r, &rule); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := config.AddAchievement(rule); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	daemon.AnnounceAchievements()
+	writeJSON(w, http.StatusCreated, rule)
+}
+
+func (s *Server) handleRemoveAchievement(w http.ResponseWriter, r *http.Request) {
+	if err := config.RemoveAchievement(mux.Vars(r)["id"]); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleCreateProfile(w http.ResponseWriter, r *http.Request) {
+	var profile config.Profile
+	if err := decodeJSON(r, &profile); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := config.AddProfile(profile); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, profile)
+}
+
+func (s *Server) handleGetProfile(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	profile, err := config.GetProfile(name)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, profile)
+}
+
+func (s *Server) handleUpdateProfile(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+
+	var profile config.Profile
+	if err := decodeJSON(r, &profile); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if profile.Name == "" {
+		profile.Name = name
+	}
+
+	if err := config.UpdateProfile(name, profile); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, profile)
+}
+
+func (s *Server) handleAdaptiveProfile(w http.ResponseWriter, r *http.Request) {
+	profile, err := config.GetProfile(mux.Vars(r)["name"])
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if profile.Adaptive == nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("profile '%s' is not adaptive", profile.Name))
+		return
+	}
+
+	next, err := config.DecideAdaptive(profile, time.Now())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	log, err := config.LoadAdaptiveLog(profile.Name)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if log == nil {
+		log = []config.AdaptiveDecision{}
+	}
+	writeJSON(w, http.StatusOK, AdaptiveExplanation{
+		Profile: profile.Name,
+		Bounds:  profile.Adaptive.WithDefaults(),
+		Next:    next,
+		Log:     log,
+	})
+}
+
+func (s *Server) handleDeleteProfile(w http.ResponseWriter, r *http.Request) {
+	if err := config.DeleteProfile(mux.Vars(r)["name"]); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleUseProfile(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	profile, err := config.GetProfile(name)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	cfg.CurrentProfile = profile.Name
+	cfg.WorkMinutes = profile.WorkMinutes
+	cfg.BreakMinutes = profile.BreakMinutes
+	cfg.NumSessions = profile.NumSessions
+	if err := config.SaveConfig(cfg); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, profile)
+}
+
+func (s *Server) handleThemes(w http.ResponseWriter, r *http.Request) {
+	current, err := config.LoadTheme()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	names := make([]string, 0, len(config.AvailableThemes))
+	for name := range config.AvailableThemes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	themes := make([]WebTheme, 0, len(names))
+	for _, name := range names {
+		themes = append(themes, webTheme(name))
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"themes":  themes,
+		"current": webTheme(current.Name),
+	})
+}
+
+func (s *Server) handleSetTheme(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Name string `json:"name"`
+	}
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	theme, ok := config.AvailableThemes[req.Name]
+	if !ok {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("theme '%s' not found", req.Name))
+		return
+	}
+	if err := config.SaveTheme(theme); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, webTheme(theme.Name))
+}
+
+func (s *Server) handleListTasks(w http.ResponseWriter, r *http.Request) {
+	tasks, err := config.LoadTasks()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	query := r.URL.Query()
+	rules, now := clock.Load(), time.Now()
+	filter := config.TaskFilter{
+		Completed: query.Get("completed") != "false",
+		Archived:  query.Get("archived") == "true",
+		Project:   query.Get("project"),
+		Tag:       query.Get("tag"),
+		Overdue:   query.Get("overdue") == "true",
+	}
+	if filter.Priority, err = config.ParsePriority(query.Get("priority")); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if filter.DueBy, err = config.ParseDueDate(query.Get("due_by"), rules, now); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if filter.Query, err = config.ParseTaskQuery(query.Get("q"), rules, now); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	result := config.FilterTasks(tasks.Tasks, filter, rules, now)
+	if err := config.SortTasks(result, query.Get("sort")); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (s *Server) handleCreateTask(w http.ResponseWriter, r *http.Request) {
+	var req taskRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if strings.TrimSpace(req.Title) == "" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("task title is required"))
+		return
+	}
+	fields, err := req.task()
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	task, err := config.AddTask(fields)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, task)
+}
+
+func (s *Server) handleGetTask(w http.ResponseWriter, r *http.Request) {
+	task, err := config.GetTask(mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, task)
+}
+
+func (s *Server) handleUpdateTask(w http.ResponseWriter, r *http.Request) {
+	var req taskRequest
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if strings.TrimSpace(req.Title) == "" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("task title is required"))
+		return
+	}
+
+	fields, err := req.task()
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	task, err := config.UpdateTask(mux.Vars(r)["id"], func(task *config.Task) {
+		task.Title = fields.Title
+		task.Description = fields.Description
+		task.Tags = fields.Tags
+		task.Estimate = fields.Estimate
+		task.Priority = fields.Priority
+		task.Due = fields.Due
+		task.Project = fields.Project
+	})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, task)
+}
+
+func (s *Server) handleDeleteTask(w http.ResponseWriter, r *http.Request) {
+	if _, err := config.DeleteTask(mux.Vars(r)["id"]); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleCompleteTask(w http.ResponseWriter, r *http.Request) {
+	task, err := config.CompleteTask(mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	daemon.AnnounceAchievements()
+	writeJSON(w, http.StatusOK, task)
+}
+
+func (s *Server) handleReopenTask(w http.ResponseWriter, r *http.Request) {
+	task, err := config.ReopenTask(mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, task)
+}
+
+func (s *Server) handleArchiveTask(w http.ResponseWriter, r *http.Request) {
+	task, err := config.ArchiveTask(mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, task)
+}
+
+func (s *Server) handleStats(w http.ResponseWriter, r *http.Request) {
+	totalSessions, totalMinutes, avgPerDay, err := logs.GetSessionStats()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	todaySessions, todayMinutes, err := logs.GetDailyStats()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	intervals, err := logs.GetIntervalStats()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	progress, _ := config.LoadProgress()
+	named, err := config.NamedGoalsProgress()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, Stats{
+		TodaySessions:      todaySessions,
+		TodayMinutes:       todayMinutes,
+		TotalSessions:      totalSessions,
+		TotalMinutes:       totalMinutes,
+		AvgSessionsPerDay:  avgPerDay,
+		CurrentStreak:      progress.CurrentStreak,
+		LongestStreak:      progress.LongestStreak,
+		DailySessionTarget: progress.TargetToday.Sessions,
+		DailyMinutesTarget: progress.TargetToday.Minutes,
+		RestToday:          progress.RestToday,
+		Freezes:            progress.Freezes,
+		Week:               progress.Week,
+		Month:              progress.Month,
+		Goals:              named,
+		Intervals:          intervals,
+	})
+}
+
+func (s *Server) handleCalendar(w http.ResponseWriter, r *http.Request) {
+	months := 3
+	if value := r.URL.Query().Get("months"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > 24 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("months must be between 1 and 24"))
+			return
+		}
+		months = parsed
+	}
+
+	days, err := config.GetCalendarDays(months)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"months": months,
+		"days":   days,
+	})
+}
+
+func (s *Server) handleInsights(w http.ResponseWriter, r *http.Request) {
+	days := config.DefaultInsightDays
+	if value := r.URL.Query().Get("days"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > config.MaxInsightDays {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("days must be between 1 and %d", config.MaxInsightDays))
+			return
+		}
+		days = parsed
+	}
+
+	insights, err := config.AnalyzeInsights(days)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, insights)
+}
+
+func (s *Server) handleCoach(w http.ResponseWriter, r *http.Request) {
+	coaching, err := config.WeeklyCoaching(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, coaching)
+}
+
+func (s *Server) handleReport(w http.ResponseWriter, r *http.Request) {
+	date, err := report.ParseDate(r.URL.Query().Get("date"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if _, _, err := report.Bounds(mux.Vars(r)["period"], date); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	review, err := report.Build(mux.Vars(r)["period"], date)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	contentType := map[string]string{
+		report.FormatText:     "text/plain; charset=utf-8",
+		report.FormatMarkdown: "text/markdown; charset=utf-8",
+		report.FormatHTML:     "text/html; charset=utf-8",
+	}
+	switch format := r.URL.Query().Get("format"); format {
+	case "", "json":
+		writeJSON(w, http.StatusOK, review)
+	case report.FormatText, report.FormatMarkdown, report.FormatHTML:
+		var buf bytes.Buffer
+		if err := report.Render(&buf, review, format); err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		w.Header().Set("Content-Type", contentType[format])
+		buf.WriteTo(w)
+	default:
+		writeError(w, http.StatusBadRequest, fmt.Errorf("unknown report format %q; use json, text, markdown or html", format))
+	}
+}
+
+func (s *Server) handleAddPlugin(w http.ResponseWriter, r *http.Request) {
+	var plugin config.Plugin
+	if err := decodeJSON(r, &plugin); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if plugin.Name == "" || plugin.Script == "" || len(plugin.Triggers) == 0 {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("plugin name, script and triggers are required"))
+		return
+	}
+
+	if err := config.AddPlugin(plugin); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, plugin)
+}
+
+func (s *Server) handleEnablePlugin(enabled bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name := mux.Vars(r)["name"]
+		if err := config.EnablePlugin(name, enabled); err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"name":    name,
+			"enabled": enabled,
+		})
+	}
+}
+
+func (s *Server) handleSetPrivacy(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		PrivacyMode bool `json:"privacy_mode"`
+	}
+	if err := decodeJSON(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	cfg.PrivacyMode = req.PrivacyMode
+	if req.PrivacyMode {
+		cfg.CloudSync = false // Disable cloud sync in privacy mode
+	}
+	if err := config.SaveConfig(cfg); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	s.handlePrivacyStatus(w, r)
+}
+
+// DaySettings is the response of GET /api/day
+type DaySettings struct {
+	clock.Settings
+	Today   string    `json:"today"`    // Date the current moment counts towards
+	StartAt time.Time `json:"start_at"` // When today started
+	EndAt   time.Time `json:"end_at"`   // When today ends
+}
+
+func (s *Server) handleDay(w http.ResponseWriter, r *http.Request) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	rules := clock.Load()
+	start, end := rules.Bounds(time.Now())
+	writeJSON(w, http.StatusOK, DaySettings{
+		Settings: cfg.Settings,
+		Today:    rules.Key(time.Now()),
+		StartAt:  start,
+		EndAt:    end,
+	})
+}
+
+func (s *Server) handleSetDay(w http.ResponseWriter, r *http.Request) {
+	var settings clock.Settings
+	if err := decodeJSON(r, &settings); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := config.ValidateDaySettings(settings); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	cfg.Settings = settings
+	if err := config.SaveConfig(cfg); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if err := config.UpdateProgress(); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	s.handleDay(w, r)
+}
+
+func (s *Server) handleSyncStatus(w http.ResponseWriter, r *http.Request) {
+	status, err := config.GetSyncStatus()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, status)
+}
+
+func (s *Server) handleExport(w http.ResponseWriter, r *http.Request) {
+	filename := "pom-export-" + time.Now().Format("2006-01-02")
+
+	switch format := r.URL.Query().Get("format"); format {
+	case "", "json":
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename+".json"))
+		writeJSON(w, http.StatusOK, config.BuildExport())
+	case "csv":
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename+".csv"))
+		if err := config.WriteSessionsCSV(w); err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+		}
+	default:
+		writeError(w, http.StatusBadRequest, fmt.Errorf("unknown export format %q; use json or csv", format))
+	}
+}
+
+func (s *Server) handleImport(w http.ResponseWriter, r *http.Request) {
+	var data config.ExportData
+	if err := decodeJSON(r, &data); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := config.ImportData(data); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"tasks":    len(data.Tasks),
+		"profiles": len(data.Profiles),
+	})
+}
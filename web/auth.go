@@ -0,0 +1,262 @@
+package web
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"html"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Flack74/pom/store"
+)
+
+const (
+	// TokenHeader carries the access token for API clients. "Authorization:
+	// Bearer <token>" is accepted as well.
+	TokenHeader = "X-Pom-Token"
+
+	// CSRFHeader must echo the CSRF cookie on mutating requests made with a
+	// session cookie
+	CSRFHeader = "X-CSRF-Token"
+
+	sessionCookie = "pom_session"
+	csrfCookie    = "pom_csrf"
+)
+
+// TokenPath returns the file holding the web access token
+func TokenPath() (string, error) {
+	dir, err := store.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "web.token"), nil
+}
+
+// LoadToken returns the web access token, generating and saving one on first use
+func LoadToken() (string, error) {
+	path, err := TokenPath()
+	if err != nil {
+		return "", err
+	}
+
+	data, err := os.ReadFile(path)
+	if err == nil {
+		if token := strings.TrimSpace(string(data)); token != "" {
+			return token, nil
+		}
+	} else if !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed to read web token: %v", err)
+	}
+
+	return ResetToken()
+}
+
+// ResetToken replaces the web access token, signing out every browser
+func ResetToken() (string, error) {
+	path, err := TokenPath()
+	if err != nil {
+		return "", err
+	}
+
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate web token: %v", err)
+	}
+	token := hex.EncodeToString(buf)
+
+	if err := store.WriteFileAtomic(path, []byte(token+"\n"), 0600); err != nil {
+		return "", fmt.Errorf("failed to save web token: %v", err)
+	}
+	return token, nil
+}
+
+// auth checks requests against the access token
+type auth struct {
+	token   string
+	session string // Value of the session cookie
+	csrf    string // Value of the CSRF cookie and header
+}
+
+// newAuth derives the cookie values from token, so resetting the token also
+// invalidates existing sessions
+func newAuth(token string) *auth {
+	return &auth{
+		token:   token,
+		session: derive(token, "session"),
+		csrf:    derive(token, "csrf"),
+	}
+}
+
+// derive returns an HMAC of purpose keyed by the token
+func derive(token, purpose string) string {
+	mac := hmac.New(sha256.New, []byte(token))
+	mac.Write([]byte(purpose))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// equal compares secrets in constant time
+func equal(a, b string) bool {
+	return hmac.Equal([]byte(a), []byte(b))
+}
+
+// headerToken returns the token sent by an API client, if any
+func headerToken(r *http.Request) string {
+	if token := r.Header.Get(TokenHeader); token != "" {
+		return token
+	}
+	if value := r.Header.Get("Authorization"); strings.HasPrefix(value, "Bearer ") {
+		return strings.TrimPrefix(value, "Bearer ")
+	}
+	return ""
+}
+
+// hasSession reports whether the request carries a valid session cookie
+func (a *auth) hasSession(r *http.Request) bool {
+	cookie, err := r.Cookie(sessionCookie)
+	return err == nil && equal(cookie.Value, a.session)
+}
+
+// middleware rejects unauthenticated requests and, for browsers, mutating
+// requests without a matching CSRF token. Requests authenticated by header
+// cannot be forged cross-site and skip the CSRF check.
+func (a *auth) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token := headerToken(r); token != "" {
+			if !equal(token, a.token) {
+				writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if !a.hasSession(r) {
+			if strings.HasPrefix(r.URL.Path, "/api/") || r.URL.Path == "/ws" {
+				writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "authentication required"})
+			} else {
+				http.Redirect(w, r, "/login", http.StatusSeeOther)
+			}
+			return
+		}
+
+		if isMutating(r.Method) && !equal(r.Header.Get(CSRFHeader), a.csrf) {
+			writeJSON(w, http.StatusForbidden, map[string]string{"error": "missing or invalid CSRF token"})
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isMutating reports whether method can change server state
+func isMutating(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+	return true
+}
+
+// checkOrigin accepts WebSocket handshakes from the server's own pages and
+// from clients that send no Origin (command-line tools). Browsers always send
+// one, so other sites cannot open a socket with the user's cookie.
+func checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(u.Host, r.Host)
+}
+
+// setSession signs the browser in
+func (a *auth) setSession(w http.ResponseWriter, r *http.Request) {
+	secure := r.TLS != nil
+	http.SetCookie(w, &http.Cookie{
+		Name: sessionCookie, Value: a.session, Path: "/",
+		HttpOnly: true, Secure: secure, SameSite: http.SameSiteStrictMode,
+	})
+	// Readable by the page so it can echo it in CSRFHeader
+	http.SetCookie(w, &http.Cookie{
+		Name: csrfCookie, Value: a.csrf, Path: "/",
+		Secure: secure, SameSite: http.SameSiteStrictMode,
+	})
+}
+
+// handleLogin serves the login form and signs the browser in with a valid
+// token. GET /login?token=... signs in directly, so the link printed by
+// `pom web` can be opened as is.
+func (a *auth) handleLogin(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	if r.Method == http.MethodPost {
+		if !checkOrigin(r) {
+			http.Error(w, "cross-origin login refused", http.StatusForbidden)
+			return
+		}
+		token = r.FormValue("token")
+	}
+
+	if token == "" {
+		writeLoginPage(w, http.StatusOK, "")
+		return
+	}
+	if !equal(strings.TrimSpace(token), a.token) {
+		writeLoginPage(w, http.StatusUnauthorized, "Invalid token")
+		return
+	}
+
+	a.setSession(w, r)
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+// handleLogout clears the session cookies
+func (a *auth) handleLogout(w http.ResponseWriter, r *http.Request) {
+	for _, name := range []string{sessionCookie, csrfCookie} {
+		http.SetCookie(w, &http.Cookie{Name: name, Value: "", Path: "/", MaxAge: -1})
+	}
+	http.Redirect(w, r, "/login", http.StatusSeeOther)
+}
+
+// localOnly restricts a handler to same-origin requests from this machine.
+// Plugins run shell scripts, so adding or enabling one is never offered to
+// other hosts, even with a valid token and --bind 0.0.0.0.
+func localOnly(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil || !isLoopback(host) {
+			writeJSON(w, http.StatusForbidden, map[string]string{"error": "plugins can only be changed from this machine"})
+			return
+		}
+		if !checkOrigin(r) {
+			writeJSON(w, http.StatusForbidden, map[string]string{"error": "cross-origin request refused"})
+			return
+		}
+		next(w, r)
+	}
+}
+
+// isLoopback reports whether host only accepts local connections
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// writeLoginPage renders the login form with an optional error message
+func writeLoginPage(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "text/html")
+	w.WriteHeader(status)
+	w.Write([]byte(getLoginUI(html.EscapeString(message))))
+}
@@ -0,0 +1,919 @@
+package web
+
+import "net/http"
+
+// handleOpenAPI serves the OpenAPI description of the REST API
+func handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(openAPISpec))
+}
+
+// openAPISpec documents every /api endpoint. Keep it in step with registerAPI.
+const openAPISpec = `{
+  "openapi": "3.0.3",
+  "info": {
+    "title": "Pom API",
+    "version": "1.0.0",
+    "description": "REST API of the pom web server. The live timer is also available as a WebSocket at /ws, which streams TimerMessage objects and accepts TimerCommand objects. Every endpoint requires the access token from 'pom web token', sent in the X-Pom-Token header or as a bearer token. Browsers sign in at /login instead and must echo the pom_csrf cookie in the X-CSRF-Token header on POST, PUT and DELETE requests."
+  },
+  "security": [{"token": []}, {"bearer": []}, {"session": []}],
+  "paths": {
+    "/api/session": {
+      "get": {
+        "summary": "Current timer state",
+        "responses": {"200": {"description": "Timer state", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/TimerSession"}}}}}
+      }
+    },
+    "/api/session/start": {
+      "post": {
+        "summary": "Start a session",
+        "requestBody": {"required": true, "content": {"application/json": {"schema": {"$ref": "#/components/schemas/TimerSession"}}}},
+        "responses": {
+          "200": {"description": "Started session", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/TimerSession"}}}},
+          "409": {"description": "A session is already running"}
+        }
+      }
+    },
+    "/api/goals": {
+      "get": {
+        "summary": "Goals and progress",
+        "responses": {"200": {"description": "Goal and progress", "content": {"application/json": {"schema": {
+          "type": "object",
+          "properties": {"goal": {"$ref": "#/components/schemas/Goal"}, "progress": {"$ref": "#/components/schemas/GoalProgress"}}
+        }}}}}
+      },
+      "put": {
+        "summary": "Set the goals",
+        "requestBody": {"required": true, "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Goal"}}}},
+        "responses": {"200": {"description": "Saved goal", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Goal"}}}}, "400": {"$ref": "#/components/responses/Error"}}
+      }
+    },
+    "/api/goals/named": {
+      "get": {
+        "summary": "Named goals and their progress this period",
+        "responses": {"200": {"description": "Progress for each named goal", "content": {"application/json": {"schema": {"type": "array", "items": {"$ref": "#/components/schemas/NamedGoalProgress"}}}}}}
+      },
+      "post": {
+        "summary": "Add a named goal for a tag, task or profile",
+        "requestBody": {"required": true, "content": {"application/json": {"schema": {"$ref": "#/components/schemas/NamedGoal"}}}},
+        "responses": {"201": {"description": "Added goal", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/NamedGoal"}}}}, "400": {"$ref": "#/components/responses/Error"}, "409": {"$ref": "#/components/responses/Error"}}
+      }
+    },
+    "/api/goals/named/{name}": {
+      "parameters": [{"name": "name", "in": "path", "required": true, "schema": {"type": "string"}}],
+      "delete": {
+        "summary": "Remove a named goal",
+        "responses": {"204": {"description": "Removed"}, "404": {"$ref": "#/components/responses/Error"}}
+      }
+    },
+    "/api/achievements": {
+      "get": {
+        "summary": "Achievements and the progress towards each",
+        "description": "Checks for new unlocks first, so unlocked achievements are up to date. Unlocked ones come first in the order they were earned.",
+        "responses": {"200": {"description": "Every built-in and custom achievement", "content": {"application/json": {"schema": {"type": "array", "items": {"$ref": "#/components/schemas/AchievementStatus"}}}}}}
+      }
+    },
+    "/api/achievements/rules": {
+      "post": {
+        "summary": "Add a custom achievement rule",
+        "description": "A rule with the ID of a built-in achievement replaces it.",
+        "requestBody": {"required": true, "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Achievement"}}}},
+        "responses": {"201": {"description": "Added rule", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Achievement"}}}}, "400": {"$ref": "#/components/responses/Error"}, "409": {"$ref": "#/components/responses/Error"}}
+      }
+    },
+    "/api/achievements/rules/{id}": {
+      "parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "string"}}],
+      "delete": {
+        "summary": "Remove a custom achievement rule",
+        "description": "Achievements already unlocked stay unlocked.",
+        "responses": {"204": {"description": "Removed"}, "404": {"$ref": "#/components/responses/Error"}}
+      }
+    },
+    "/api/goals/history": {
+      "get": {
+        "summary": "Past days met or missed",
+        "description": "Every day up to today judged against the current goal, with the streak recomputed from the session log.",
+        "parameters": [{"name": "days", "in": "query", "schema": {"type": "integer", "minimum": 1, "maximum": 366, "default": 30}}],
+        "responses": {"200": {"description": "One entry per day, oldest first", "content": {"application/json": {"schema": {"type": "array", "items": {"$ref": "#/components/schemas/GoalDay"}}}}}, "400": {"$ref": "#/components/responses/Error"}}
+      }
+    },
+    "/api/profiles": {
+      "get": {
+        "summary": "List profiles",
+        "responses": {"200": {"description": "Profiles and the current profile name", "content": {"application/json": {"schema": {
+          "type": "object",
+          "properties": {"profiles": {"type": "array", "items": {"$ref": "#/components/schemas/Profile"}}, "current": {"type": "string"}}
+        }}}}}
+      },
+      "post": {
+        "summary": "Create a profile",
+        "requestBody": {"required": true, "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Profile"}}}},
+        "responses": {"201": {"description": "Created profile", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Profile"}}}}, "400": {"$ref": "#/components/responses/Error"}, "409": {"$ref": "#/components/responses/Error"}}
+      }
+    },
+    "/api/profiles/{name}": {
+      "parameters": [{"name": "name", "in": "path", "required": true, "schema": {"type": "string"}}],
+      "get": {
+        "summary": "Get a profile",
+        "responses": {"200": {"description": "Profile", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Profile"}}}}, "404": {"$ref": "#/components/responses/Error"}}
+      },
+      "put": {
+        "summary": "Replace a profile",
+        "requestBody": {"required": true, "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Profile"}}}},
+        "responses": {"200": {"description": "Updated profile", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Profile"}}}}, "404": {"$ref": "#/components/responses/Error"}}
+      },
+      "delete": {
+        "summary": "Delete a profile",
+        "responses": {"204": {"description": "Deleted"}, "404": {"$ref": "#/components/responses/Error"}}
+      }
+    },
+    "/api/profiles/{name}/use": {
+      "parameters": [{"name": "name", "in": "path", "required": true, "schema": {"type": "string"}}],
+      "post": {
+        "summary": "Make a profile the current one",
+        "responses": {"200": {"description": "Current profile", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Profile"}}}}, "404": {"$ref": "#/components/responses/Error"}}
+      }
+    },
+    "/api/profiles/{name}/adaptive": {
+      "parameters": [{"name": "name", "in": "path", "required": true, "schema": {"type": "string"}}],
+      "get": {
+        "summary": "Explain an adaptive profile's lengths",
+        "description": "Returns the lengths a run started now would use, the signals and reasons behind them, and the lengths chosen for earlier runs.",
+        "responses": {"200": {"description": "Explanation", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/AdaptiveExplanation"}}}}, "400": {"$ref": "#/components/responses/Error"}, "404": {"$ref": "#/components/responses/Error"}}
+      }
+    },
+    "/api/themes": {
+      "get": {
+        "summary": "List themes with their web colors",
+        "responses": {"200": {"description": "Themes and the current theme", "content": {"application/json": {"schema": {
+          "type": "object",
+          "properties": {"themes": {"type": "array", "items": {"$ref": "#/components/schemas/WebTheme"}}, "current": {"$ref": "#/components/schemas/WebTheme"}}
+        }}}}}
+      }
+    },
+    "/api/theme": {
+      "put": {
+        "summary": "Set the theme used by the CLI and the web UI",
+        "requestBody": {"required": true, "content": {"application/json": {"schema": {
+          "type": "object",
+          "required": ["name"],
+          "properties": {"name": {"type": "string"}}
+        }}}},
+        "responses": {"200": {"description": "New theme", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/WebTheme"}}}}, "400": {"$ref": "#/components/responses/Error"}}
+      }
+    },
+    "/api/tasks": {
+      "get": {
+        "summary": "List tasks",
+        "parameters": [
+          {"name": "q", "in": "query", "description": "Task query, e.g. 'tag:work due:<friday !done'; see pom plan list --help", "schema": {"type": "string"}},
+          {"name": "completed", "in": "query", "description": "Set to false to hide completed tasks", "schema": {"type": "boolean", "default": true}},
+          {"name": "archived", "in": "query", "description": "Set to true to include archived tasks", "schema": {"type": "boolean", "default": false}},
+          {"name": "project", "in": "query", "schema": {"type": "string"}},
+          {"name": "tag", "in": "query", "schema": {"type": "string"}},
+          {"name": "priority", "in": "query", "schema": {"type": "string", "enum": ["high", "medium", "low"]}},
+          {"name": "overdue", "in": "query", "description": "Set to true for open tasks past their due date only", "schema": {"type": "boolean", "default": false}},
+          {"name": "due_by", "in": "query", "description": "Only tasks due on or before this date: YYYY-MM-DD, today, tomorrow or a weekday", "schema": {"type": "string"}},
+          {"name": "sort", "in": "query", "description": "Tasks without the field sorted on come last", "schema": {"type": "string", "enum": ["created", "priority", "due", "estimate", "variance", "project", "title"], "default": "created"}}
+        ],
+        "responses": {"200": {"description": "Tasks", "content": {"application/json": {"schema": {"type": "array", "items": {"$ref": "#/components/schemas/Task"}}}}}, "400": {"$ref": "#/components/responses/Error"}}
+      },
+      "post": {
+        "summary": "Create a task",
+        "requestBody": {"required": true, "content": {"application/json": {"schema": {"$ref": "#/components/schemas/TaskInput"}}}},
+        "responses": {"201": {"description": "Created task", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Task"}}}}, "400": {"$ref": "#/components/responses/Error"}}
+      }
+    },
+    "/api/tasks/{id}": {
+      "parameters": [{"name": "id", "in": "path", "required": true, "description": "Task number, ID or a prefix of exactly one ID", "schema": {"type": "string"}}],
+      "get": {
+        "summary": "Get a task",
+        "responses": {"200": {"description": "Task", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Task"}}}}, "404": {"$ref": "#/components/responses/Error"}}
+      },
+      "put": {
+        "summary": "Edit a task",
+        "requestBody": {"required": true, "content": {"application/json": {"schema": {"$ref": "#/components/schemas/TaskInput"}}}},
+        "responses": {"200": {"description": "Updated task", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Task"}}}}, "404": {"$ref": "#/components/responses/Error"}}
+      },
+      "delete": {
+        "summary": "Delete a task",
+        "responses": {"204": {"description": "Deleted"}, "404": {"$ref": "#/components/responses/Error"}}
+      }
+    },
+    "/api/tasks/{id}/complete": {
+      "parameters": [{"name": "id", "in": "path", "required": true, "description": "Task number, ID or a prefix of exactly one ID", "schema": {"type": "string"}}],
+      "post": {
+        "summary": "Mark a task as completed",
+        "responses": {"200": {"description": "Completed task", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Task"}}}}, "404": {"$ref": "#/components/responses/Error"}}
+      }
+    },
+    "/api/tasks/{id}/reopen": {
+      "parameters": [{"name": "id", "in": "path", "required": true, "description": "Task number, ID or a prefix of exactly one ID", "schema": {"type": "string"}}],
+      "post": {
+        "summary": "Mark a completed or archived task as open again",
+        "responses": {"200": {"description": "Reopened task", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Task"}}}}, "404": {"$ref": "#/components/responses/Error"}}
+      }
+    },
+    "/api/tasks/{id}/archive": {
+      "parameters": [{"name": "id", "in": "path", "required": true, "description": "Task number, ID or a prefix of exactly one ID", "schema": {"type": "string"}}],
+      "post": {
+        "summary": "Hide a task from lists, keeping its history",
+        "responses": {"200": {"description": "Archived task", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Task"}}}}, "404": {"$ref": "#/components/responses/Error"}}
+      }
+    },
+    "/api/stats": {
+      "get": {
+        "summary": "Session statistics",
+        "responses": {"200": {"description": "Statistics", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Stats"}}}}}
+      }
+    },
+    "/api/calendar": {
+      "get": {
+        "summary": "Daily focus activity",
+        "parameters": [{"name": "months", "in": "query", "schema": {"type": "integer", "minimum": 1, "maximum": 24, "default": 3}}],
+        "responses": {"200": {"description": "One entry per day, oldest first", "content": {"application/json": {"schema": {
+          "type": "object",
+          "properties": {"months": {"type": "integer"}, "days": {"type": "array", "items": {"$ref": "#/components/schemas/CalendarDay"}}}
+        }}}}}
+      }
+    },
+    "/api/insights": {
+      "get": {
+        "summary": "Statistical analysis of recent focus sessions",
+        "description": "Completion by weekday and hour, rolling weeks with a trend, breakdowns by profile, task and tag, interruptions and pauses, and the focus score.",
+        "parameters": [{"name": "days", "in": "query", "schema": {"type": "integer", "minimum": 1, "maximum": 365, "default": 28}}],
+        "responses": {"200": {"description": "Insights", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Insights"}}}}, "400": {"$ref": "#/components/responses/Error"}}
+      }
+    },
+    "/api/insights/coach": {
+      "get": {
+        "summary": "Weekly coaching note",
+        "description": "Written by the configured OpenAI-compatible endpoint from an anonymised summary of the last 7 days, or by the built-in rules when no endpoint is set, privacy mode is on, or the endpoint fails.",
+        "responses": {"200": {"description": "Coaching note", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Coaching"}}}}}
+      }
+    },
+    "/api/insights/today": {
+      "get": {
+        "summary": "Today's completed sessions and focus time",
+        "responses": {"200": {"description": "Today's totals", "content": {"application/json": {"schema": {
+          "type": "object",
+          "properties": {"sessions": {"type": "integer"}, "minutes": {"type": "integer"}, "hours": {"type": "number"}}
+        }}}}}
+      }
+    },
+    "/api/insights/suggestions": {
+      "get": {
+        "summary": "Suggested session settings",
+        "responses": {"200": {"description": "Suggestions", "content": {"application/json": {"schema": {"type": "array", "items": {"$ref": "#/components/schemas/Suggestion"}}}}}}
+      }
+    },
+    "/api/report/{period}": {
+      "get": {
+        "summary": "Weekly or monthly review",
+        "description": "Focus time against the daily goal, best and worst days, task progress, streak changes, a comparison with the previous period and suggestions. Weeks run Monday to Sunday; the current period is compared with the same number of days of the previous one.",
+        "parameters": [
+          {"name": "period", "in": "path", "required": true, "schema": {"type": "string", "enum": ["week", "month"]}},
+          {"name": "date", "in": "query", "description": "Any day in the period (YYYY-MM-DD), default today", "schema": {"type": "string", "format": "date"}},
+          {"name": "format", "in": "query", "schema": {"type": "string", "enum": ["json", "text", "markdown", "html"], "default": "json"}}
+        ],
+        "responses": {
+          "200": {"description": "Report", "content": {
+            "application/json": {"schema": {"$ref": "#/components/schemas/Report"}},
+            "text/plain": {"schema": {"type": "string"}},
+            "text/markdown": {"schema": {"type": "string"}},
+            "text/html": {"schema": {"type": "string"}}
+          }},
+          "400": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/api/plugins": {
+      "get": {
+        "summary": "List plugins",
+        "responses": {"200": {"description": "Plugins", "content": {"application/json": {"schema": {"type": "array", "items": {"$ref": "#/components/schemas/Plugin"}}}}}}
+      },
+      "post": {
+        "summary": "Add a plugin",
+        "description": "Only accepted from the machine running pom web.",
+        "requestBody": {"required": true, "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Plugin"}}}},
+        "responses": {"201": {"description": "Added plugin", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Plugin"}}}}, "400": {"$ref": "#/components/responses/Error"}, "403": {"$ref": "#/components/responses/Error"}}
+      }
+    },
+    "/api/plugins/{name}/enable": {
+      "parameters": [{"name": "name", "in": "path", "required": true, "schema": {"type": "string"}}],
+      "post": {"summary": "Enable a plugin", "description": "Only accepted from the machine running pom web.", "responses": {"200": {"description": "Plugin state"}, "403": {"$ref": "#/components/responses/Error"}, "404": {"$ref": "#/components/responses/Error"}}}
+    },
+    "/api/plugins/{name}/disable": {
+      "parameters": [{"name": "name", "in": "path", "required": true, "schema": {"type": "string"}}],
+      "post": {"summary": "Disable a plugin", "description": "Only accepted from the machine running pom web.", "responses": {"200": {"description": "Plugin state"}, "403": {"$ref": "#/components/responses/Error"}, "404": {"$ref": "#/components/responses/Error"}}}
+    },
+    "/api/privacy": {
+      "get": {
+        "summary": "Privacy settings",
+        "responses": {"200": {"description": "Privacy settings", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Privacy"}}}}}
+      },
+      "put": {
+        "summary": "Turn privacy mode on or off",
+        "requestBody": {"required": true, "content": {"application/json": {"schema": {
+          "type": "object",
+          "properties": {"privacy_mode": {"type": "boolean"}}
+        }}}},
+        "responses": {"200": {"description": "Privacy settings", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Privacy"}}}}}
+      }
+    },
+    "/api/privacy/status": {
+      "get": {
+        "summary": "Privacy settings (same as GET /api/privacy)",
+        "responses": {"200": {"description": "Privacy settings", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Privacy"}}}}}
+      }
+    },
+    "/api/day": {
+      "get": {
+        "summary": "Timezone and start of day",
+        "description": "How sessions, stored in UTC, are grouped into days for goals, streaks, the heatmap, insights and reports.",
+        "responses": {"200": {"description": "Day settings", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/DaySettings"}}}}}
+      },
+      "put": {
+        "summary": "Set the timezone and start of day",
+        "requestBody": {"required": true, "content": {"application/json": {"schema": {
+          "type": "object",
+          "properties": {
+            "timezone": {"type": "string", "description": "IANA name, empty for the system timezone", "example": "Europe/Berlin"},
+            "day_start": {"type": "string", "description": "HH:MM, empty for midnight", "example": "04:00"}
+          }
+        }}}},
+        "responses": {"200": {"description": "Day settings", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/DaySettings"}}}}, "400": {"$ref": "#/components/responses/Error"}}
+      }
+    },
+    "/api/sync/status": {
+      "get": {
+        "summary": "Cloud sync configuration",
+        "responses": {"200": {"description": "Sync status", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/SyncStatus"}}}}}
+      }
+    },
+    "/api/export": {
+      "get": {
+        "summary": "Download a backup",
+        "parameters": [{"name": "format", "in": "query", "schema": {"type": "string", "enum": ["json", "csv"], "default": "json"}}],
+        "responses": {
+          "200": {"description": "Full JSON backup, or the session log as CSV", "content": {
+            "application/json": {"schema": {"$ref": "#/components/schemas/ExportData"}},
+            "text/csv": {"schema": {"type": "string"}}
+          }},
+          "400": {"$ref": "#/components/responses/Error"}
+        }
+      }
+    },
+    "/api/import": {
+      "post": {
+        "summary": "Restore tasks, profiles and settings from a JSON backup",
+        "requestBody": {"required": true, "content": {"application/json": {"schema": {"$ref": "#/components/schemas/ExportData"}}}},
+        "responses": {"200": {"description": "Number of imported tasks and profiles"}, "400": {"$ref": "#/components/responses/Error"}}
+      }
+    }
+  },
+  "components": {
+    "securitySchemes": {
+      "token": {"type": "apiKey", "in": "header", "name": "X-Pom-Token"},
+      "bearer": {"type": "http", "scheme": "bearer"},
+      "session": {"type": "apiKey", "in": "cookie", "name": "pom_session"}
+    },
+    "responses": {
+      "Error": {"description": "Error", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Error"}}}}
+    },
+    "schemas": {
+      "Error": {"type": "object", "properties": {"error": {"type": "string"}}},
+      "TimerSession": {
+        "type": "object",
+        "properties": {
+          "id": {"type": "string"},
+          "work_time": {"type": "integer", "description": "Minutes"},
+          "break_time": {"type": "integer", "description": "Minutes"},
+          "sessions": {"type": "integer"},
+          "current_session": {"type": "integer"},
+          "is_running": {"type": "boolean"},
+          "is_paused": {"type": "boolean"},
+          "is_break": {"type": "boolean"},
+          "time_left": {"type": "integer", "description": "Seconds"},
+          "duration": {"type": "integer", "description": "Planned seconds of the current phase"},
+          "profile": {"type": "string"},
+          "task_id": {"type": "string"},
+          "open": {"type": "boolean", "description": "The current interval counts up until it is ended"},
+          "elapsed": {"type": "integer", "description": "Seconds spent in the current phase"},
+          "interval": {"type": "string", "description": "Name of the current interval"},
+          "step": {"type": "integer", "description": "Index of the current interval in intervals"},
+          "intervals": {"type": "array", "items": {"$ref": "#/components/schemas/Interval"}, "description": "The run's interval sequence. On start it overrides the work/break settings and the profile's sequence."}
+        }
+      },
+      "Interval": {
+        "type": "object",
+        "required": ["kind", "minutes"],
+        "properties": {
+          "name": {"type": "string", "example": "Long break"},
+          "kind": {"type": "string", "enum": ["focus", "break"]},
+          "minutes": {"type": "integer"},
+          "open": {"type": "boolean", "description": "Focus that counts up until it is ended; minutes is filled in afterwards"},
+          "ratio": {"type": "number", "description": "Break of one minute per this many minutes of the focus before it"}
+        }
+      },
+      "TimerMessage": {
+        "type": "object",
+        "properties": {
+          "type": {"type": "string", "enum": ["state", "tick", "started", "phase", "paused", "resumed", "skipped", "finished", "stopped", "shutdown", "error"]},
+          "session": {"$ref": "#/components/schemas/TimerSession"},
+          "message": {"type": "string"}
+        }
+      },
+      "TimerCommand": {
+        "type": "object",
+        "properties": {
+          "type": {"type": "string", "enum": ["start", "flow", "pause", "resume", "skip", "stop", "status"], "description": "flow starts an open-ended focus interval; skip ends it and starts its break"},
+          "ratio": {"type": "number", "description": "Flow break ratio, focus minutes per break minute"},
+          "work_time": {"type": "integer"},
+          "break_time": {"type": "integer"},
+          "sessions": {"type": "integer"},
+          "profile": {"type": "string", "description": "Without work_time the profile's own sequence is run"},
+          "task_id": {"type": "string"},
+          "intervals": {"type": "array", "items": {"$ref": "#/components/schemas/Interval"}}
+        }
+      },
+      "Goal": {
+        "type": "object",
+        "properties": {
+          "daily_session_target": {"type": "integer"},
+          "daily_minutes": {"type": "integer"},
+          "weekdays": {"type": "object", "description": "Targets for particular weekdays, keyed mon to sun", "additionalProperties": {"$ref": "#/components/schemas/DayTarget"}},
+          "rest_days": {"type": "array", "items": {"type": "string", "enum": ["mon", "tue", "wed", "thu", "fri", "sat", "sun"]}},
+          "weekly_sessions": {"type": "integer"},
+          "weekly_minutes": {"type": "integer"},
+          "monthly_sessions": {"type": "integer"},
+          "monthly_minutes": {"type": "integer"},
+          "freeze_every": {"type": "integer", "description": "Met days in a row that earn a streak freeze, 0 for 7"},
+          "max_freezes": {"type": "integer", "description": "Freezes that can be banked, 0 for 2"},
+          "no_freezes": {"type": "boolean"},
+          "start_date": {"type": "string", "format": "date-time"},
+          "end_date": {"type": "string", "format": "date-time"}
+        }
+      },
+      "DayTarget": {
+        "type": "object",
+        "description": "A day without sessions or minutes needs one completed session",
+        "properties": {"sessions": {"type": "integer"}, "minutes": {"type": "integer"}}
+      },
+      "GoalProgress": {
+        "type": "object",
+        "description": "Recomputed from the session log",
+        "properties": {
+          "current_date": {"type": "string", "format": "date-time"},
+          "sessions_today": {"type": "integer"},
+          "minutes_today": {"type": "integer"},
+          "target_today": {"$ref": "#/components/schemas/DayTarget"},
+          "rest_today": {"type": "boolean"},
+          "current_streak": {"type": "integer"},
+          "longest_streak": {"type": "integer"},
+          "freezes": {"type": "integer"},
+          "freezes_used": {"type": "integer"},
+          "week": {"$ref": "#/components/schemas/PeriodProgress"},
+          "month": {"$ref": "#/components/schemas/PeriodProgress"},
+          "last_update_date": {"type": "string", "format": "date-time"}
+        }
+      },
+      "PeriodProgress": {
+        "type": "object",
+        "properties": {
+          "start": {"type": "string", "format": "date-time"},
+          "end": {"type": "string", "format": "date-time"},
+          "sessions": {"type": "integer"},
+          "minutes": {"type": "integer"},
+          "target_sessions": {"type": "integer"},
+          "target_minutes": {"type": "integer"},
+          "met": {"type": "boolean"}
+        }
+      },
+      "NamedGoal": {
+        "type": "object",
+        "description": "A target for matching sessions only; scopes given together must all match",
+        "required": ["name", "period"],
+        "properties": {
+          "name": {"type": "string"},
+          "period": {"type": "string", "enum": ["day", "week", "month"]},
+          "sessions": {"type": "integer"},
+          "minutes": {"type": "integer"},
+          "tag": {"type": "string"},
+          "task_id": {"type": "string"},
+          "profile": {"type": "string"},
+          "created_at": {"type": "string", "format": "date-time"}
+        }
+      },
+      "NamedGoalProgress": {
+        "type": "object",
+        "properties": {
+          "goal": {"$ref": "#/components/schemas/NamedGoal"},
+          "start": {"type": "string", "format": "date-time"},
+          "end": {"type": "string", "format": "date-time"},
+          "sessions": {"type": "integer"},
+          "minutes": {"type": "integer"},
+          "percent": {"type": "number", "description": "Of the furthest-off target, capped at 100"},
+          "met": {"type": "boolean"}
+        }
+      },
+      "Achievement": {
+        "type": "object",
+        "description": "Unlocks once the metric reaches the threshold",
+        "required": ["id", "name", "metric", "threshold"],
+        "properties": {
+          "id": {"type": "string"},
+          "name": {"type": "string"},
+          "description": {"type": "string"},
+          "icon": {"type": "string"},
+          "metric": {"type": "string", "enum": ["sessions", "hours", "day_sessions", "week_hours", "active_days", "streak", "tasks_completed", "task_sessions", "task_estimate"]},
+          "threshold": {"type": "number"},
+          "tag": {"type": "string", "description": "Only sessions or tasks with this tag"},
+          "profile": {"type": "string", "description": "Only sessions run with this profile"}
+        }
+      },
+      "AchievementStatus": {
+        "allOf": [
+          {"$ref": "#/components/schemas/Achievement"},
+          {
+            "type": "object",
+            "properties": {
+              "custom": {"type": "boolean"},
+              "value": {"type": "number", "description": "Current value of the metric"},
+              "progress": {"type": "number", "description": "Value over threshold, capped at 1"},
+              "unlocked": {"type": "boolean"},
+              "unlocked_at": {"type": "string", "format": "date-time"}
+            }
+          }
+        ]
+      },
+      "GoalDay": {
+        "type": "object",
+        "properties": {
+          "date": {"type": "string", "format": "date-time"},
+          "sessions": {"type": "integer"},
+          "minutes": {"type": "integer"},
+          "target": {"$ref": "#/components/schemas/DayTarget"},
+          "status": {"type": "string", "enum": ["met", "missed", "rest", "frozen", "pending"]},
+          "streak": {"type": "integer"},
+          "freezes": {"type": "integer"}
+        }
+      },
+      "Profile": {
+        "type": "object",
+        "required": ["name"],
+        "properties": {
+          "name": {"type": "string"},
+          "work_minutes": {"type": "integer"},
+          "break_minutes": {"type": "integer"},
+          "num_sessions": {"type": "integer"},
+          "description": {"type": "string"},
+          "long_break_minutes": {"type": "integer", "description": "Long break length, 0 for none"},
+          "long_break_every": {"type": "integer", "description": "Take the long break after every this many sessions"},
+          "intervals": {"type": "array", "items": {"$ref": "#/components/schemas/Interval"}, "description": "Explicit sequence; replaces work, break and sessions when set"},
+          "adaptive": {"$ref": "#/components/schemas/Adaptive"}
+        }
+      },
+      "InsightBucket": {
+        "type": "object",
+        "properties": {
+          "key": {"type": "string", "description": "Weekday (Mon-Sun), hour (0-23), week start, profile name, task ID or tag"},
+          "label": {"type": "string"},
+          "sessions": {"type": "integer", "description": "Focus sessions"},
+          "completed": {"type": "integer"},
+          "interrupted": {"type": "integer", "description": "Stopped, skipped or abandoned"},
+          "completion_rate": {"type": "number"},
+          "minutes": {"type": "number", "description": "Focus time actually spent"},
+          "pause_minutes": {"type": "number"}
+        }
+      },
+      "Insights": {
+        "type": "object",
+        "properties": {
+          "since": {"type": "string", "format": "date-time"},
+          "until": {"type": "string", "format": "date-time"},
+          "days": {"type": "integer"},
+          "focus": {"$ref": "#/components/schemas/InsightBucket"},
+          "weekdays": {"type": "array", "items": {"$ref": "#/components/schemas/InsightBucket"}, "description": "Monday first"},
+          "hours": {"type": "array", "items": {"$ref": "#/components/schemas/InsightBucket"}, "description": "24 entries, hour 0 first"},
+          "weeks": {"type": "array", "description": "Rolling weeks ending today, oldest first", "items": {"allOf": [
+            {"$ref": "#/components/schemas/InsightBucket"},
+            {"type": "object", "properties": {"start": {"type": "string", "format": "date-time"}, "end": {"type": "string", "format": "date-time"}}}
+          ]}},
+          "trend": {
+            "type": "object",
+            "description": "Least-squares fit over the weeks; up or down when focus time changes by more than a tenth of the average week per week",
+            "properties": {
+              "direction": {"type": "string", "enum": ["up", "down", "flat"]},
+              "minutes_slope": {"type": "number"},
+              "completion_slope": {"type": "number"},
+              "summary": {"type": "string"}
+            }
+          },
+          "profiles": {"type": "array", "items": {"$ref": "#/components/schemas/InsightBucket"}},
+          "tasks": {"type": "array", "items": {"$ref": "#/components/schemas/InsightBucket"}},
+          "tags": {"type": "array", "items": {"$ref": "#/components/schemas/InsightBucket"}},
+          "interruptions": {
+            "type": "object",
+            "properties": {
+              "interrupted": {"type": "integer"},
+              "skipped": {"type": "integer"},
+              "abandoned": {"type": "integer"},
+              "avg_minutes_in": {"type": "number"},
+              "avg_share_done": {"type": "number"},
+              "paused_sessions": {"type": "integer"},
+              "pause_minutes": {"type": "number"},
+              "avg_pause_minutes": {"type": "number"},
+              "worst_hour": {"type": "integer", "description": "-1 if nothing was cut short"},
+              "worst_weekday": {"type": "string"}
+            }
+          },
+          "estimates": {
+            "type": "object",
+            "description": "Estimated against actual sessions of tasks completed in the period",
+            "properties": {
+              "tasks": {"type": "integer"},
+              "estimated": {"type": "integer"},
+              "actual": {"type": "integer"},
+              "ratio": {"type": "number", "description": "Actual over estimated sessions"},
+              "accuracy": {"type": "number", "description": "1 minus the mean error relative to each estimate, at least 0"},
+              "over": {"type": "integer"},
+              "under": {"type": "integer"},
+              "on_target": {"type": "integer"},
+              "summary": {"type": "string"}
+            }
+          },
+          "score": {
+            "type": "object",
+            "description": "score = 100 × (0.4 × completion + 0.2 × consistency + 0.2 × depth + 0.2 × calm). Completion is the share of focus sessions run to the end, consistency the share of days with a completed session, depth the average focus length over 50 minutes (at most 1), calm 1 minus the share of focus time spent paused.",
+            "properties": {
+              "score": {"type": "number"},
+              "completion": {"type": "number"},
+              "consistency": {"type": "number"},
+              "depth": {"type": "number"},
+              "calm": {"type": "number"}
+            }
+          },
+          "best_hour": {"type": "integer", "description": "Hour with the best completion rate over 3 or more sessions, -1 if none"},
+          "best_weekday": {"type": "string"}
+        }
+      },
+      "Adaptive": {
+        "type": "object",
+        "description": "Makes work and break lengths follow recent sessions. Unset bounds use the defaults shown.",
+        "properties": {
+          "min_work": {"type": "integer", "default": 15},
+          "max_work": {"type": "integer", "default": 60},
+          "min_break": {"type": "integer", "default": 3},
+          "max_break": {"type": "integer", "default": 20},
+          "window_days": {"type": "integer", "default": 14, "description": "Days of history considered"}
+        }
+      },
+      "AdaptiveDecision": {
+        "type": "object",
+        "properties": {
+          "time": {"type": "string", "format": "date-time"},
+          "profile": {"type": "string"},
+          "prev_work": {"type": "integer"},
+          "prev_break": {"type": "integer"},
+          "base_work": {"type": "integer", "description": "Work length saved in the profile for later runs"},
+          "base_break": {"type": "integer", "description": "Break length saved in the profile for later runs"},
+          "work_minutes": {"type": "integer"},
+          "break_minutes": {"type": "integer"},
+          "confidence": {"type": "number", "description": "Highest confidence of the applied suggestions, 0 if none"},
+          "signals": {
+            "type": "object",
+            "properties": {
+              "samples": {"type": "integer"},
+              "completion_rate": {"type": "number"},
+              "avg_pause_minutes": {"type": "number"},
+              "hour_samples": {"type": "integer"},
+              "hour_completion": {"type": "number"}
+            }
+          },
+          "reasons": {"type": "array", "items": {"type": "string"}}
+        }
+      },
+      "AdaptiveExplanation": {
+        "type": "object",
+        "properties": {
+          "profile": {"type": "string"},
+          "bounds": {"$ref": "#/components/schemas/Adaptive"},
+          "next": {"$ref": "#/components/schemas/AdaptiveDecision"},
+          "log": {"type": "array", "items": {"$ref": "#/components/schemas/AdaptiveDecision"}}
+        }
+      },
+      "WebTheme": {
+        "type": "object",
+        "properties": {
+          "name": {"type": "string"},
+          "accent": {"type": "string", "description": "CSS color"},
+          "secondary": {"type": "string", "description": "CSS color"}
+        }
+      },
+      "TaskInput": {
+        "type": "object",
+        "required": ["title"],
+        "properties": {
+          "title": {"type": "string"},
+          "description": {"type": "string"},
+          "tags": {"type": "array", "items": {"type": "string"}},
+          "estimate": {"type": "integer", "description": "Estimated pomodoros"},
+          "priority": {"type": "string", "enum": ["high", "medium", "low"]},
+          "due": {"type": "string", "description": "YYYY-MM-DD, today, tomorrow or a weekday"},
+          "project": {"type": "string"}
+        }
+      },
+      "Task": {
+        "type": "object",
+        "properties": {
+          "id": {"type": "string"},
+          "title": {"type": "string"},
+          "description": {"type": "string"},
+          "created_at": {"type": "string", "format": "date-time"},
+          "completed_at": {"type": "string", "format": "date-time"},
+          "sessions": {"type": "integer"},
+          "minutes": {"type": "integer"},
+          "tags": {"type": "array", "items": {"type": "string"}},
+          "is_completed": {"type": "boolean"},
+          "estimate": {"type": "integer", "description": "Estimated pomodoros, 0 if not estimated"},
+          "priority": {"type": "string", "enum": ["high", "medium", "low"]},
+          "due": {"type": "string", "format": "date-time", "description": "Start of the day the task is due, zero if none"},
+          "project": {"type": "string"},
+          "number": {"type": "integer", "description": "Short number to refer to the task by"},
+          "archived": {"type": "boolean"}
+        }
+      },
+      "Stats": {
+        "type": "object",
+        "properties": {
+          "today_sessions": {"type": "integer"},
+          "today_minutes": {"type": "integer"},
+          "total_sessions": {"type": "integer"},
+          "total_minutes": {"type": "number"},
+          "avg_sessions_per_day": {"type": "number"},
+          "current_streak": {"type": "integer"},
+          "longest_streak": {"type": "integer"},
+          "daily_session_target": {"type": "integer"},
+          "daily_minutes_target": {"type": "integer"},
+          "rest_today": {"type": "boolean"},
+          "freezes": {"type": "integer"},
+          "week": {"$ref": "#/components/schemas/PeriodProgress"},
+          "month": {"$ref": "#/components/schemas/PeriodProgress"},
+          "goals": {"type": "array", "items": {"$ref": "#/components/schemas/NamedGoalProgress"}},
+          "intervals": {"type": "array", "items": {"$ref": "#/components/schemas/IntervalStat"}}
+        }
+      },
+      "IntervalStat": {
+        "type": "object",
+        "description": "Logged intervals that share a name",
+        "properties": {
+          "name": {"type": "string"},
+          "kind": {"type": "string", "enum": ["focus", "break"]},
+          "count": {"type": "integer"},
+          "completed": {"type": "integer"},
+          "minutes": {"type": "number"}
+        }
+      },
+      "CalendarDay": {
+        "type": "object",
+        "properties": {
+          "date": {"type": "string", "format": "date-time"},
+          "sessions": {"type": "integer"},
+          "minutes": {"type": "integer"},
+          "level": {"type": "integer", "minimum": 0, "maximum": 4}
+        }
+      },
+      "DaySettings": {
+        "type": "object",
+        "properties": {
+          "timezone": {"type": "string"},
+          "day_start": {"type": "string"},
+          "today": {"type": "string", "format": "date"},
+          "start_at": {"type": "string", "format": "date-time"},
+          "end_at": {"type": "string", "format": "date-time"}
+        }
+      },
+      "ReportTotals": {
+        "type": "object",
+        "properties": {
+          "sessions": {"type": "integer", "description": "Completed focus intervals"},
+          "started": {"type": "integer"},
+          "minutes": {"type": "integer"},
+          "hours": {"type": "number"},
+          "active_days": {"type": "integer"},
+          "completion_rate": {"type": "number"}
+        }
+      },
+      "ReportDay": {
+        "type": "object",
+        "properties": {
+          "date": {"type": "string", "format": "date-time"},
+          "sessions": {"type": "integer"},
+          "minutes": {"type": "integer"},
+          "goal_met": {"type": "boolean"}
+        }
+      },
+      "Report": {
+        "type": "object",
+        "properties": {
+          "period": {"type": "string", "enum": ["week", "month"]},
+          "title": {"type": "string"},
+          "start": {"type": "string", "format": "date-time"},
+          "end": {"type": "string", "format": "date-time", "description": "Exclusive"},
+          "totals": {"$ref": "#/components/schemas/ReportTotals"},
+          "previous": {"$ref": "#/components/schemas/ReportTotals"},
+          "change": {"type": "object", "properties": {
+            "sessions": {"type": "integer"}, "minutes": {"type": "integer"},
+            "minutes_percent": {"type": "number"}, "completion_rate": {"type": "number", "description": "Percentage points"}
+          }},
+          "goal": {"type": "object", "properties": {
+            "set": {"type": "boolean"}, "daily_sessions": {"type": "integer"}, "daily_minutes": {"type": "integer"},
+            "target_minutes": {"type": "integer"}, "target_sessions": {"type": "integer"},
+            "days_met": {"type": "integer"}, "days": {"type": "integer"}
+          }},
+          "days": {"type": "array", "items": {"$ref": "#/components/schemas/ReportDay"}},
+          "best_day": {"$ref": "#/components/schemas/ReportDay"},
+          "worst_day": {"$ref": "#/components/schemas/ReportDay"},
+          "tasks": {"type": "array", "items": {"type": "object", "properties": {
+            "id": {"type": "string"}, "title": {"type": "string"}, "tags": {"type": "array", "items": {"type": "string"}},
+            "sessions": {"type": "integer"}, "minutes": {"type": "integer"}, "completed": {"type": "boolean"}
+          }}},
+          "streak": {"type": "object", "properties": {
+            "start": {"type": "integer"}, "end": {"type": "integer"}, "longest": {"type": "integer"}
+          }},
+          "suggestions": {"type": "array", "items": {"type": "string"}}
+        }
+      },
+      "Coaching": {
+        "type": "object",
+        "properties": {
+          "provider": {"type": "string", "enum": ["rules", "openai"]},
+          "narrative": {"type": "string"},
+          "fallback": {"type": "boolean", "description": "The endpoint failed and the rules wrote the note"},
+          "error": {"type": "string", "description": "Why the endpoint failed"},
+          "summary": {"type": "object", "description": "The anonymised summary the provider was given: counts, rates and times of day only"}
+        }
+      },
+      "Suggestion": {
+        "type": "object",
+        "properties": {
+          "type": {"type": "string"},
+          "message": {"type": "string"},
+          "work_time": {"type": "integer"},
+          "break_time": {"type": "integer"},
+          "confidence": {"type": "number"}
+        }
+      },
+      "Plugin": {
+        "type": "object",
+        "required": ["name", "script", "triggers"],
+        "properties": {
+          "name": {"type": "string"},
+          "description": {"type": "string"},
+          "script": {"type": "string"},
+          "triggers": {"type": "array", "items": {"type": "string", "enum": ["session_start", "session_end", "break_start", "break_end"]}},
+          "enabled": {"type": "boolean"},
+          "args": {"type": "array", "items": {"type": "string"}}
+        }
+      },
+      "Privacy": {
+        "type": "object",
+        "properties": {"privacy_mode": {"type": "boolean"}, "cloud_sync": {"type": "boolean"}}
+      },
+      "SyncStatus": {
+        "type": "object",
+        "properties": {"enabled": {"type": "boolean"}, "provider": {"type": "string"}, "available": {"type": "boolean"}}
+      },
+      "SessionRecord": {
+        "type": "object",
+        "properties": {
+          "run_id": {"type": "string"},
+          "kind": {"type": "string", "enum": ["focus", "break"]},
+          "name": {"type": "string", "description": "Interval name, e.g. Long break"},
+          "index": {"type": "integer"},
+          "start_time": {"type": "string", "format": "date-time"},
+          "end_time": {"type": "string", "format": "date-time"},
+          "planned_seconds": {"type": "integer"},
+          "actual_seconds": {"type": "integer"},
+          "paused_seconds": {"type": "integer"},
+          "status": {"type": "string", "enum": ["completed", "interrupted", "abandoned", "skipped"]},
+          "profile": {"type": "string"},
+          "task_id": {"type": "string"}
+        }
+      },
+      "ExportData": {
+        "type": "object",
+        "properties": {
+          "sessions": {"type": "array", "items": {"$ref": "#/components/schemas/SessionRecord"}},
+          "tasks": {"type": "array", "items": {"$ref": "#/components/schemas/Task"}},
+          "goal": {"$ref": "#/components/schemas/Goal"},
+          "progress": {"$ref": "#/components/schemas/GoalProgress"},
+          "config": {"type": "object", "description": "Settings as stored in the datastore"},
+          "profiles": {"type": "array", "items": {"$ref": "#/components/schemas/Profile"}}
+        }
+      }
+    }
+  }
+}
+`
@@ -0,0 +1,170 @@
+package web
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Flack74/pom/store"
+)
+
+// Instance describes a running web server, as recorded in its pidfile
+type Instance struct {
+	PID     int
+	URL     string
+	Started time.Time
+}
+
+// PIDPath returns the pidfile of the web server. The first line is the pid,
+// the second the URL it serves.
+func PIDPath() (string, error) {
+	dir, err := store.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "web.pid"), nil
+}
+
+// LogPath returns the log file of a web server started with `pom web -d`
+func LogPath() (string, error) {
+	dir, err := store.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "web.log"), nil
+}
+
+// writePIDFile records this process as the running web server
+func writePIDFile(url string) error {
+	path, err := PIDPath()
+	if err != nil {
+		return err
+	}
+	data := fmt.Sprintf("%d\n%s\n", os.Getpid(), url)
+	return store.WriteFileAtomic(path, []byte(data), 0644)
+}
+
+// removePIDFile deletes the pidfile if it still belongs to this process
+func removePIDFile() {
+	if inst, err := readPIDFile(); err == nil && inst.PID == os.Getpid() {
+		path, _ := PIDPath()
+		os.Remove(path)
+	}
+}
+
+// readPIDFile parses the pidfile
+func readPIDFile() (Instance, error) {
+	path, err := PIDPath()
+	if err != nil {
+		return Instance{}, err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return Instance{}, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Instance{}, err
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	pid, err := strconv.Atoi(strings.TrimSpace(lines[0]))
+	if err != nil {
+		return Instance{}, fmt.Errorf("invalid pidfile %s: %v", path, err)
+	}
+
+	inst := Instance{PID: pid, Started: info.ModTime()}
+	if len(lines) > 1 {
+		inst.URL = strings.TrimSpace(lines[1])
+	}
+	return inst, nil
+}
+
+// Running returns the web server recorded in the pidfile if its process is
+// still alive. A stale pidfile is removed.
+func Running() (Instance, bool) {
+	inst, err := readPIDFile()
+	if err != nil {
+		return Instance{}, false
+	}
+	if !processAlive(inst.PID) {
+		path, _ := PIDPath()
+		os.Remove(path)
+		return Instance{}, false
+	}
+	return inst, true
+}
+
+// Stop asks the running web server to shut down and waits up to timeout for
+// it to exit
+func Stop(timeout time.Duration) (Instance, error) {
+	inst, ok := Running()
+	if !ok {
+		return Instance{}, fmt.Errorf("pom web is not running")
+	}
+
+	if !isPomWeb(inst) {
+		return inst, fmt.Errorf("pid %d in the pidfile does not answer as pom web at %s; not stopping it", inst.PID, inst.URL)
+	}
+
+	proc, err := os.FindProcess(inst.PID)
+	if err != nil {
+		return inst, err
+	}
+	if err := terminate(proc); err != nil {
+		return inst, fmt.Errorf("failed to stop pom web (pid %d): %v", inst.PID, err)
+	}
+
+	deadline := time.Now().Add(timeout)
+	for processAlive(inst.PID) {
+		if time.Now().After(deadline) {
+			return inst, fmt.Errorf("pom web (pid %d) did not stop within %s", inst.PID, timeout)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	// Remove the pidfile if the process could not do it itself
+	if current, err := readPIDFile(); err == nil && current.PID == inst.PID {
+		path, _ := PIDPath()
+		os.Remove(path)
+	}
+	return inst, nil
+}
+
+// pidHeader is the response header in which the web server names its pid
+const pidHeader = "X-Pom-Web-Pid"
+
+// withPID adds pidHeader to every response
+func withPID(next http.Handler) http.Handler {
+	pid := strconv.Itoa(os.Getpid())
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(pidHeader, pid)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isPomWeb reports whether the pidfile's process is the web server it
+// records: it answers at the recorded URL with its pid or, if it cannot be
+// reached, runs this executable. After a crash the pid may have been given
+// to an unrelated process, which must not be signalled.
+func isPomWeb(inst Instance) bool {
+	if inst.URL != "" {
+		// Only the pid is read back and no token is sent, so the
+		// self-signed certificate need not be verified
+		client := &http.Client{
+			Timeout:   2 * time.Second,
+			Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}},
+		}
+		if resp, err := client.Get(inst.URL + "/login"); err == nil {
+			resp.Body.Close()
+			return resp.Header.Get(pidHeader) == strconv.Itoa(inst.PID)
+		}
+	}
+	return runsThisExecutable(inst.PID)
+}
@@ -0,0 +1,41 @@
+//go:build !windows
+
+package web
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"syscall"
+)
+
+// processAlive reports whether a process with pid exists
+func processAlive(pid int) bool {
+	proc, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	err = proc.Signal(syscall.Signal(0))
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// runsThisExecutable reports whether the process with pid runs the same
+// executable as this one. It needs /proc, so it reports false elsewhere.
+func runsThisExecutable(pid int) bool {
+	self, err := os.Executable()
+	if err != nil {
+		return false
+	}
+	exe, err := os.Readlink(fmt.Sprintf("/proc/%d/exe", pid))
+	if err != nil {
+		return false
+	}
+	// A rebuilt binary shows the old one as deleted
+	return strings.TrimSuffix(exe, " (deleted)") == self
+}
+
+// terminate asks the process to shut down gracefully
+func terminate(proc *os.Process) error {
+	return proc.Signal(syscall.SIGTERM)
+}
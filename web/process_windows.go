@@ -0,0 +1,29 @@
+//go:build windows
+
+package web
+
+import "os"
+
+// processAlive reports whether a process with pid exists. FindProcess opens
+// the process on Windows and fails once it has exited.
+func processAlive(pid int) bool {
+	proc, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	proc.Release()
+	return true
+}
+
+// runsThisExecutable reports whether the process with pid runs the same
+// executable as this one. It is not checked on Windows, so the web server must
+// answer at its recorded URL to be stopped.
+func runsThisExecutable(pid int) bool {
+	return false
+}
+
+// terminate ends the process. Windows cannot deliver SIGTERM to a detached
+// process, so it is killed; the timer itself keeps running in the pom daemon.
+func terminate(proc *os.Process) error {
+	return proc.Kill()
+}
@@ -1,19 +1,31 @@
 package web
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"log"
+	"net"
 	"net/http"
 	"os"
+	"strconv"
+	"sync"
 
 	"github.com/Flack74/pom/config"
+	"github.com/Flack74/pom/daemon"
+	"github.com/Flack74/pom/timer"
 	"github.com/gorilla/mux"
 	"github.com/gorilla/websocket"
 )
 
 type Server struct {
 	upgrader websocket.Upgrader
-	clients  map[*websocket.Conn]bool
+	timer    *timerHub
+	auth     *auth
+	http     *http.Server
+	redirect *http.Server
+	stopOnce sync.Once
+	stopped  chan struct{} // Closed once Shutdown has finished
 }
 
 type TimerSession struct {
@@ -26,38 +38,171 @@ type TimerSession struct {
 	IsPaused    bool   `json:"is_paused"`
 	IsBreak     bool   `json:"is_break"`
 	TimeLeft    int    `json:"time_left"`
+	Duration    int    `json:"duration"` // Planned seconds of the current phase
 	Profile     string `json:"profile"`
+	TaskID      string `json:"task_id,omitempty"`
+
+	Open      bool             `json:"open,omitempty"`      // The current interval counts up until it is ended
+	Elapsed   int              `json:"elapsed"`             // Seconds spent in the current phase
+	Interval  string           `json:"interval,omitempty"`  // Name of the current interval
+	Step      int              `json:"step"`                // Index of the current interval in Intervals
+	Intervals []timer.Interval `json:"intervals,omitempty"` // The run's interval sequence
 }
 
-func NewServer() *Server {
-	return &Server{
-		upgrader: websocket.Upgrader{CheckOrigin: func(r *http.Request) bool { return true }},
-		clients:  make(map[*websocket.Conn]bool),
+func NewServer() (*Server, error) {
+	token, err := LoadToken()
+	if err != nil {
+		return nil, err
 	}
+
+	return &Server{
+		upgrader: websocket.Upgrader{CheckOrigin: checkOrigin},
+		timer:    newTimerHub(),
+		auth:     newAuth(token),
+		http:     &http.Server{},
+		redirect: &http.Server{},
+		stopped:  make(chan struct{}),
+	}, nil
+}
+
+// Options configure how the web server listens
+type Options struct {
+	Bind         string // Address to listen on
+	Port         int
+	TLS          bool   // Serve HTTPS, with a self-signed certificate unless CertFile is set
+	CertFile     string // PEM certificate; implies TLS
+	KeyFile      string // PEM private key for CertFile
+	RedirectPort int    // If non-zero, redirect plain HTTP on this port to HTTPS
 }
 
-func (s *Server) Start(port int) error {
+// Start serves the web UI until Shutdown is called or the listener fails. It
+// records the server in the pidfile while it runs.
+func (s *Server) Start(opts Options) error {
+	if (opts.CertFile == "") != (opts.KeyFile == "") {
+		return fmt.Errorf("--cert and --key must be used together")
+	}
+	useTLS := opts.TLS || opts.CertFile != ""
+	if opts.RedirectPort != 0 && !useTLS {
+		return fmt.Errorf("redirecting HTTP requires TLS")
+	}
+
+	certFile, keyFile := opts.CertFile, opts.KeyFile
+	if useTLS && certFile == "" {
+		var err error
+		if certFile, keyFile, err = SelfSignedCert(opts.Bind); err != nil {
+			return err
+		}
+	}
+
 	r := mux.NewRouter()
+	r.Use(withPID)
+
+	// Sign in with the access token
+	r.HandleFunc("/login", s.auth.handleLogin).Methods("GET", "POST")
+	r.HandleFunc("/logout", s.auth.handleLogout).Methods("GET", "POST")
+
+	// Everything else requires a token or a session cookie
+	protected := r.PathPrefix("/").Subrouter()
+	protected.Use(s.auth.middleware)
 
 	// API routes
-	api := r.PathPrefix("/api").Subrouter()
+	api := protected.PathPrefix("/api").Subrouter()
 	api.HandleFunc("/profiles", s.handleProfiles).Methods("GET")
+	api.HandleFunc("/session", s.handleSession).Methods("GET")
 	api.HandleFunc("/session/start", s.handleStartSession).Methods("POST")
 	api.HandleFunc("/insights/suggestions", s.handleSuggestions).Methods("GET")
 	api.HandleFunc("/insights/today", s.handleTodayStats).Methods("GET")
 	api.HandleFunc("/plugins", s.handlePlugins).Methods("GET")
 	api.HandleFunc("/privacy/status", s.handlePrivacyStatus).Methods("GET")
-	api.HandleFunc("/command/{cmd}", s.handleCommand).Methods("POST")
+	s.registerAPI(api)
+
+	// Live timer updates and controls
+	protected.HandleFunc("/ws", s.handleWebSocket)
 
 	// Serve embedded HTML/JS web UI
-	r.PathPrefix("/").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	protected.PathPrefix("/").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "text/html")
 		w.Write([]byte(getWebUI()))
 	})
 
-	addr := fmt.Sprintf(":%d", port)
-	fmt.Printf("🌐 Web UI: http://localhost%s\n", addr)
-	return http.ListenAndServe(addr, r)
+	addr := net.JoinHostPort(opts.Bind, strconv.Itoa(opts.Port))
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	var redirect net.Listener
+	if opts.RedirectPort != 0 {
+		redirect, err = net.Listen("tcp", net.JoinHostPort(opts.Bind, strconv.Itoa(opts.RedirectPort)))
+		if err != nil {
+			listener.Close()
+			return err
+		}
+	}
+
+	host := opts.Bind
+	if host == "" || net.ParseIP(host).IsUnspecified() {
+		host = "localhost"
+	}
+	scheme := "http"
+	if useTLS {
+		scheme = "https"
+	}
+	base := scheme + "://" + net.JoinHostPort(host, strconv.Itoa(opts.Port))
+
+	fmt.Printf("🌐 Web UI: %s\n", base)
+	fmt.Printf("🔑 Sign in: %s/login?token=%s\n", base, s.auth.token)
+	if useTLS {
+		fmt.Printf("🔒 Certificate: %s\n", certFile)
+		if opts.CertFile == "" {
+			if fingerprint, err := Fingerprint(certFile); err == nil {
+				fmt.Printf("   Self-signed, SHA-256 fingerprint %s\n", fingerprint)
+			}
+		}
+	}
+	if redirect != nil {
+		fmt.Printf("↪️  Redirecting http://%s to HTTPS\n", net.JoinHostPort(host, strconv.Itoa(opts.RedirectPort)))
+		s.redirect.Handler = redirectHandler(opts.Port)
+		go func() {
+			if err := s.redirect.Serve(redirect); err != nil && err != http.ErrServerClosed {
+				log.Printf("redirect server: %v", err)
+			}
+		}()
+	}
+	if !isLoopback(opts.Bind) {
+		fmt.Printf("⚠️  Listening on %s: anyone on your network can reach the login page\n", addr)
+	}
+
+	if err := writePIDFile(base); err != nil {
+		log.Printf("failed to write pidfile: %v", err)
+	}
+	defer removePIDFile()
+
+	s.http.Handler = r
+	if useTLS {
+		err = s.http.ServeTLS(listener, certFile, keyFile)
+	} else {
+		err = s.http.Serve(listener)
+	}
+	if err == http.ErrServerClosed {
+		<-s.stopped
+		return nil
+	}
+	return err
+}
+
+// Shutdown stops accepting connections, waits for requests in flight until
+// ctx expires, then sends every WebSocket client the final timer state and
+// closes it. The timer itself keeps running in the pom daemon.
+func (s *Server) Shutdown(ctx context.Context) error {
+	var err error
+	s.stopOnce.Do(func() {
+		defer close(s.stopped)
+
+		s.redirect.Shutdown(ctx)
+		err = s.http.Shutdown(ctx)
+		s.timer.close(ctx, "pom web is shutting down")
+	})
+	return err
 }
 
 func fileExists(filename string) bool {
@@ -76,12 +221,40 @@ func (s *Server) handleProfiles(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+func (s *Server) handleSession(w http.ResponseWriter, r *http.Request) {
+	session, err := runTimerCommand(TimerCommand{Type: daemon.MethodStatus})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(session)
+}
+
 func (s *Server) handleStartSession(w http.ResponseWriter, r *http.Request) {
 	var req TimerSession
-	json.NewDecoder(r.Body).Decode(&req)
-	
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("invalid session: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	session, err := runTimerCommand(TimerCommand{
+		Type:      daemon.MethodStart,
+		WorkTime:  req.WorkTime,
+		BreakTime: req.BreakTime,
+		Sessions:  req.Sessions,
+		Profile:   req.Profile,
+		TaskID:    req.TaskID,
+		Intervals: req.Intervals,
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{"status": "started"})
+	json.NewEncoder(w).Encode(session)
 }
 
 func (s *Server) handleSuggestions(w http.ResponseWriter, r *http.Request) {
@@ -118,30 +291,3 @@ func (s *Server) handlePrivacyStatus(w http.ResponseWriter, r *http.Request) {
 		"cloud_sync":   cfg.CloudSync,
 	})
 }
-
-func (s *Server) handleCommand(w http.ResponseWriter, r *http.Request) {
-	cmd := mux.Vars(r)["cmd"]
-	
-	w.Header().Set("Content-Type", "text/plain")
-	
-	switch cmd {
-	case "goals":
-		w.Write([]byte("🎯 Daily Goals:\n\nToday's Target: 4 sessions\nCompleted: 0 sessions\nStreak: 0 days\n\nUse CLI: pom goals set 6"))
-	case "profile":
-		w.Write([]byte("👥 Available Profiles:\n\n• default (25/5) - Standard Pomodoro\n• work (45/10) - Deep work sessions\n• study (30/5) - Learning sessions\n• quick (15/3) - Quick tasks\n\nUse CLI: pom profile use work"))
-	case "stats":
-		w.Write([]byte("📊 Session Statistics:\n\nToday: 0 sessions, 0 minutes\nThis Week: 0 sessions\nTotal: 0 sessions\n\nUse CLI: pom stats --detailed"))
-	case "insights":
-		w.Write([]byte("🧠 AI Insights:\n\n• Best focus time: Not enough data\n• Optimal session length: 25 minutes\n• Productivity trend: Stable\n\nUse CLI: pom insights suggest"))
-	case "export":
-		w.Write([]byte("📤 Export Options:\n\n• JSON format: Complete backup\n• CSV format: Spreadsheet compatible\n\nUse CLI: pom export json backup.json"))
-	case "sync":
-		w.Write([]byte("🔄 Cloud Sync:\n\n• GitHub: Not configured\n• Dropbox: Not configured\n\nUse CLI: pom sync setup github"))
-	case "plugins":
-		w.Write([]byte("🧩 Available Plugins:\n\n• Notion Logger: Disabled\n• Slack Notify: Disabled\n• Break Reminder: Enabled\n\nUse CLI: pom plugins enable notion-logger"))
-	case "privacy":
-		w.Write([]byte("🔐 Privacy Settings:\n\n• Privacy Mode: Disabled\n• Data Logging: Enabled\n• Cloud Sync: Optional\n\nUse CLI: pom privacy enable"))
-	default:
-		w.Write([]byte("❌ Unknown command: " + cmd))
-	}
-}
\ No newline at end of file
@@ -0,0 +1,313 @@
+package web
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Flack74/pom/config"
+	"github.com/Flack74/pom/daemon"
+	"github.com/Flack74/pom/history"
+	"github.com/Flack74/pom/timer"
+	"github.com/gorilla/websocket"
+)
+
+// reconnectDelay is how long the hub waits before reconnecting to the daemon
+const reconnectDelay = 2 * time.Second
+
+// TimerMessage is sent to WebSocket clients whenever the timer changes
+type TimerMessage struct {
+	Type    string       `json:"type"` // A timer.Event* name, "state" for the initial state, "shutdown" or "error"
+	Session TimerSession `json:"session"`
+	Message string       `json:"message,omitempty"`
+}
+
+// TimerCommand is sent by WebSocket clients to control the timer
+type TimerCommand struct {
+	Type      string `json:"type"` // start, flow, pause, resume, skip or stop
+	WorkTime  int    `json:"work_time,omitempty"`
+	BreakTime int    `json:"break_time,omitempty"`
+	Sessions  int    `json:"sessions,omitempty"`
+	Profile   string `json:"profile,omitempty"`
+	TaskID    string `json:"task_id,omitempty"`
+
+	Intervals []timer.Interval `json:"intervals,omitempty"` // Explicit interval sequence for start
+	Ratio     float64          `json:"ratio,omitempty"`     // Focus minutes per break minute for flow
+}
+
+// commandFlow starts an open-ended flow run rather than timed intervals
+const commandFlow = "flow"
+
+// client is a connected WebSocket client
+type client struct {
+	conn *websocket.Conn
+	send chan TimerMessage
+	done chan struct{} // Closed when the writer has closed the connection
+
+	mu     sync.Mutex // Guards closed, so nothing sends on send once it is closed
+	closed bool
+}
+
+// queue sends msg to the client's writer unless the client is closed or its
+// queue is full, and reports whether it was queued
+func (c *client) queue(msg TimerMessage) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return false
+	}
+	select {
+	case c.send <- msg:
+		return true
+	default:
+		return false
+	}
+}
+
+// shutdown closes the send queue, once, so the writer drains it and
+// disconnects
+func (c *client) shutdown() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.closed {
+		c.closed = true
+		close(c.send)
+	}
+}
+
+// timerHub relays the daemon's timer to every WebSocket client
+type timerHub struct {
+	mu      sync.Mutex
+	clients map[*client]bool
+	current TimerSession
+}
+
+// newTimerHub creates a hub and starts following the daemon
+func newTimerHub() *timerHub {
+	h := &timerHub{clients: make(map[*client]bool)}
+	go h.follow()
+	return h
+}
+
+// follow watches the daemon's timer, reconnecting whenever the connection drops
+func (h *timerHub) follow() {
+	for {
+		if err := h.watch(); err != nil {
+			log.Printf("timer: %v", err)
+		}
+		time.Sleep(reconnectDelay)
+	}
+}
+
+// watch relays snapshots from one daemon connection until it ends
+func (h *timerHub) watch() error {
+	conn, err := daemon.Connect()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	updates, err := conn.Watch()
+	if err != nil {
+		return err
+	}
+
+	for snap := range updates {
+		event := snap.Event
+		if event == "" {
+			event = "state"
+		}
+		h.broadcast(TimerMessage{Type: event, Session: sessionFromSnapshot(snap)})
+	}
+
+	return fmt.Errorf("lost connection to pom daemon")
+}
+
+// broadcast records the latest state and queues it for every client
+func (h *timerHub) broadcast(msg TimerMessage) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.current = msg.Session
+	for c := range h.clients {
+		// Drop ticks for clients that are not keeping up
+		c.queue(msg)
+	}
+}
+
+// add registers a client and sends it the current state
+func (h *timerHub) add(c *client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.clients[c] = true
+	c.queue(TimerMessage{Type: "state", Session: h.current})
+}
+
+// remove unregisters a client
+func (h *timerHub) remove(c *client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delete(h.clients, c)
+	c.shutdown()
+}
+
+// close sends every client the last known state with a shutdown message,
+// then disconnects them, waiting until ctx expires for the messages to go out
+func (h *timerHub) close(ctx context.Context, message string) {
+	h.mu.Lock()
+	clients := make([]*client, 0, len(h.clients))
+	for c := range h.clients {
+		c.queue(TimerMessage{Type: "shutdown", Session: h.current, Message: message})
+		delete(h.clients, c)
+		c.shutdown()
+		clients = append(clients, c)
+	}
+	h.mu.Unlock()
+
+	for _, c := range clients {
+		select {
+		case <-c.done:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// sessionFromSnapshot converts a daemon snapshot to the web representation
+func sessionFromSnapshot(snap timer.Snapshot) TimerSession {
+	return TimerSession{
+		ID:          snap.RunID,
+		WorkTime:    snap.WorkMinutes,
+		BreakTime:   snap.BreakMinutes,
+		Sessions:    snap.Sessions,
+		CurrentSess: snap.Session,
+		IsRunning:   snap.Active,
+		IsPaused:    snap.Paused,
+		IsBreak:     snap.Phase == history.KindBreak,
+		TimeLeft:    snap.Remaining,
+		Duration:    snap.Duration,
+		Profile:     snap.Profile,
+		TaskID:      snap.TaskID,
+		Open:        snap.Open,
+		Elapsed:     snap.Elapsed,
+		Interval:    snap.Interval,
+		Step:        snap.Step,
+		Intervals:   snap.Intervals,
+	}
+}
+
+// commandPlan returns the interval sequence for a start command. A profile
+// runs its own sequence unless work time is given, in which case the run
+// alternates the given work and break times with the profile's long break.
+// Adaptive profiles choose their lengths first; the decision is returned to
+// be saved once the run starts.
+func commandPlan(cmd TimerCommand) ([]timer.Interval, *config.AdaptiveDecision) {
+	if len(cmd.Intervals) > 0 {
+		return cmd.Intervals, nil
+	}
+	if cmd.Profile == "" {
+		return nil, nil
+	}
+	profile, err := config.GetProfile(cmd.Profile)
+	if err != nil {
+		return nil, nil
+	}
+	if cmd.WorkTime == 0 {
+		if profile.Adaptive != nil {
+			if adapted, decision, err := config.Adapt(profile); err == nil {
+				return adapted.Plan(), &decision
+			}
+		}
+		return profile.Plan(), nil
+	}
+	return timer.ClassicPlan(cmd.WorkTime, cmd.BreakTime, cmd.Sessions, profile.LongBreakMinutes, profile.LongBreakEvery), nil
+}
+
+// runTimerCommand forwards a timer command to the daemon
+func runTimerCommand(cmd TimerCommand) (TimerSession, error) {
+	conn, err := daemon.Connect()
+	if err != nil {
+		return TimerSession{}, err
+	}
+	defer conn.Close()
+
+	var snap timer.Snapshot
+	switch cmd.Type {
+	case daemon.MethodStart:
+		plan, decision := commandPlan(cmd)
+		snap, err = conn.Call(daemon.MethodStart, daemon.StartParams{
+			WorkMinutes:  cmd.WorkTime,
+			BreakMinutes: cmd.BreakTime,
+			Sessions:     cmd.Sessions,
+			Intervals:    plan,
+			Profile:      cmd.Profile,
+			TaskID:       cmd.TaskID,
+		})
+		if err == nil && decision != nil {
+			if saveErr := config.SaveAdaptive(*decision); saveErr != nil {
+				log.Printf("Error saving adaptive lengths: %v", saveErr)
+			}
+		}
+	case commandFlow:
+		ratio := cmd.Ratio
+		if ratio <= 0 {
+			cfg, _ := config.LoadConfig()
+			ratio = cfg.BreakRatio()
+		}
+		snap, err = conn.Call(daemon.MethodStart, daemon.StartParams{
+			Intervals: timer.FlowPlan(1, ratio),
+			TaskID:    cmd.TaskID,
+		})
+	case daemon.MethodPause, daemon.MethodResume, daemon.MethodSkip, daemon.MethodStop, daemon.MethodStatus:
+		snap, err = conn.Call(cmd.Type, nil)
+	default:
+		return TimerSession{}, fmt.Errorf("unknown timer command %q", cmd.Type)
+	}
+	if err != nil {
+		return TimerSession{}, err
+	}
+
+	return sessionFromSnapshot(snap), nil
+}
+
+// handleWebSocket streams timer state to a browser and applies its commands
+func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+
+	c := &client{conn: conn, send: make(chan TimerMessage, 16), done: make(chan struct{})}
+	s.timer.add(c)
+	defer s.timer.remove(c)
+
+	go func() {
+		defer close(c.done)
+		for msg := range c.send {
+			if err := conn.WriteJSON(msg); err != nil {
+				conn.Close()
+				return
+			}
+		}
+		conn.WriteControl(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseGoingAway, ""), time.Now().Add(time.Second))
+		conn.Close()
+	}()
+
+	for {
+		var cmd TimerCommand
+		if err := conn.ReadJSON(&cmd); err != nil {
+			return
+		}
+		if _, err := runTimerCommand(cmd); err != nil {
+			c.queue(TimerMessage{Type: "error", Message: err.Error()})
+		}
+	}
+}
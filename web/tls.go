@@ -0,0 +1,253 @@
+package web
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Flack74/pom/store"
+)
+
+const (
+	// certValidity is how long a generated certificate is valid
+	certValidity = 2 * 365 * 24 * time.Hour
+
+	// certRenewBefore is how long before expiry a generated certificate is replaced
+	certRenewBefore = 30 * 24 * time.Hour
+)
+
+// SelfSignedPaths returns where the generated certificate and key are kept
+func SelfSignedPaths() (certFile, keyFile string, err error) {
+	dir, err := store.Dir()
+	if err != nil {
+		return "", "", err
+	}
+	return filepath.Join(dir, "web-cert.pem"), filepath.Join(dir, "web-key.pem"), nil
+}
+
+// SelfSignedCert returns the generated certificate and key for serving on
+// bind, creating them on first use and again shortly before they expire.
+// The certificate covers localhost, the machine's host name and the
+// addresses of its network interfaces at the time it is created. It is only
+// reissued for a new address when the one being served is not covered, and
+// then keeps its key.
+func SelfSignedCert(bind string) (certFile, keyFile string, err error) {
+	certFile, keyFile, err = SelfSignedPaths()
+	if err != nil {
+		return "", "", err
+	}
+
+	served := servedHosts(bind)
+	key, keyErr := readKey(keyFile)
+	cert, certErr := readCert(certFile)
+	if keyErr == nil && certErr == nil && time.Until(cert.NotAfter) > certRenewBefore {
+		missing := uncovered(cert, served)
+		if len(missing) == 0 {
+			return certFile, keyFile, nil
+		}
+		fmt.Printf("🔒 Reissuing the certificate to cover %s, keeping its key\n", strings.Join(missing, ", "))
+	}
+
+	if err := generateCert(certFile, keyFile, key, served); err != nil {
+		return "", "", fmt.Errorf("failed to generate certificate: %v", err)
+	}
+	return certFile, keyFile, nil
+}
+
+// servedHosts returns the addresses clients reach the server on when it
+// listens on bind: bind itself, or loopback and the address of the
+// machine's default route if bind is every interface
+func servedHosts(bind string) []string {
+	if bind != "" && !net.ParseIP(bind).IsUnspecified() {
+		return []string{bind}
+	}
+	hosts := []string{"127.0.0.1"}
+	if ip := primaryIP(); ip != nil {
+		hosts = append(hosts, ip.String())
+	}
+	return hosts
+}
+
+// primaryIP returns the local address of the default route, which is what
+// other devices on the network usually connect to, or nil if there is none.
+// Dialing UDP only picks the route; nothing is sent.
+func primaryIP() net.IP {
+	conn, err := net.Dial("udp", "192.0.2.1:9")
+	if err != nil {
+		return nil
+	}
+	defer conn.Close()
+	if addr, ok := conn.LocalAddr().(*net.UDPAddr); ok && !addr.IP.IsLoopback() {
+		return addr.IP
+	}
+	return nil
+}
+
+// uncovered returns the hosts the certificate is not valid for
+func uncovered(cert *x509.Certificate, hosts []string) []string {
+	var missing []string
+	for _, host := range hosts {
+		if cert.VerifyHostname(host) != nil {
+			missing = append(missing, host)
+		}
+	}
+	return missing
+}
+
+// generateCert writes a new self-signed ECDSA certificate for the local
+// addresses and hosts, and its key. An existing key is reused if given.
+func generateCert(certFile, keyFile string, key *ecdsa.PrivateKey, hosts []string) error {
+	if key == nil {
+		var err error
+		if key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader); err != nil {
+			return err
+		}
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	template := x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{Organization: []string{"pom"}, CommonName: "pom web"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(certValidity),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		DNSNames:              []string{"localhost"},
+	}
+	if hostname, err := os.Hostname(); err == nil && hostname != "" {
+		template.DNSNames = append(template.DNSNames, hostname)
+	}
+	template.IPAddresses = localIPs()
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			if !containsIP(template.IPAddresses, ip) {
+				template.IPAddresses = append(template.IPAddresses, ip)
+			}
+		} else if !containsString(template.DNSNames, host) {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		return err
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return err
+	}
+
+	if err := store.WriteFileAtomic(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		return err
+	}
+	return store.WriteFileAtomic(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644)
+}
+
+// containsIP reports whether ips holds ip
+func containsIP(ips []net.IP, ip net.IP) bool {
+	for _, other := range ips {
+		if other.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsString reports whether values holds value, ignoring case
+func containsString(values []string, value string) bool {
+	for _, other := range values {
+		if strings.EqualFold(other, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// readKey parses the EC private key in a PEM file
+func readKey(path string) (*ecdsa.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "EC PRIVATE KEY" {
+		return nil, fmt.Errorf("%s does not contain a PEM EC private key", path)
+	}
+	return x509.ParseECPrivateKey(block.Bytes)
+}
+
+// localIPs returns the loopback addresses and those of every network interface
+func localIPs() []net.IP {
+	ips := []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback}
+
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return ips
+	}
+	for _, addr := range addrs {
+		if ipnet, ok := addr.(*net.IPNet); ok && !ipnet.IP.IsLoopback() && !ipnet.IP.IsLinkLocalUnicast() {
+			ips = append(ips, ipnet.IP)
+		}
+	}
+	return ips
+}
+
+// readCert parses the first certificate in a PEM file
+func readCert(path string) (*x509.Certificate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, fmt.Errorf("%s does not contain a PEM certificate", path)
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+// Fingerprint returns the SHA-256 fingerprint of a certificate file, so users
+// can check a self-signed certificate before trusting it
+func Fingerprint(certFile string) (string, error) {
+	cert, err := readCert(certFile)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(cert.Raw)
+	parts := make([]string, len(sum))
+	for i, b := range sum {
+		parts[i] = fmt.Sprintf("%02X", b)
+	}
+	return strings.Join(parts, ":"), nil
+}
+
+// redirectHandler sends plain HTTP requests to the HTTPS server on port
+func redirectHandler(port int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(r.Host); err == nil {
+			host = h
+		}
+		target := "https://" + net.JoinHostPort(host, strconv.Itoa(port)) + r.URL.RequestURI()
+		http.Redirect(w, r, target, http.StatusMovedPermanently)
+	})
+}
@@ -0,0 +1,52 @@
+package web
+
+import (
+	"os"
+	"testing"
+)
+
+func TestSelfSignedCertReissue(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	certFile, keyFile, err := SelfSignedCert("127.0.0.1")
+	if err != nil {
+		t.Fatalf("SelfSignedCert failed: %v", err)
+	}
+	first, _ := Fingerprint(certFile)
+	key, _ := os.ReadFile(keyFile)
+
+	// The same address keeps the certificate
+	if _, _, err := SelfSignedCert("127.0.0.1"); err != nil {
+		t.Fatalf("SelfSignedCert failed: %v", err)
+	}
+	if again, _ := Fingerprint(certFile); again != first {
+		t.Errorf("certificate changed for an address it already covers")
+	}
+
+	// A new served address reissues the certificate with the same key
+	if _, _, err := SelfSignedCert("10.200.0.1"); err != nil {
+		t.Fatalf("SelfSignedCert failed: %v", err)
+	}
+	reissued, _ := Fingerprint(certFile)
+	if reissued == first {
+		t.Errorf("certificate was not reissued for a new address")
+	}
+	if newKey, _ := os.ReadFile(keyFile); string(newKey) != string(key) {
+		t.Errorf("key changed when the certificate was reissued")
+	}
+	cert, err := readCert(certFile)
+	if err != nil {
+		t.Fatalf("readCert failed: %v", err)
+	}
+	if missing := uncovered(cert, []string{"10.200.0.1", "127.0.0.1", "localhost"}); len(missing) > 0 {
+		t.Errorf("reissued certificate does not cover %v", missing)
+	}
+
+	// Serving on the old address again does not change it back
+	if _, _, err := SelfSignedCert("127.0.0.1"); err != nil {
+		t.Fatalf("SelfSignedCert failed: %v", err)
+	}
+	if again, _ := Fingerprint(certFile); again != reissued {
+		t.Errorf("certificate changed for an address it already covers")
+	}
+}
@@ -8,6 +8,7 @@ func getWebUI() string {
     <meta name="viewport" content="width=device-width, initial-scale=1.0">
     <title>🍅 Pom - Pomodoro Timer</title>
     <style>
+        :root { --accent: #18FFFF; --secondary: #FF4081; }
         * { margin: 0; padding: 0; box-sizing: border-box; }
         body { 
             font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, sans-serif;
@@ -16,30 +17,44 @@ func getWebUI() string {
         }
         .container { max-width: 800px; margin: 0 auto; }
         .header { text-align: center; margin-bottom: 40px; }
-        .header h1 { font-size: 3rem; margin-bottom: 10px; color: #18FFFF; text-shadow: 0 0 20px #18FFFF; animation: glow 2s ease-in-out infinite alternate; }
-        @keyframes glow { from { text-shadow: 0 0 20px #18FFFF; } to { text-shadow: 0 0 30px #18FFFF, 0 0 40px #18FFFF; } }
+        .header h1 { font-size: 3rem; margin-bottom: 10px; color: var(--accent); text-shadow: 0 0 20px var(--accent); animation: glow 2s ease-in-out infinite alternate; }
+        @keyframes glow { from { text-shadow: 0 0 20px var(--accent); } to { text-shadow: 0 0 30px var(--accent), 0 0 40px var(--accent); } }
         .tabs { display: flex; margin-bottom: 30px; border-radius: 10px; overflow: hidden; }
         .tab { flex: 1; padding: 15px; background: #1a1a2e; border: none; color: #fff; cursor: pointer; transition: all 0.3s; }
-        .tab.active { background: #18FFFF; color: #0B0F1A; }
-        .tab:hover { background: #FF4081; }
+        .tab.active { background: var(--accent); color: #0B0F1A; }
+        .tab:hover { background: var(--secondary); }
         .content { background: rgba(255,255,255,0.1); border-radius: 15px; padding: 30px; backdrop-filter: blur(10px); }
         .timer-display { text-align: center; margin: 30px 0; }
-        .time { font-size: 4rem; font-weight: bold; color: #18FFFF; margin-bottom: 20px; }
+        .time { font-size: 4rem; font-weight: bold; color: var(--accent); margin-bottom: 20px; }
         .progress { width: 100%; height: 20px; background: rgba(255,255,255,0.2); border-radius: 10px; overflow: hidden; margin-bottom: 20px; }
-        .progress-bar { height: 100%; background: linear-gradient(90deg, #18FFFF, #FF4081); transition: width 0.3s; }
+        .progress-bar { height: 100%; background: linear-gradient(90deg, var(--accent), var(--secondary)); transition: width 0.3s; }
         .controls { display: flex; gap: 15px; justify-content: center; margin: 30px 0; }
         .btn { padding: 12px 24px; border: none; border-radius: 8px; font-size: 1rem; cursor: pointer; transition: all 0.3s; }
-        .btn-primary { background: #18FFFF; color: #0B0F1A; }
-        .btn-secondary { background: #FF4081; color: #fff; }
+        .btn-primary { background: var(--accent); color: #0B0F1A; }
+        .btn-secondary { background: var(--secondary); color: #fff; }
         .btn:hover { transform: translateY(-2px); box-shadow: 0 5px 15px rgba(0,0,0,0.3); }
         .settings { display: grid; grid-template-columns: repeat(auto-fit, minmax(200px, 1fr)); gap: 20px; margin-bottom: 30px; }
         .setting { display: flex; flex-direction: column; }
-        .setting label { margin-bottom: 8px; color: #18FFFF; }
+        .setting label { margin-bottom: 8px; color: var(--accent); }
         .setting input, .setting select { padding: 10px; border: none; border-radius: 5px; background: rgba(255,255,255,0.1); color: #fff; }
         .stats { display: grid; grid-template-columns: repeat(auto-fit, minmax(150px, 1fr)); gap: 20px; }
         .stat { text-align: center; padding: 20px; background: rgba(255,255,255,0.1); border-radius: 10px; }
-        .stat-value { font-size: 2rem; font-weight: bold; color: #18FFFF; }
+        .stat-value { font-size: 2rem; font-weight: bold; color: var(--accent); }
         .stat-label { color: #ccc; margin-top: 5px; }
+        .goal { margin-top: 25px; }
+        .goal-label { display: flex; justify-content: space-between; color: #ccc; margin-bottom: 8px; }
+        .profile-table { width: 100%; border-collapse: collapse; margin-bottom: 30px; }
+        .profile-table th, .profile-table td { padding: 10px; text-align: left; border-bottom: 1px solid rgba(255,255,255,0.1); }
+        .profile-table th { color: var(--accent); font-weight: normal; }
+        .profile-table .current { color: var(--accent); }
+        .btn-small { padding: 6px 12px; font-size: 0.85rem; margin-right: 5px; }
+        .content h3 { color: var(--accent); margin-bottom: 15px; }
+        .message { min-height: 1.5em; margin-bottom: 20px; }
+        .sequence { display: flex; flex-wrap: wrap; gap: 6px; justify-content: center; margin-top: 15px; }
+        .sequence span { padding: 4px 10px; border-radius: 12px; font-size: 0.8rem; background: rgba(255,255,255,0.1); color: #ccc; }
+        .sequence .break { border: 1px dashed rgba(255,255,255,0.3); }
+        .sequence .done { opacity: 0.4; }
+        .sequence .current { background: var(--accent); color: #0B0F1A; }
         .hidden { display: none; }
     </style>
 </head>
@@ -48,11 +63,13 @@ func getWebUI() string {
         <div class="header">
             <h1>🍅 Galactic Pomodoro</h1>
             <p>Focus • Break • Achieve</p>
+            <p><a href="/logout" style="color: var(--secondary); font-size: 0.8rem;">Sign out</a></p>
         </div>
 
         <div class="tabs">
             <button class="tab active" onclick="showTab('timer')">Timer</button>
             <button class="tab" onclick="showTab('dashboard')">Dashboard</button>
+            <button class="tab" onclick="showTab('profiles')">Profiles</button>
             <button class="tab" onclick="showTab('controls')">CLI Controls</button>
         </div>
 
@@ -60,12 +77,7 @@ func getWebUI() string {
             <div class="settings">
                 <div class="setting">
                     <label>Profile</label>
-                    <select id="profile">
-                        <option value="default">Default (25/5)</option>
-                        <option value="work">Work (45/10)</option>
-                        <option value="study">Study (30/5)</option>
-                        <option value="quick">Quick (15/3)</option>
-                    </select>
+                    <select id="profile"></select>
                 </div>
                 <div class="setting">
                     <label>Work Time (min)</label>
@@ -87,12 +99,15 @@ func getWebUI() string {
                     <div class="progress-bar" id="progressBar" style="width: 0%"></div>
                 </div>
                 <p id="sessionInfo">Ready to start • Session 1/4</p>
+                <div class="sequence" id="sequence"></div>
             </div>
 
             <div class="controls">
                 <button class="btn btn-primary" id="startBtn" onclick="startTimer()">Start Focus</button>
+                <button class="btn btn-secondary" id="flowBtn" onclick="startFlow()" title="Count up until you take a break">Start Flow</button>
                 <button class="btn btn-secondary hidden" id="pauseBtn" onclick="pauseTimer()">Pause</button>
                 <button class="btn btn-secondary hidden" id="resumeBtn" onclick="resumeTimer()">Resume</button>
+                <button class="btn btn-secondary hidden" id="skipBtn" onclick="skipTimer()">Skip</button>
                 <button class="btn btn-secondary" id="stopBtn" onclick="stopTimer()">Stop</button>
             </div>
         </div>
@@ -115,6 +130,105 @@ func getWebUI() string {
                     <div class="stat-value" id="totalSessions">0</div>
                     <div class="stat-label">Total Sessions</div>
                 </div>
+                <div class="stat">
+                    <div class="stat-value" id="focusScore">-</div>
+                    <div class="stat-label">Focus Score (28 days)</div>
+                </div>
+            </div>
+            <div id="goals"></div>
+            <table class="profile-table hidden" id="intervalStats" style="margin-top: 25px;">
+                <thead>
+                    <tr><th>Interval</th><th>Completed</th><th>Minutes</th></tr>
+                </thead>
+                <tbody id="intervalRows"></tbody>
+            </table>
+            <p class="message" id="trend"></p>
+            <table class="profile-table hidden" id="weekdayStats">
+                <thead>
+                    <tr><th>Weekday</th><th>Completed</th><th>Minutes</th></tr>
+                </thead>
+                <tbody id="weekdayRows"></tbody>
+            </table>
+            <table class="profile-table hidden" id="achievements" style="margin-top: 25px;">
+                <thead>
+                    <tr><th>Achievement</th><th>Progress</th><th>Unlocked</th></tr>
+                </thead>
+                <tbody id="achievementRows"></tbody>
+            </table>
+        </div>
+
+        <div id="profiles-tab" class="content hidden">
+            <table class="profile-table">
+                <thead>
+                    <tr><th>Name</th><th>Sequence</th><th>Description</th><th></th></tr>
+                </thead>
+                <tbody id="profileRows"></tbody>
+            </table>
+
+            <h3 id="profileFormTitle">New Profile</h3>
+            <div class="settings">
+                <div class="setting">
+                    <label>Name</label>
+                    <input type="text" id="profileName">
+                </div>
+                <div class="setting">
+                    <label>Work Time (min)</label>
+                    <input type="number" id="profileWork" value="25" min="1">
+                </div>
+                <div class="setting">
+                    <label>Break Time (min)</label>
+                    <input type="number" id="profileBreak" value="5" min="1">
+                </div>
+                <div class="setting">
+                    <label>Sessions</label>
+                    <input type="number" id="profileSessions" value="4" min="1">
+                </div>
+                <div class="setting">
+                    <label>Long Break (min, 0 for none)</label>
+                    <input type="number" id="profileLongBreak" value="0" min="0">
+                </div>
+                <div class="setting">
+                    <label>Long Break Every (sessions)</label>
+                    <input type="number" id="profileLongEvery" value="4" min="1">
+                </div>
+                <div class="setting">
+                    <label>Description</label>
+                    <input type="text" id="profileDescription">
+                </div>
+            </div>
+            <div class="settings">
+                <div class="setting">
+                    <label><input type="checkbox" id="profileAdaptive"> Adaptive lengths</label>
+                </div>
+                <div class="setting">
+                    <label>Work Bounds (min)</label>
+                    <input type="number" id="profileMinWork" placeholder="15" min="1">
+                    <input type="number" id="profileMaxWork" placeholder="60" min="1">
+                </div>
+                <div class="setting">
+                    <label>Break Bounds (min)</label>
+                    <input type="number" id="profileMinBreak" placeholder="3" min="1">
+                    <input type="number" id="profileMaxBreak" placeholder="20" min="1">
+                </div>
+            </div>
+            <div class="settings">
+                <div class="setting">
+                    <label>Interval Sequence (optional, replaces the settings above)</label>
+                    <input type="text" id="profileIntervals" placeholder="Deep work:focus:90, Walk:break:20, Deep work:focus:90">
+                </div>
+            </div>
+            <div class="controls">
+                <button class="btn btn-primary" onclick="saveProfile()">Save Profile</button>
+                <button class="btn btn-secondary" onclick="resetProfileForm()">Cancel</button>
+            </div>
+            <p class="message" id="profileMessage"></p>
+
+            <h3>🎨 Theme</h3>
+            <div class="settings">
+                <div class="setting">
+                    <label>Theme (CLI and web)</label>
+                    <select id="theme"></select>
+                </div>
             </div>
         </div>
 
@@ -126,7 +240,7 @@ func getWebUI() string {
                 </div>
                 <div class="setting">
                     <label>👥 Profile Management</label>
-                    <button class="btn btn-primary" onclick="executeCommand('profile')">Manage Profiles</button>
+                    <button class="btn btn-primary" onclick="executeCommand('profile')">View Profiles</button>
                 </div>
                 <div class="setting">
                     <label>📊 Statistics</label>
@@ -136,6 +250,15 @@ func getWebUI() string {
                     <label>🧠 AI Insights</label>
                     <button class="btn btn-primary" onclick="executeCommand('insights')">Get Insights</button>
                 </div>
+                <div class="setting">
+                    <label>🧑‍🏫 Coaching</label>
+                    <button class="btn btn-primary" onclick="executeCommand('coach')">Weekly Coaching</button>
+                </div>
+                <div class="setting">
+                    <label>📅 Reviews</label>
+                    <button class="btn btn-primary" onclick="window.open('/api/report/week?format=html')">This Week</button>
+                    <button class="btn btn-primary" onclick="window.open('/api/report/month?format=html')">This Month</button>
+                </div>
                 <div class="setting">
                     <label>📤 Export Data</label>
                     <button class="btn btn-secondary" onclick="executeCommand('export')">Export JSON</button>
@@ -153,18 +276,19 @@ func getWebUI() string {
                     <button class="btn btn-secondary" onclick="executeCommand('privacy')">Privacy Settings</button>
                 </div>
             </div>
-            <div id="commandOutput" style="margin-top: 20px; padding: 15px; background: rgba(0,0,0,0.3); border-radius: 8px; font-family: monospace; color: #18FFFF; max-height: 300px; overflow-y: auto;"></div>
+            <div id="commandOutput" style="margin-top: 20px; padding: 15px; background: rgba(0,0,0,0.3); border-radius: 8px; font-family: monospace; color: var(--accent); max-height: 300px; overflow-y: auto;"></div>
         </div>
     </div>
 
     <script>
-        let timer = null;
-        let timeLeft = 25 * 60;
-        let totalTime = 25 * 60;
-        let isRunning = false;
-        let currentSession = 1;
-        let totalSessions = 4;
-        let isBreak = false;
+        // The timer runs on the server; this page only displays the state it
+        // broadcasts over the WebSocket and sends commands back.
+        let socket = null;
+        let session = null;
+        let serverStopped = false;
+        let profiles = [];
+        let currentProfile = '';
+        let editingProfile = null;
 
         function showTab(tab) {
             document.querySelectorAll('.tab').forEach(t => t.classList.remove('active'));
@@ -174,155 +298,696 @@ func getWebUI() string {
             document.getElementById(tab + '-tab').classList.remove('hidden');
             
             if (tab === 'dashboard') loadStats();
+            if (tab === 'profiles') loadProfiles();
+        }
+
+        function connect() {
+            const scheme = location.protocol === 'https:' ? 'wss://' : 'ws://';
+            socket = new WebSocket(scheme + location.host + '/ws');
+            socket.onmessage = e => handleMessage(JSON.parse(e.data));
+            socket.onclose = () => {
+                if (!serverStopped) {
+                    document.getElementById('sessionInfo').textContent = 'Disconnected • Reconnecting...';
+                }
+                api('/api/session');
+                setTimeout(connect, 2000);
+            };
+        }
+
+        // api calls the REST API, sending the CSRF token the server expects on
+        // changes and returning to the login page when the session has ended
+        function api(url, options = {}) {
+            const csrf = document.cookie.split('; ').find(c => c.startsWith('pom_csrf='));
+            options.headers = Object.assign({}, options.headers, {
+                'X-CSRF-Token': csrf ? csrf.substring('pom_csrf='.length) : ''
+            });
+            return fetch(url, options).then(r => {
+                if (r.status === 401) location.href = '/login';
+                return r;
+            });
+        }
+
+        function send(command) {
+            if (socket && socket.readyState === WebSocket.OPEN) {
+                socket.send(JSON.stringify(command));
+            }
+        }
+
+        function handleMessage(msg) {
+            if (msg.type === 'error') {
+                document.getElementById('sessionInfo').textContent = '⚠️ ' + msg.message;
+                return;
+            }
+            if (msg.type === 'shutdown') {
+                serverStopped = true;
+                session = msg.session;
+                updateDisplay();
+                document.getElementById('sessionInfo').textContent = '🛑 ' + msg.message + ' • Reconnecting...';
+                return;
+            }
+            serverStopped = false;
+
+            session = msg.session;
+            updateDisplay();
+
+            if (msg.type === 'phase') {
+                notifyPhase(session.is_break
+                    ? '🎉 Work session complete! Time for: ' + intervalName(currentInterval()) + '.'
+                    : '☕ Break over! Time to focus.');
+            } else if (msg.type === 'finished') {
+                notifyPhase('🏆 All sessions complete! Great work!');
+                loadStats();
+            }
+        }
+
+        function notifyPhase(text) {
+            document.getElementById('sessionInfo').textContent = text;
+            if (window.Notification && Notification.permission === 'granted') {
+                new Notification('🍅 Pom', { body: text });
+            }
         }
 
         function updateDisplay() {
+            const active = session && session.is_running;
+            const open = active && session.open;
+            // Open intervals count up, so show the time spent instead
+            const timeLeft = open ? session.elapsed : (active ? session.time_left : parseInt(document.getElementById('workTime').value) * 60);
+            const totalTime = open ? 0 : (active ? session.duration : timeLeft);
+
             const minutes = Math.floor(timeLeft / 60);
             const seconds = timeLeft % 60;
             document.getElementById('timeDisplay').textContent = 
                 ` + "`" + `${minutes.toString().padStart(2, '0')}:${seconds.toString().padStart(2, '0')}` + "`" + `;
             
-            const progress = ((totalTime - timeLeft) / totalTime) * 100;
+            const progress = totalTime > 0 ? ((totalTime - timeLeft) / totalTime) * 100 : 0;
             document.getElementById('progressBar').style.width = progress + '%';
             
-            const phase = isBreak ? 'Break' : 'Focus';
-            document.getElementById('sessionInfo').textContent = 
-                ` + "`" + `${phase} Time • Session ${currentSession}/${totalSessions}` + "`" + `;
+            if (active) {
+                const phase = session.interval || (session.is_break ? 'Break' : 'Focus');
+                const state = (session.is_paused ? ' (paused)' : '') + (open ? ' • counting up' : '');
+                document.getElementById('sessionInfo').textContent = 
+                    ` + "`" + `${phase}${state} • Session ${session.current_session}/${session.sessions}` + "`" + `;
+                renderSequence(session.intervals || [], session.step);
+            } else {
+                const plan = selectedPlan();
+                document.getElementById('sessionInfo').textContent = 
+                    ` + "`" + `Ready to start • ${plan.filter(i => i.kind === 'focus').length} sessions` + "`" + `;
+                renderSequence(plan, -1);
+            }
+
+            document.getElementById('startBtn').classList.toggle('hidden', !!active);
+            document.getElementById('flowBtn').classList.toggle('hidden', !!active);
+            document.getElementById('skipBtn').textContent = open ? 'Take Break' : 'Skip';
+            document.getElementById('pauseBtn').classList.toggle('hidden', !active || session.is_paused);
+            document.getElementById('resumeBtn').classList.toggle('hidden', !active || !session.is_paused);
+            document.getElementById('skipBtn').classList.toggle('hidden', !active);
         }
 
         function startTimer() {
-            const workTime = parseInt(document.getElementById('workTime').value);
-            const breakTime = parseInt(document.getElementById('breakTime').value);
-            totalSessions = parseInt(document.getElementById('sessions').value);
-            
-            timeLeft = isBreak ? breakTime * 60 : workTime * 60;
-            totalTime = timeLeft;
-            isRunning = true;
-            
-            document.getElementById('startBtn').classList.add('hidden');
-            document.getElementById('pauseBtn').classList.remove('hidden');
-            
-            timer = setInterval(() => {
-                timeLeft--;
-                updateDisplay();
-                
-                if (timeLeft <= 0) {
-                    clearInterval(timer);
-                    completeSession();
-                }
-            }, 1000);
+            if (window.Notification && Notification.permission === 'default') {
+                Notification.requestPermission();
+            }
+            const command = { type: 'start', profile: document.getElementById('profile').value };
+            // Profiles with their own sequence run it as is, and adaptive
+            // profiles choose their own lengths
+            if (!ownsPlan(findProfile(command.profile))) {
+                command.work_time = parseInt(document.getElementById('workTime').value);
+                command.break_time = parseInt(document.getElementById('breakTime').value);
+                command.sessions = parseInt(document.getElementById('sessions').value);
+            }
+            send(command);
         }
 
-        function pauseTimer() {
-            clearInterval(timer);
-            isRunning = false;
-            document.getElementById('pauseBtn').classList.add('hidden');
-            document.getElementById('resumeBtn').classList.remove('hidden');
+        function hasSequence(profile) {
+            return !!(profile && profile.intervals && profile.intervals.length);
         }
 
-        function resumeTimer() {
-            isRunning = true;
-            document.getElementById('resumeBtn').classList.add('hidden');
-            document.getElementById('pauseBtn').classList.remove('hidden');
-            
-            timer = setInterval(() => {
-                timeLeft--;
-                updateDisplay();
-                
-                if (timeLeft <= 0) {
-                    clearInterval(timer);
-                    completeSession();
+        // ownsPlan reports whether a profile decides its own lengths
+        function ownsPlan(profile) {
+            return hasSequence(profile) || !!(profile && profile.adaptive);
+        }
+
+        function intervalName(interval) {
+            if (!interval) return '';
+            return interval.name || (interval.kind === 'focus' ? 'Focus' : 'Break');
+        }
+
+        function currentInterval() {
+            return session && session.intervals ? session.intervals[session.step] : null;
+        }
+
+        // classicPlan mirrors timer.ClassicPlan: work and break intervals with
+        // every n-th break replaced by a long break, ending with the last focus
+        function classicPlan(work, brk, sessions, longBreak, every) {
+            const plan = [];
+            for (let i = 1; i <= sessions; i++) {
+                plan.push({ name: 'Focus', kind: 'focus', minutes: work });
+                if (longBreak > 0 && every > 0 && i % every === 0 && i < sessions) {
+                    plan.push({ name: 'Long break', kind: 'break', minutes: longBreak });
+                } else if (i < sessions && brk > 0) {
+                    plan.push({ name: 'Break', kind: 'break', minutes: brk });
                 }
-            }, 1000);
+            }
+            return plan;
         }
 
-        function stopTimer() {
-            clearInterval(timer);
-            isRunning = false;
-            isBreak = false;
-            currentSession = 1;
-            
-            const workTime = parseInt(document.getElementById('workTime').value);
-            timeLeft = workTime * 60;
-            totalTime = timeLeft;
-            
-            document.getElementById('startBtn').classList.remove('hidden');
-            document.getElementById('pauseBtn').classList.add('hidden');
-            document.getElementById('resumeBtn').classList.add('hidden');
-            
-            updateDisplay();
+        function profilePlan(profile) {
+            if (hasSequence(profile)) return profile.intervals;
+            return classicPlan(profile.work_minutes, profile.break_minutes, profile.num_sessions,
+                profile.long_break_minutes || 0, profile.long_break_every || 0);
+        }
+
+        // selectedPlan is the sequence the Start button would run
+        function selectedPlan() {
+            const profile = findProfile(document.getElementById('profile').value);
+            if (ownsPlan(profile)) return profilePlan(profile);
+            return classicPlan(
+                parseInt(document.getElementById('workTime').value) || 0,
+                parseInt(document.getElementById('breakTime').value) || 0,
+                parseInt(document.getElementById('sessions').value) || 0,
+                profile ? profile.long_break_minutes || 0 : 0,
+                profile ? profile.long_break_every || 0 : 0);
+        }
+
+        // intervalLength mirrors timer.Interval.Length
+        function intervalLength(interval) {
+            if (interval.open && !interval.minutes) return 'open';
+            if (interval.ratio && !interval.minutes) return 'focus/' + interval.ratio;
+            return interval.minutes + 'm';
+        }
+
+        function summarizePlan(plan) {
+            return plan.map(i => intervalName(i) + ' ' + intervalLength(i)).join(' → ');
         }
 
-        function completeSession() {
-            if (!isBreak) {
-                if (currentSession < totalSessions) {
-                    isBreak = true;
-                    alert('🎉 Work session complete! Time for a break.');
-                    startTimer();
-                } else {
-                    alert('🏆 All sessions complete! Great work!');
-                    stopTimer();
+        function renderSequence(plan, step) {
+            const container = document.getElementById('sequence');
+            container.innerHTML = '';
+            plan.forEach((interval, i) => {
+                const chip = document.createElement('span');
+                chip.textContent = intervalName(interval) + ' ' + intervalLength(interval);
+                chip.className = interval.kind === 'focus' ? 'focus' : 'break';
+                if (i < step) chip.className += ' done';
+                if (i === step) chip.className += ' current';
+                container.appendChild(chip);
+            });
+        }
+
+        function parseIntervals(text) {
+            return text.split(',').map(s => s.trim()).filter(s => s).map(item => {
+                const parts = item.split(':').map(s => s.trim());
+                const length = parts[parts.length - 1];
+                const interval = { kind: 'focus', minutes: parseInt(length) };
+                if (length === 'open') {
+                    interval.open = true;
+                    interval.minutes = 0;
+                } else if (length.startsWith('focus/')) {
+                    interval.ratio = parseFloat(length.slice(6));
+                    interval.minutes = 0;
                 }
-            } else {
-                isBreak = false;
-                currentSession++;
-                alert('☕ Break over! Ready for the next session?');
-                document.getElementById('startBtn').classList.remove('hidden');
-                document.getElementById('pauseBtn').classList.add('hidden');
-                isRunning = false;
+                if (parts.length === 2) interval.kind = parts[0].toLowerCase();
+                if (parts.length === 3) {
+                    interval.name = parts[0];
+                    interval.kind = parts[1].toLowerCase();
+                }
+                if (parts.length > 3 || isNaN(interval.minutes) || (interval.ratio !== undefined && !(interval.ratio > 0))) {
+                    throw new Error('Invalid interval "' + item + '", expected name:kind:minutes');
+                }
+                return interval;
+            });
+        }
+
+        function formatIntervals(plan) {
+            return (plan || []).map(i => intervalName(i) + ':' + i.kind + ':' + intervalLength(i).replace(/m$/, '')).join(', ');
+        }
+
+        function startFlow() {
+            if (window.Notification && Notification.permission === 'default') {
+                Notification.requestPermission();
             }
+            send({ type: 'flow' });
+        }
+
+        function pauseTimer() { send({ type: 'pause' }); }
+        function resumeTimer() { send({ type: 'resume' }); }
+        function skipTimer() { send({ type: 'skip' }); }
+        function stopTimer() { send({ type: 'stop' }); }
+
+        // readJSON returns the body of an API response, throwing its error message
+        function readJSON(r) {
+            if (r.status === 204) return Promise.resolve(null);
+            return r.json().then(data => {
+                if (!r.ok) throw new Error(data.error || r.statusText);
+                return data;
+            });
         }
 
         function loadStats() {
-            fetch('/api/insights/today')
-                .then(r => r.json())
+            api('/api/stats')
+                .then(readJSON)
+                .then(stats => {
+                    document.getElementById('todaySessions').textContent = stats.today_sessions;
+                    document.getElementById('todayMinutes').textContent = stats.today_minutes;
+                    document.getElementById('currentStreak').textContent = stats.current_streak;
+                    document.getElementById('totalSessions').textContent = stats.total_sessions;
+                    renderGoals(stats);
+                    renderIntervalStats(stats.intervals || []);
+                })
+                .catch(() => {});
+            api('/api/insights')
+                .then(readJSON)
+                .then(renderInsights)
+                .catch(() => {});
+            api('/api/achievements')
+                .then(readJSON)
+                .then(renderAchievements)
+                .catch(() => {});
+        }
+
+        // renderAchievements lists unlocked badges first, then progress towards the rest
+        function renderAchievements(achievements) {
+            const rows = document.getElementById('achievementRows');
+            rows.innerHTML = '';
+            achievements.forEach(a => {
+                const row = document.createElement('tr');
+                const name = (a.unlocked ? (a.icon || '🏆') : '🔒') + ' ' + a.name;
+                const progress = a.unlocked ? '✓' : Math.round(a.value * 10) / 10 + ' / ' + a.threshold;
+                const date = a.unlocked ? new Date(a.unlocked_at).toLocaleDateString() : '';
+                [name, progress, date].forEach(value => {
+                    const cell = document.createElement('td');
+                    cell.textContent = value;
+                    row.appendChild(cell);
+                });
+                row.title = a.description || '';
+                rows.appendChild(row);
+            });
+            document.getElementById('achievements').classList.toggle('hidden', achievements.length === 0);
+        }
+
+        // renderInsights shows the focus score, trend and weekday curve
+        function renderInsights(insights) {
+            const active = insights.focus.sessions > 0;
+            document.getElementById('focusScore').textContent = active ? Math.round(insights.score.score) : '-';
+            document.getElementById('trend').textContent = active ? '📈 ' + insights.trend.summary : '';
+            const rows = document.getElementById('weekdayRows');
+            rows.innerHTML = '';
+            insights.weekdays.forEach(day => {
+                const row = document.createElement('tr');
+                const rate = day.sessions ? Math.round(day.completion_rate * 100) + '% of ' + day.sessions : '-';
+                [day.label, rate, Math.round(day.minutes)].forEach(value => {
+                    const cell = document.createElement('td');
+                    cell.textContent = value;
+                    row.appendChild(cell);
+                });
+                rows.appendChild(row);
+            });
+            document.getElementById('weekdayStats').classList.toggle('hidden', !active);
+        }
+
+        function renderGoals(stats) {
+            const goals = document.getElementById('goals');
+            goals.innerHTML = '';
+            addGoal(goals, 'Sessions today', stats.today_sessions, stats.daily_session_target);
+            addGoal(goals, 'Minutes today', stats.today_minutes, stats.daily_minutes_target);
+            addGoal(goals, 'Sessions this week', stats.week.sessions, stats.week.target_sessions);
+            addGoal(goals, 'Minutes this week', stats.week.minutes, stats.week.target_minutes);
+            addGoal(goals, 'Sessions this month', stats.month.sessions, stats.month.target_sessions);
+            addGoal(goals, 'Minutes this month', stats.month.minutes, stats.month.target_minutes);
+            (stats.goals || []).forEach(named => {
+                const label = named.goal.name + ' (' + namedGoalScope(named.goal) + ', per ' + named.goal.period + ')';
+                if (named.goal.sessions) addGoal(goals, label + ' sessions', named.sessions, named.goal.sessions);
+                if (named.goal.minutes) addGoal(goals, label + ' minutes', named.minutes, named.goal.minutes);
+            });
+            const note = [];
+            if (stats.rest_today) note.push('💤 Rest day');
+            if (stats.freezes) note.push('🧊 ' + stats.freezes + ' streak freeze' + (stats.freezes === 1 ? '' : 's'));
+            if (note.length) {
+                const text = document.createElement('div');
+                text.className = 'goal-label';
+                text.textContent = note.join(' · ');
+                goals.appendChild(text);
+            }
+        }
+
+        function renderIntervalStats(intervals) {
+            const rows = document.getElementById('intervalRows');
+            rows.innerHTML = '';
+            intervals.forEach(i => {
+                const row = document.createElement('tr');
+                [(i.kind === 'focus' ? '📚 ' : '☕ ') + i.name, i.completed + ' / ' + i.count, Math.round(i.minutes)].forEach(value => {
+                    const cell = document.createElement('td');
+                    cell.textContent = value;
+                    row.appendChild(cell);
+                });
+                rows.appendChild(row);
+            });
+            document.getElementById('intervalStats').classList.toggle('hidden', intervals.length === 0);
+        }
+
+        // namedGoalScope describes the sessions a named goal counts
+        function namedGoalScope(goal) {
+            const scope = [];
+            if (goal.tag) scope.push('tag:' + goal.tag);
+            if (goal.task_id) scope.push('task:' + goal.task_id);
+            if (goal.profile) scope.push('profile:' + goal.profile);
+            return scope.length ? scope.join(' ') : 'all sessions';
+        }
+
+        function addGoal(container, label, value, target) {
+            if (!target) return;
+            const goal = document.createElement('div');
+            goal.className = 'goal';
+            const text = document.createElement('div');
+            text.className = 'goal-label';
+            text.innerHTML = '<span></span><span></span>';
+            text.children[0].textContent = '🎯 ' + label;
+            text.children[1].textContent = value + ' / ' + target;
+            const bar = document.createElement('div');
+            bar.className = 'progress';
+            bar.innerHTML = '<div class="progress-bar"></div>';
+            bar.firstChild.style.width = Math.min(100, value / target * 100) + '%';
+            goal.appendChild(text);
+            goal.appendChild(bar);
+            container.appendChild(goal);
+        }
+
+        function loadProfiles() {
+            return api('/api/profiles')
+                .then(readJSON)
                 .then(data => {
-                    document.getElementById('todaySessions').textContent = data.sessions || 0;
-                    document.getElementById('todayMinutes').textContent = data.minutes || 0;
+                    profiles = data.profiles || [];
+                    currentProfile = data.current;
+                    renderProfileSelect();
+                    renderProfileTable();
                 })
-                .catch(() => {
-                    document.getElementById('todaySessions').textContent = '0';
-                    document.getElementById('todayMinutes').textContent = '0';
+                .catch(err => showProfileMessage(err.message, true));
+        }
+
+        function findProfile(name) {
+            return profiles.find(p => p.name === name);
+        }
+
+        function renderProfileSelect() {
+            const select = document.getElementById('profile');
+            const selected = findProfile(select.value) ? select.value : (findProfile(currentProfile) ? currentProfile : '');
+            select.innerHTML = '';
+            profiles.forEach(p => {
+                const option = document.createElement('option');
+                option.value = p.name;
+                option.textContent = p.name + (hasSequence(p)
+                    ? ' (' + p.intervals.length + ' intervals)'
+                    : ' (' + p.work_minutes + '/' + p.break_minutes + (p.adaptive ? ', adaptive' : '') + ')');
+                select.appendChild(option);
+            });
+            if (selected) {
+                select.value = selected;
+                applyProfile(selected);
+            }
+        }
+
+        // applyProfile fills the timer settings from a profile
+        function applyProfile(name) {
+            const profile = findProfile(name);
+            if (!profile) return;
+            document.getElementById('workTime').value = profile.work_minutes;
+            document.getElementById('breakTime').value = profile.break_minutes;
+            document.getElementById('sessions').value = profile.num_sessions;
+            ['workTime', 'breakTime', 'sessions'].forEach(id => {
+                document.getElementById(id).disabled = ownsPlan(profile);
+            });
+            updateDisplay();
+        }
+
+        function renderProfileTable() {
+            const rows = document.getElementById('profileRows');
+            rows.innerHTML = '';
+            profiles.forEach(p => {
+                const row = document.createElement('tr');
+                const plan = summarizePlan(profilePlan(p)) + (p.adaptive ? ' (adaptive)' : '');
+                [p.name, plan, p.description].forEach(value => {
+                    const cell = document.createElement('td');
+                    cell.textContent = value;
+                    row.appendChild(cell);
                 });
+                if (p.name === currentProfile) {
+                    row.firstChild.className = 'current';
+                    row.firstChild.textContent += ' ✓';
+                }
+
+                const actions = document.createElement('td');
+                addButton(actions, 'Edit', 'btn-primary', () => editProfile(p.name));
+                if (p.adaptive) {
+                    addButton(actions, 'Explain', 'btn-primary', () => explainProfile(p.name));
+                }
+                if (p.name !== currentProfile) {
+                    addButton(actions, 'Use', 'btn-primary', () => useProfile(p.name));
+                    addButton(actions, 'Delete', 'btn-secondary', () => deleteProfile(p.name));
+                }
+                row.appendChild(actions);
+                rows.appendChild(row);
+            });
         }
 
-        document.getElementById('profile').addEventListener('change', function() {
-            const profiles = {
-                default: {work: 25, break: 5, sessions: 4},
-                work: {work: 45, break: 10, sessions: 3},
-                study: {work: 30, break: 5, sessions: 4},
-                quick: {work: 15, break: 3, sessions: 6}
+        function addButton(container, label, style, onclick) {
+            const button = document.createElement('button');
+            button.className = 'btn btn-small ' + style;
+            button.textContent = label;
+            button.onclick = onclick;
+            container.appendChild(button);
+        }
+
+        function showProfileMessage(text, isError) {
+            const message = document.getElementById('profileMessage');
+            message.textContent = text;
+            message.style.color = isError ? 'var(--secondary)' : 'var(--accent)';
+        }
+
+        function editProfile(name) {
+            const profile = findProfile(name);
+            if (!profile) return;
+            editingProfile = name;
+            document.getElementById('profileFormTitle').textContent = 'Edit Profile';
+            document.getElementById('profileName').value = profile.name;
+            document.getElementById('profileWork').value = profile.work_minutes;
+            document.getElementById('profileBreak').value = profile.break_minutes;
+            document.getElementById('profileSessions').value = profile.num_sessions;
+            document.getElementById('profileLongBreak').value = profile.long_break_minutes || 0;
+            document.getElementById('profileLongEvery').value = profile.long_break_every || 4;
+            document.getElementById('profileIntervals').value = formatIntervals(profile.intervals);
+            const adaptive = profile.adaptive || {};
+            document.getElementById('profileAdaptive').checked = !!profile.adaptive;
+            document.getElementById('profileMinWork').value = adaptive.min_work || '';
+            document.getElementById('profileMaxWork').value = adaptive.max_work || '';
+            document.getElementById('profileMinBreak').value = adaptive.min_break || '';
+            document.getElementById('profileMaxBreak').value = adaptive.max_break || '';
+            document.getElementById('profileDescription').value = profile.description;
+            showProfileMessage('', false);
+        }
+
+        function resetProfileForm() {
+            editingProfile = null;
+            document.getElementById('profileFormTitle').textContent = 'New Profile';
+            document.getElementById('profileName').value = '';
+            document.getElementById('profileWork').value = 25;
+            document.getElementById('profileBreak').value = 5;
+            document.getElementById('profileSessions').value = 4;
+            document.getElementById('profileLongBreak').value = 0;
+            document.getElementById('profileLongEvery').value = 4;
+            document.getElementById('profileIntervals').value = '';
+            document.getElementById('profileAdaptive').checked = false;
+            ['profileMinWork', 'profileMaxWork', 'profileMinBreak', 'profileMaxBreak'].forEach(id => {
+                document.getElementById(id).value = '';
+            });
+            document.getElementById('profileDescription').value = '';
+        }
+
+        function saveProfile() {
+            const profile = {
+                name: document.getElementById('profileName').value.trim(),
+                work_minutes: parseInt(document.getElementById('profileWork').value),
+                break_minutes: parseInt(document.getElementById('profileBreak').value),
+                num_sessions: parseInt(document.getElementById('profileSessions').value),
+                description: document.getElementById('profileDescription').value
             };
-            
-            const profile = profiles[this.value];
-            document.getElementById('workTime').value = profile.work;
-            document.getElementById('breakTime').value = profile.break;
-            document.getElementById('sessions').value = profile.sessions;
-            
-            if (!isRunning) {
-                timeLeft = profile.work * 60;
-                totalTime = timeLeft;
-                totalSessions = profile.sessions;
-                updateDisplay();
+            const longBreak = parseInt(document.getElementById('profileLongBreak').value) || 0;
+            if (longBreak > 0) {
+                profile.long_break_minutes = longBreak;
+                profile.long_break_every = parseInt(document.getElementById('profileLongEvery').value);
+            }
+            if (document.getElementById('profileAdaptive').checked) {
+                // Keep settings the form does not show, such as the window
+                const previous = editingProfile && findProfile(editingProfile);
+                profile.adaptive = Object.assign({}, previous && previous.adaptive, {
+                    min_work: parseInt(document.getElementById('profileMinWork').value) || 0,
+                    max_work: parseInt(document.getElementById('profileMaxWork').value) || 0,
+                    min_break: parseInt(document.getElementById('profileMinBreak').value) || 0,
+                    max_break: parseInt(document.getElementById('profileMaxBreak').value) || 0
+                });
+            }
+            try {
+                const intervals = parseIntervals(document.getElementById('profileIntervals').value);
+                if (intervals.length) profile.intervals = intervals;
+            } catch (err) {
+                showProfileMessage('⚠️ ' + err.message, true);
+                return;
             }
+            const editing = editingProfile;
+            const request = editing
+                ? api('/api/profiles/' + encodeURIComponent(editing), { method: 'PUT', body: JSON.stringify(profile) })
+                : api('/api/profiles', { method: 'POST', body: JSON.stringify(profile) });
+
+            request.then(readJSON)
+                .then(() => {
+                    resetProfileForm();
+                    showProfileMessage('✅ Profile "' + profile.name + '" saved', false);
+                    return loadProfiles();
+                })
+                .catch(err => showProfileMessage('⚠️ ' + err.message, true));
+        }
+
+        function explainProfile(name) {
+            api('/api/profiles/' + encodeURIComponent(name) + '/adaptive')
+                .then(readJSON)
+                .then(explanation => {
+                    const next = explanation.next;
+                    showProfileMessage('🧠 Next run of "' + name + '": work ' + next.work_minutes +
+                        ' min, break ' + next.break_minutes + ' min. ' + next.reasons.join('. '), false);
+                })
+                .catch(err => showProfileMessage('⚠️ ' + err.message, true));
+        }
+
+        function deleteProfile(name) {
+            if (!confirm('Delete profile "' + name + '"?')) return;
+            api('/api/profiles/' + encodeURIComponent(name), { method: 'DELETE' })
+                .then(readJSON)
+                .then(() => {
+                    if (editingProfile === name) resetProfileForm();
+                    showProfileMessage('🗑️ Profile "' + name + '" deleted', false);
+                    return loadProfiles();
+                })
+                .catch(err => showProfileMessage('⚠️ ' + err.message, true));
+        }
+
+        function useProfile(name) {
+            api('/api/profiles/' + encodeURIComponent(name) + '/use', { method: 'POST' })
+                .then(readJSON)
+                .then(() => {
+                    document.getElementById('profile').value = name;
+                    showProfileMessage('✅ Now using "' + name + '"', false);
+                    return loadProfiles();
+                })
+                .catch(err => showProfileMessage('⚠️ ' + err.message, true));
+        }
+
+        function loadThemes() {
+            api('/api/themes')
+                .then(readJSON)
+                .then(data => {
+                    const select = document.getElementById('theme');
+                    select.innerHTML = '';
+                    data.themes.forEach(t => {
+                        const option = document.createElement('option');
+                        option.value = t.name;
+                        option.textContent = t.name;
+                        select.appendChild(option);
+                    });
+                    select.value = data.current.name;
+                    applyTheme(data.current);
+                })
+                .catch(() => {});
+        }
+
+        function applyTheme(theme) {
+            document.documentElement.style.setProperty('--accent', theme.accent);
+            document.documentElement.style.setProperty('--secondary', theme.secondary);
+        }
+
+        document.getElementById('theme').addEventListener('change', function() {
+            api('/api/theme', { method: 'PUT', body: JSON.stringify({ name: this.value }) })
+                .then(readJSON)
+                .then(applyTheme)
+                .catch(err => showProfileMessage('⚠️ ' + err.message, true));
+        });
+
+        document.getElementById('profile').addEventListener('change', function() {
+            applyProfile(this.value);
+        });
+
+        ['workTime', 'breakTime', 'sessions'].forEach(id => {
+            document.getElementById(id).addEventListener('input', updateDisplay);
         });
 
         updateDisplay();
+        loadProfiles();
+        loadThemes();
+        loadStats();
+        connect();
+
+        const commandEndpoints = {
+            goals: '/api/goals',
+            profile: '/api/profiles',
+            stats: '/api/stats',
+            insights: '/api/insights/suggestions',
+            coach: '/api/insights/coach',
+            export: '/api/export?format=json',
+            sync: '/api/sync/status',
+            plugins: '/api/plugins',
+            privacy: '/api/privacy'
+        };
 
         function executeCommand(cmd) {
             const output = document.getElementById('commandOutput');
-            output.innerHTML = '<div style="color: #FFD600;">Executing: pom ' + cmd + '</div>';
-            
-            fetch('/api/command/' + cmd, { method: 'POST' })
-                .then(r => r.text())
+            const url = commandEndpoints[cmd];
+            output.innerHTML = '<div style="color: #FFD600;">GET ' + url + '</div>';
+
+            api(url)
+                .then(readJSON)
                 .then(data => {
-                    output.innerHTML += '<pre>' + data + '</pre>';
+                    const pre = document.createElement('pre');
+                    pre.textContent = JSON.stringify(data, null, 2);
+                    output.appendChild(pre);
                 })
                 .catch(err => {
-                    output.innerHTML += '<div style="color: #FF4081;">Error: ' + err.message + '</div>';
+                    output.innerHTML += '<div style="color: var(--secondary);">Error: ' + err.message + '</div>';
                 });
         }
     </script>
 </body>
 </html>`
-}
\ No newline at end of file
+}
+
+// getLoginUI returns the login page. message must already be HTML-escaped.
+func getLoginUI(message string) string {
+	return `<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>🍅 Pom - Sign in</title>
+    <style>
+        * { margin: 0; padding: 0; box-sizing: border-box; }
+        body {
+            font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, sans-serif;
+            background: linear-gradient(135deg, #0B0F1A 0%, #1a1a2e 100%);
+            color: #fff; min-height: 100vh; display: flex; align-items: center; justify-content: center;
+        }
+        form { background: rgba(255,255,255,0.1); border-radius: 15px; padding: 30px; width: 360px; }
+        h1 { color: #18FFFF; text-shadow: 0 0 20px #18FFFF; margin-bottom: 20px; text-align: center; }
+        p { color: #ccc; font-size: 0.9rem; margin-bottom: 15px; }
+        input { width: 100%; padding: 10px; border: none; border-radius: 5px; background: rgba(255,255,255,0.2); color: #fff; margin-bottom: 15px; }
+        button { width: 100%; padding: 12px; border: none; border-radius: 8px; background: #18FFFF; color: #0B0F1A; font-weight: bold; cursor: pointer; }
+        .error { color: #FF4081; margin-bottom: 15px; }
+        code { color: #FFD600; }
+    </style>
+</head>
+<body>
+    <form method="POST" action="/login">
+        <h1>🍅 Pom</h1>
+        <p>Enter the access token shown by <code>pom web token</code>.</p>
+        <div class="error">` + message + `</div>
+        <input type="password" name="token" placeholder="Access token" autofocus>
+        <button type="submit">Sign in</button>
+    </form>
+</body>
+</html>`
+}